@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"log"
+	"os"
+
+	"wallet_api/config"
+	"wallet_api/internal/module/fx/repository"
+	fxusecase "wallet_api/internal/module/fx/usecase"
+	"wallet_api/pkg/postgres"
+)
+
+// fxrates loads FX rates from a CSV file with the columns
+// base_currency,quote_currency,rate,spread,valid_from,valid_to. Every row is stored or none is.
+func main() {
+	file := flag.String("file", "", "CSV file to import, - reads stdin")
+	dryRun := flag.Bool("dry-run", false, "only parse the file, nothing is stored")
+	flag.Parse()
+
+	if *file == "" {
+		log.Fatal("--file is required")
+	}
+
+	var in io.Reader = os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			log.Fatalf("Failed to open file: %s", err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	inputs, err := fxusecase.ReadRatesCSV(in)
+	if err != nil {
+		log.Fatalf("Invalid rate file: %s", err)
+	}
+
+	if *dryRun {
+		log.Printf("Read %d rates, nothing stored", len(inputs))
+		return
+	}
+
+	// Load config
+	cfg, err := config.NewConfig()
+	if err != nil {
+		log.Fatalf("Config error: %s", err)
+	}
+
+	// Connect to database
+	pg, err := postgres.New(cfg.PG.URL, postgres.MaxPoolSize(cfg.PG.PoolMax))
+	if err != nil {
+		log.Fatalf("Database connection error: %s", err)
+	}
+	defer pg.Close()
+
+	uc := fxusecase.NewRateUseCase(repository.New(pg.DB))
+
+	rates, err := uc.ImportRates(context.Background(), inputs)
+	if err != nil {
+		log.Fatalf("Import failed: %s", err)
+	}
+
+	log.Printf("Imported %d rates", len(rates))
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"os"
+
+	"wallet_api/config"
+	resp "wallet_api/internal/module/account/dto/response"
+	"wallet_api/internal/module/account/repository"
+	accountusecase "wallet_api/internal/module/account/usecase"
+	"wallet_api/pkg/postgres"
+
+	"github.com/google/uuid"
+)
+
+// reconcile checks every wallet balance against its transaction history and the ledger.
+// It exits with status 1 when drift is left uncorrected, so it can run from cron.
+func main() {
+	walletFlag := flag.String("wallet", "", "only check this wallet ID")
+	format := flag.String("format", "json", "report format: json or csv")
+	output := flag.String("output", "", "write the report to this file instead of stdout")
+	fix := flag.Bool("fix", false, "write adjustment entries for balance and ledger mismatches")
+	reason := flag.String("reason", "", "reason recorded on every adjustment, required with --fix")
+	flag.Parse()
+
+	if *format != "json" && *format != "csv" {
+		log.Fatalf("Unknown format %q, use json or csv", *format)
+	}
+
+	var walletID *uuid.UUID
+	if *walletFlag != "" {
+		id, err := uuid.Parse(*walletFlag)
+		if err != nil {
+			log.Fatalf("Invalid wallet ID: %s", err)
+		}
+		walletID = &id
+	}
+
+	// Load config
+	cfg, err := config.NewConfig()
+	if err != nil {
+		log.Fatalf("Config error: %s", err)
+	}
+
+	// Connect to database
+	pg, err := postgres.New(cfg.PG.URL, postgres.MaxPoolSize(cfg.PG.PoolMax))
+	if err != nil {
+		log.Fatalf("Database connection error: %s", err)
+	}
+	defer pg.Close()
+
+	uc := accountusecase.NewReconcileUseCase(repository.NewReconcileRepository(pg.DB), repository.NewUnitOfWork(pg.DB))
+
+	ctx := context.Background()
+	var report *accountusecase.ReconcileReport
+	if *fix {
+		report, err = uc.Fix(ctx, walletID, *reason)
+	} else {
+		report, err = uc.Reconcile(ctx, walletID)
+	}
+	if err != nil {
+		log.Fatalf("Reconciliation failed: %s", err)
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			log.Fatalf("Failed to create output file: %s", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if err := writeReport(out, *format, report); err != nil {
+		log.Fatalf("Failed to write report: %s", err)
+	}
+
+	log.Printf("Checked %d wallets, found %d discrepancies", report.CheckedWallets, len(report.Discrepancies))
+	for _, discrepancy := range report.Discrepancies {
+		if discrepancy.AdjustmentReference == "" {
+			pg.Close()
+			os.Exit(1)
+		}
+	}
+}
+
+func writeReport(w io.Writer, format string, report *accountusecase.ReconcileReport) error {
+	if format == "csv" {
+		return resp.WriteReconcileCSV(w, report)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(resp.ToReconcileReportDto(report))
+}
@@ -2,9 +2,12 @@ package config
 
 import (
 	"fmt"
+	"reflect"
+	"time"
 
 	"github.com/caarlos0/env/v11"
 	"github.com/joho/godotenv"
+	"github.com/shopspring/decimal"
 )
 
 func init() {
@@ -19,6 +22,18 @@ type (
 		Log  Log
 		PG   PG
 		JWT  JWT
+		CORS CORS
+
+		LoginThrottle     LoginThrottle
+		Mail              Mail
+		PasswordReset     PasswordReset
+		EmailVerification EmailVerification
+		StepUp            StepUp
+		Idempotency       Idempotency
+		Reversal          Reversal
+		Hold              Hold
+		Schedule          Schedule
+		FX                FX
 	}
 
 	// App -.
@@ -33,6 +48,11 @@ type (
 		UsePreforkMode bool   `env:"HTTP_USE_PREFORK_MODE" envDefault:"false"`
 	}
 
+	// CORS - origin frontend yang boleh memanggil API dengan cookie.
+	CORS struct {
+		AllowOrigins []string `env:"CORS_ALLOW_ORIGINS" envSeparator:"," envDefault:"http://localhost:3000"`
+	}
+
 	// Log -.
 	Log struct {
 		Level string `env:"LOG_LEVEL,required"`
@@ -46,16 +66,93 @@ type (
 
 	// JWT -.
 	JWT struct {
-		Secret             string `env:"JWT_SECRET,required"`
+		KeysDir            string `env:"JWT_KEYS_DIR,required"`      // berisi <kid>.pem (private) dan <kid>.pub.pem (key lama)
+		ActiveKeyID        string `env:"JWT_ACTIVE_KEY_ID,required"` // kid yang dipakai untuk sign token baru
 		AccessTokenExpiry  int    `env:"ACCESS_TOKEN_EXPIRY" envDefault:"15"`
 		RefreshTokenExpiry int    `env:"REFRESH_TOKEN_EXPIRY" envDefault:"7"`
 	}
+
+	// LoginThrottle - batas percobaan login gagal per username dan per IP.
+	LoginThrottle struct {
+		MaxFailures     int           `env:"LOGIN_MAX_FAILURES" envDefault:"5"`     // gagal sebanyak ini dalam Window -> lockout
+		MaxIPFailures   int           `env:"LOGIN_MAX_IP_FAILURES" envDefault:"50"` // sama, tapi per IP
+		Window          time.Duration `env:"LOGIN_FAILURE_WINDOW" envDefault:"15m"`
+		LockoutDuration time.Duration `env:"LOGIN_LOCKOUT_DURATION" envDefault:"15m"`
+		DelayAfter      int           `env:"LOGIN_DELAY_AFTER" envDefault:"3"` // mulai delay setelah gagal sebanyak ini
+		BaseDelay       time.Duration `env:"LOGIN_BASE_DELAY" envDefault:"1s"` // delay dobel setiap kegagalan berikutnya
+	}
+
+	// Mail - driver: smtp, file (tulis .eml ke FileDir) atau log.
+	Mail struct {
+		Driver       string `env:"MAIL_DRIVER" envDefault:"log"`
+		From         string `env:"MAIL_FROM" envDefault:"wallet_api <no-reply@wallet-api.local>"`
+		SMTPHost     string `env:"MAIL_SMTP_HOST"`
+		SMTPPort     string `env:"MAIL_SMTP_PORT" envDefault:"587"`
+		SMTPUsername string `env:"MAIL_SMTP_USERNAME"`
+		SMTPPassword string `env:"MAIL_SMTP_PASSWORD"`
+		FileDir      string `env:"MAIL_FILE_DIR" envDefault:"./tmp/mail"`
+	}
+
+	// PasswordReset - URL halaman reset di frontend, token ditambahkan sebagai ?token=.
+	PasswordReset struct {
+		URL      string        `env:"PASSWORD_RESET_URL" envDefault:"http://localhost:3000/reset-password"`
+		TokenTTL time.Duration `env:"PASSWORD_RESET_TOKEN_TTL" envDefault:"30m"`
+	}
+
+	// EmailVerification - URL halaman verifikasi email di frontend, token ditambahkan sebagai ?token=.
+	EmailVerification struct {
+		URL      string        `env:"EMAIL_VERIFICATION_URL" envDefault:"http://localhost:3000/verify-email"`
+		TokenTTL time.Duration `env:"EMAIL_VERIFICATION_TOKEN_TTL" envDefault:"24h"`
+	}
+
+	// StepUp - tarik/transfer di atas batas per mata uang butuh PIN atau step-up token.
+	// Mata uang yang tidak ada di daftar selalu butuh PIN.
+	StepUp struct {
+		Thresholds map[string]decimal.Decimal `env:"STEP_UP_THRESHOLDS" envDefault:"IDR:1000000,USD:100"`
+		TokenTTL   time.Duration              `env:"STEP_UP_TOKEN_TTL" envDefault:"5m"`
+	}
+
+	// Idempotency - berapa lama response deposit/tarik/transfer disimpan untuk Idempotency-Key yang sama.
+	Idempotency struct {
+		KeyTTL time.Duration `env:"IDEMPOTENCY_KEY_TTL" envDefault:"24h"`
+	}
+
+	// Reversal - kalau saldo wallet tidak cukup untuk reversal: fail (tolak), negative (saldo boleh minus)
+	// atau hold (saldo boleh minus dan wallet dibekukan). Bisa di-override per request.
+	Reversal struct {
+		InsufficientBalancePolicy string `env:"REVERSAL_INSUFFICIENT_BALANCE_POLICY" envDefault:"fail"`
+	}
+
+	// Hold - berapa lama hold menahan saldo kalau tidak di-capture atau di-void.
+	Hold struct {
+		DefaultTTL time.Duration `env:"HOLD_DEFAULT_TTL" envDefault:"168h"`
+		MaxTTL     time.Duration `env:"HOLD_MAX_TTL" envDefault:"720h"`
+	}
+
+	// Schedule - worker yang menjalankan transfer terjadwal. Aman jalan di beberapa replica sekaligus.
+	Schedule struct {
+		WorkerEnabled  bool          `env:"SCHEDULE_WORKER_ENABLED" envDefault:"true"`
+		WorkerInterval time.Duration `env:"SCHEDULE_WORKER_INTERVAL" envDefault:"30s"`
+		BatchSize      int           `env:"SCHEDULE_BATCH_SIZE" envDefault:"100"`
+	}
+
+	// FX - berapa lama quote mengunci rate sebelum harus minta quote baru.
+	FX struct {
+		QuoteTTL time.Duration `env:"FX_QUOTE_TTL" envDefault:"30s"`
+	}
 )
 
+// parsers handles field types env does not know about.
+var parsers = map[reflect.Type]env.ParserFunc{
+	reflect.TypeOf(decimal.Decimal{}): func(v string) (interface{}, error) {
+		return decimal.NewFromString(v)
+	},
+}
+
 // NewConfig returns app config.
 func NewConfig() (*Config, error) {
 	cfg := &Config{}
-	if err := env.Parse(cfg); err != nil {
+	if err := env.ParseWithOptions(cfg, env.Options{FuncMap: parsers}); err != nil {
 		return nil, fmt.Errorf("config error: %w", err)
 	}
 
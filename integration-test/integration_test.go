@@ -9,10 +9,18 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"wallet_api/internal/utils"
+
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 const (
@@ -30,6 +38,8 @@ const (
 	authPath          = basePathV1 + "/auth"
 	userPath          = basePathV1 + "/users"
 	accountPath       = basePathV1 + "/accounts"
+	walletPath        = basePathV1 + "/wallets"
+	schedulePath      = basePathV1 + "/transfer-schedules"
 )
 
 var (
@@ -67,6 +77,7 @@ type UpdateProfileRequest struct {
 type UserResponse struct {
 	ID        string `json:"id"`
 	Username  string `json:"username"`
+	Email     string `json:"email"`
 	CreatedAt string `json:"created_at"`
 }
 
@@ -109,6 +120,34 @@ type TransactionResponse struct {
 	CreatedAt     string `json:"created_at"`
 }
 
+// Wallet data structures
+type CreateWalletRequest struct {
+	WalletName string `json:"wallet_name"`
+	Currency   string `json:"currency"`
+}
+
+type WalletResponse struct {
+	ID         string `json:"id"`
+	UserID     string `json:"user_id"`
+	WalletName string `json:"wallet_name"`
+	Currency   string `json:"currency"`
+	Balance    string `json:"balance"`
+	// Balance minus active holds
+	AvailableBalance string `json:"available_balance"`
+	Status           string `json:"status"`
+}
+
+type WalletTransactionRequest struct {
+	Amount      string `json:"amount"`
+	Description string `json:"description"`
+}
+
+type WalletTransferRequest struct {
+	ToWalletID  string `json:"to_wallet_id"`
+	Amount      string `json:"amount"`
+	Description string `json:"description"`
+}
+
 func init() {
 	testHTTPClient = &http.Client{
 		Timeout: requestTimeout,
@@ -117,6 +156,11 @@ func init() {
 
 // Helper function to make HTTP requests
 func makeRequest(method, url string, body interface{}, cookies []*http.Cookie) (*http.Response, error) {
+	return makeRequestWithHeaders(method, url, body, cookies, nil)
+}
+
+// Helper function to make HTTP requests with extra headers (e.g. Authorization)
+func makeRequestWithHeaders(method, url string, body interface{}, cookies []*http.Cookie, headers map[string]string) (*http.Response, error) {
 	var reqBody io.Reader
 	if body != nil {
 		jsonBody, err := json.Marshal(body)
@@ -132,10 +176,16 @@ func makeRequest(method, url string, body interface{}, cookies []*http.Cookie) (
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
 
-	// Add cookies if provided
+	// Add cookies if provided, echoing the CSRF cookie in its header like the frontend does
 	for _, cookie := range cookies {
 		req.AddCookie(cookie)
+		if cookie.Name == "csrf_token" && req.Header.Get("X-CSRF-Token") == "" {
+			req.Header.Set("X-CSRF-Token", cookie.Value)
+		}
 	}
 
 	return testHTTPClient.Do(req)
@@ -459,6 +509,17 @@ func TestLogoutAndRefresh(t *testing.T) {
 		}
 	})
 
+	t.Run("Access After Logout Should Fail", func(t *testing.T) {
+		resp, err := makeRequest(http.MethodGet, userPath+"/profile", nil, cookies)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected status 401 for revoked session, got %d", resp.StatusCode)
+		}
+	})
+
 	t.Run("Refresh Token", func(t *testing.T) {
 		// Login again to get fresh tokens
 		loginReq := LoginRequest{
@@ -1115,3 +1176,1927 @@ func TestEdgeCases(t *testing.T) {
 		}
 	})
 }
+
+// ============================================================================
+// WALLET OWNERSHIP TESTS
+// ============================================================================
+
+// registerWalletUser registers a fresh user and returns its auth cookies.
+func registerWalletUser(t *testing.T, prefix string) []*http.Cookie {
+	t.Helper()
+
+	suffix := uuid.New().String()[:8]
+	registerReq := map[string]string{
+		"username": fmt.Sprintf("%s_%s", prefix, suffix),
+		"email":    fmt.Sprintf("%s_%s@example.com", prefix, suffix),
+		"password": "password123",
+	}
+
+	resp, err := makeRequest(http.MethodPost, authPath+"/register", registerReq, nil)
+	if err != nil {
+		t.Fatalf("Failed to register %s: %v", prefix, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Failed to register %s, status: %d", prefix, resp.StatusCode)
+	}
+
+	return resp.Cookies()
+}
+
+// createWallet creates an IDR wallet for the given user and returns it.
+func createWallet(t *testing.T, cookies []*http.Cookie, name string) WalletResponse {
+	t.Helper()
+
+	resp, err := makeRequest(http.MethodPost, walletPath, CreateWalletRequest{WalletName: name, Currency: "IDR"}, cookies)
+	if err != nil {
+		t.Fatalf("Failed to create wallet: %v", err)
+	}
+
+	testResp, err := parseResponse(resp)
+	if err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	data, _ := json.Marshal(testResp.Data)
+	var wallet WalletResponse
+	if err := json.Unmarshal(data, &wallet); err != nil {
+		t.Fatalf("Failed to unmarshal wallet: %v", err)
+	}
+
+	return wallet
+}
+
+func TestWalletOwnership(t *testing.T) {
+	ownerCookies := registerWalletUser(t, "owner")
+	otherCookies := registerWalletUser(t, "intruder")
+
+	ownerWallet := createWallet(t, ownerCookies, "Owner Wallet")
+	otherWallet := createWallet(t, otherCookies, "Intruder Wallet")
+
+	// Fund the owner's wallet so that a successful withdraw or transfer by
+	// the intruder would be observable.
+	url := fmt.Sprintf("%s/%s/deposit", walletPath, ownerWallet.ID)
+	resp, err := makeRequest(http.MethodPost, url, WalletTransactionRequest{Amount: "100000"}, ownerCookies)
+	if err != nil {
+		t.Fatalf("Failed to deposit: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Owner deposit failed, status: %d", resp.StatusCode)
+	}
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+	}{
+		{"Get Wallet", http.MethodGet, "", nil},
+		{"Deposit", http.MethodPost, "/deposit", WalletTransactionRequest{Amount: "1000"}},
+		{"Withdraw", http.MethodPost, "/withdraw", WalletTransactionRequest{Amount: "1000"}},
+		{"Transfer", http.MethodPost, "/transfer", WalletTransferRequest{ToWalletID: otherWallet.ID, Amount: "1000"}},
+		{"Get Transactions", http.MethodGet, "/transactions", nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name+" On Foreign Wallet Should Fail", func(t *testing.T) {
+			url := fmt.Sprintf("%s/%s%s", walletPath, ownerWallet.ID, tc.path)
+			resp, err := makeRequest(tc.method, url, tc.body, otherCookies)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusNotFound {
+				t.Errorf("Expected status 404, got %d", resp.StatusCode)
+			}
+		})
+	}
+
+	t.Run("Owner Balance Is Untouched", func(t *testing.T) {
+		url := fmt.Sprintf("%s/%s", walletPath, ownerWallet.ID)
+		resp, err := makeRequest(http.MethodGet, url, nil, ownerCookies)
+		if err != nil {
+			t.Fatalf("Failed to get wallet: %v", err)
+		}
+
+		testResp, err := parseResponse(resp)
+		if err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+
+		data, _ := json.Marshal(testResp.Data)
+		var wallet WalletResponse
+		if err := json.Unmarshal(data, &wallet); err != nil {
+			t.Fatalf("Failed to unmarshal wallet: %v", err)
+		}
+
+		if wallet.Balance != "100000" {
+			t.Errorf("Expected balance 100000, got %s", wallet.Balance)
+		}
+	})
+}
+
+// ============================================================================
+// REFRESH TOKEN ROTATION TESTS
+// ============================================================================
+
+func findCookie(cookies []*http.Cookie, name string) *http.Cookie {
+	for _, cookie := range cookies {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	originalCookies := registerWalletUser(t, "rotation")
+
+	resp, err := makeRequest(http.MethodPost, authPath+"/refresh", nil, originalCookies)
+	if err != nil {
+		t.Fatalf("Failed to refresh token: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 on first refresh, got %d", resp.StatusCode)
+	}
+
+	rotatedCookies := resp.Cookies()
+
+	t.Run("Access Token Cannot Be Used As Refresh Token", func(t *testing.T) {
+		accessToken := findCookie(rotatedCookies, "access_token")
+		if accessToken == nil {
+			t.Fatal("Access token cookie not set after refresh")
+		}
+
+		// Keep the CSRF cookie so the request gets as far as the token check
+		forged := []*http.Cookie{{Name: "refresh_token", Value: accessToken.Value}, findCookie(rotatedCookies, "csrf_token")}
+		resp, err := makeRequest(http.MethodPost, authPath+"/refresh", nil, forged)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Refresh Token Cannot Be Used As Access Token", func(t *testing.T) {
+		refreshToken := findCookie(rotatedCookies, "refresh_token")
+		if refreshToken == nil {
+			t.Fatal("Refresh token cookie not set after refresh")
+		}
+
+		forged := []*http.Cookie{{Name: "access_token", Value: refreshToken.Value}}
+		resp, err := makeRequest(http.MethodGet, userPath+"/profile", nil, forged)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Reusing Rotated Refresh Token Revokes Session", func(t *testing.T) {
+		resp, err := makeRequest(http.MethodPost, authPath+"/refresh", nil, originalCookies)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected status 401 on reuse, got %d", resp.StatusCode)
+		}
+
+		resp, err = makeRequest(http.MethodGet, userPath+"/profile", nil, rotatedCookies)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected rotated tokens to be revoked, got %d", resp.StatusCode)
+		}
+	})
+}
+
+// ============================================================================
+// ADMIN ACCESS TESTS
+// ============================================================================
+
+func TestAdminRoutesRequireAdminRole(t *testing.T) {
+	cookies := registerWalletUser(t, "regular")
+	wallet := createWallet(t, cookies, "Regular Wallet")
+
+	paths := []struct {
+		method string
+		url    string
+	}{
+		{http.MethodGet, basePathV1 + "/admin/users"},
+		{http.MethodGet, basePathV1 + "/admin/wallets/" + wallet.ID},
+		{http.MethodPost, basePathV1 + "/admin/wallets/" + wallet.ID + "/freeze"},
+		{http.MethodGet, basePathV1 + "/admin/reconciliation"},
+		{http.MethodPost, basePathV1 + "/admin/reconciliation/fix"},
+		{http.MethodPost, basePathV1 + "/admin/transactions/" + uuid.New().String() + "/reverse"},
+		{http.MethodPost, basePathV1 + "/admin/fx/rates"},
+		{http.MethodGet, basePathV1 + "/admin/fx/rates"},
+		{http.MethodGet, basePathV1 + "/admin/currencies"},
+		{http.MethodPatch, basePathV1 + "/admin/currencies/IDR"},
+	}
+
+	for _, p := range paths {
+		t.Run(p.method+" "+p.url, func(t *testing.T) {
+			resp, err := makeRequest(p.method, p.url, nil, cookies)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusForbidden {
+				t.Errorf("Expected status 403, got %d", resp.StatusCode)
+			}
+		})
+	}
+}
+
+// ============================================================================
+// TWO-FACTOR AUTHENTICATION TESTS
+// ============================================================================
+
+func decodeData(t *testing.T, resp *http.Response, out interface{}) {
+	t.Helper()
+
+	testResp, err := parseResponse(resp)
+	if err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	data, _ := json.Marshal(testResp.Data)
+	if err := json.Unmarshal(data, out); err != nil {
+		t.Fatalf("Failed to unmarshal data: %v", err)
+	}
+}
+
+func TestTwoFactorLogin(t *testing.T) {
+	username := fmt.Sprintf("totp_%s", uuid.New().String()[:8])
+	registerReq := map[string]string{
+		"username": username,
+		"email":    username + "@example.com",
+		"password": "password123",
+	}
+
+	resp, err := makeRequest(http.MethodPost, authPath+"/register", registerReq, nil)
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+	resp.Body.Close()
+	cookies := resp.Cookies()
+
+	resp, err = makeRequest(http.MethodPost, userPath+"/2fa/enroll", nil, cookies)
+	if err != nil {
+		t.Fatalf("Failed to enroll: %v", err)
+	}
+
+	var enrollment struct {
+		Secret     string `json:"secret"`
+		OTPAuthURI string `json:"otpauth_uri"`
+	}
+	decodeData(t, resp, &enrollment)
+
+	code, err := utils.TOTPCode(enrollment.Secret, time.Now())
+	if err != nil {
+		t.Fatalf("Failed to compute TOTP code: %v", err)
+	}
+
+	resp, err = makeRequest(http.MethodPost, userPath+"/2fa/confirm", map[string]string{"code": code}, cookies)
+	if err != nil {
+		t.Fatalf("Failed to confirm: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 on confirm, got %d", resp.StatusCode)
+	}
+
+	var recovery struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+	decodeData(t, resp, &recovery)
+
+	if len(recovery.RecoveryCodes) == 0 {
+		t.Fatal("Expected recovery codes after confirming 2FA")
+	}
+
+	var challenge struct {
+		TwoFactorRequired bool   `json:"two_factor_required"`
+		TwoFactorToken    string `json:"two_factor_token"`
+	}
+
+	t.Run("Password Login Returns Challenge", func(t *testing.T) {
+		resp, err := makeRequest(http.MethodPost, authPath+"/login", LoginRequest{Username: username, Password: "password123"}, nil)
+		if err != nil {
+			t.Fatalf("Failed to login: %v", err)
+		}
+
+		if findCookie(resp.Cookies(), "access_token") != nil {
+			t.Error("Access token must not be issued before the second factor")
+		}
+
+		decodeData(t, resp, &challenge)
+		if !challenge.TwoFactorRequired || challenge.TwoFactorToken == "" {
+			t.Fatal("Expected a two-factor challenge")
+		}
+	})
+
+	t.Run("Recovery Code Completes Login Once", func(t *testing.T) {
+		body := map[string]string{
+			"two_factor_token": challenge.TwoFactorToken,
+			"code":             recovery.RecoveryCodes[0],
+		}
+
+		resp, err := makeRequest(http.MethodPost, authPath+"/login/2fa", body, nil)
+		if err != nil {
+			t.Fatalf("Failed to complete login: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		if findCookie(resp.Cookies(), "access_token") == nil {
+			t.Error("Expected access token after the second factor")
+		}
+
+		resp, err = makeRequest(http.MethodPost, authPath+"/login/2fa", body, nil)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected used recovery code to be rejected, got %d", resp.StatusCode)
+		}
+	})
+}
+
+// ============================================================================
+// BEARER TOKEN TESTS
+// ============================================================================
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+func TestBearerAuthentication(t *testing.T) {
+	username := fmt.Sprintf("bearer_%s", uuid.New().String()[:8])
+	registerReq := map[string]string{
+		"username": username,
+		"email":    username + "@example.com",
+		"password": "password123",
+	}
+
+	resp, err := makeRequest(http.MethodPost, authPath+"/register", registerReq, nil)
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+	resp.Body.Close()
+	cookies := resp.Cookies()
+
+	var tokens TokenResponse
+
+	t.Run("Body Login Returns Tokens Without Cookies", func(t *testing.T) {
+		loginReq := map[string]string{
+			"username":       username,
+			"password":       "password123",
+			"token_delivery": "body",
+		}
+
+		resp, err := makeRequest(http.MethodPost, authPath+"/login", loginReq, nil)
+		if err != nil {
+			t.Fatalf("Failed to login: %v", err)
+		}
+
+		if findCookie(resp.Cookies(), "access_token") != nil {
+			t.Error("Body login must not set auth cookies")
+		}
+
+		var auth struct {
+			Tokens TokenResponse `json:"tokens"`
+		}
+		decodeData(t, resp, &auth)
+		tokens = auth.Tokens
+
+		if tokens.AccessToken == "" || tokens.RefreshToken == "" {
+			t.Fatal("Expected tokens in the response body")
+		}
+	})
+
+	t.Run("Bearer Token Authenticates", func(t *testing.T) {
+		headers := map[string]string{"Authorization": "Bearer " + tokens.AccessToken}
+
+		resp, err := makeRequestWithHeaders(http.MethodGet, userPath+"/profile", nil, nil, headers)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Authorization Header Takes Precedence Over Cookie", func(t *testing.T) {
+		headers := map[string]string{"Authorization": "Bearer not-a-token"}
+
+		resp, err := makeRequestWithHeaders(http.MethodGet, userPath+"/profile", nil, cookies, headers)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Refresh With Token In Body", func(t *testing.T) {
+		body := map[string]string{"refresh_token": tokens.RefreshToken}
+
+		resp, err := makeRequest(http.MethodPost, authPath+"/token/refresh", body, nil)
+		if err != nil {
+			t.Fatalf("Failed to refresh: %v", err)
+		}
+
+		var refreshed TokenResponse
+		decodeData(t, resp, &refreshed)
+
+		if refreshed.AccessToken == "" || refreshed.RefreshToken == tokens.RefreshToken {
+			t.Error("Expected a new token pair in the response body")
+		}
+	})
+}
+
+// ============================================================================
+// API KEY TESTS
+// ============================================================================
+
+func TestAPIKeyScopes(t *testing.T) {
+	cookies := registerWalletUser(t, "apikey")
+	allowedWallet := createWallet(t, cookies, "Allowed Wallet")
+	otherWallet := createWallet(t, cookies, "Other Wallet")
+
+	createReq := map[string]interface{}{
+		"name":       "reporting job",
+		"scopes":     []string{"wallets:read"},
+		"wallet_ids": []string{allowedWallet.ID},
+	}
+
+	resp, err := makeRequest(http.MethodPost, basePathV1+"/api-keys", createReq, cookies)
+	if err != nil {
+		t.Fatalf("Failed to create API key: %v", err)
+	}
+
+	var created struct {
+		ID  string `json:"id"`
+		Key string `json:"key"`
+	}
+	decodeData(t, resp, &created)
+
+	if created.Key == "" {
+		t.Fatal("Expected the plaintext key in the create response")
+	}
+
+	keyHeaders := map[string]string{"X-API-Key": created.Key}
+
+	cases := []struct {
+		name   string
+		method string
+		url    string
+		body   interface{}
+		status int
+	}{
+		{"Read Allowed Wallet", http.MethodGet, fmt.Sprintf("%s/%s", walletPath, allowedWallet.ID), nil, http.StatusOK},
+		{"Read Wallet Outside Restriction", http.MethodGet, fmt.Sprintf("%s/%s", walletPath, otherWallet.ID), nil, http.StatusForbidden},
+		{"Deposit Without Write Scope", http.MethodPost, fmt.Sprintf("%s/%s/deposit", walletPath, allowedWallet.ID), WalletTransactionRequest{Amount: "1000"}, http.StatusForbidden},
+		{"Manage Keys With A Key", http.MethodGet, basePathV1 + "/api-keys", nil, http.StatusUnauthorized},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := makeRequestWithHeaders(tc.method, tc.url, tc.body, nil, keyHeaders)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Errorf("Expected status %d, got %d", tc.status, resp.StatusCode)
+			}
+		})
+	}
+
+	t.Run("Revoked Key Is Rejected", func(t *testing.T) {
+		resp, err := makeRequest(http.MethodDelete, basePathV1+"/api-keys/"+created.ID, nil, cookies)
+		if err != nil {
+			t.Fatalf("Failed to revoke API key: %v", err)
+		}
+		resp.Body.Close()
+
+		resp, err = makeRequestWithHeaders(http.MethodGet, fmt.Sprintf("%s/%s", walletPath, allowedWallet.ID), nil, nil, keyHeaders)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", resp.StatusCode)
+		}
+	})
+}
+
+// ============================================================================
+// JWKS TESTS
+// ============================================================================
+
+func TestJWKSEndpoint(t *testing.T) {
+	resp, err := makeRequest(http.MethodGet, httpURL+"/.well-known/jwks.json", nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to get JWKS: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var jwks struct {
+		Keys []struct {
+			KeyID     string `json:"kid"`
+			Algorithm string `json:"alg"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		t.Fatalf("Failed to decode JWKS: %v", err)
+	}
+
+	if len(jwks.Keys) == 0 {
+		t.Fatal("Expected at least one published key")
+	}
+	for _, key := range jwks.Keys {
+		if key.KeyID == "" || (key.Algorithm != "RS256" && key.Algorithm != "EdDSA") {
+			t.Errorf("Unexpected key %+v", key)
+		}
+	}
+}
+
+// ============================================================================
+// LOGIN THROTTLING TESTS
+// ============================================================================
+
+func TestLoginThrottling(t *testing.T) {
+	username := fmt.Sprintf("throttle_%s", uuid.New().String()[:8])
+	registerReq := map[string]string{
+		"username": username,
+		"email":    username + "@example.com",
+		"password": "password123",
+	}
+
+	resp, err := makeRequest(http.MethodPost, authPath+"/register", registerReq, nil)
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+	resp.Body.Close()
+
+	login := func(password string) (int, string) {
+		resp, err := makeRequest(http.MethodPost, authPath+"/login", LoginRequest{Username: username, Password: password}, nil)
+		if err != nil {
+			t.Fatalf("Failed to login: %v", err)
+		}
+
+		testResp, err := parseResponse(resp)
+		if err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		return resp.StatusCode, testResp.Message
+	}
+
+	_, badCredentialsMessage := login("wrong-password")
+	login("wrong-password")
+	login("wrong-password")
+
+	t.Run("Throttled Login Looks Like Bad Credentials", func(t *testing.T) {
+		status, message := login("password123")
+
+		if status != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", status)
+		}
+		if message != badCredentialsMessage {
+			t.Errorf("Expected message %q, got %q", badCredentialsMessage, message)
+		}
+	})
+}
+
+// ============================================================================
+// PASSWORD RESET TESTS
+// ============================================================================
+
+func TestPasswordReset(t *testing.T) {
+	cookies := registerWalletUser(t, "reset")
+
+	resp, err := makeRequest(http.MethodGet, userPath+"/profile", nil, cookies)
+	if err != nil {
+		t.Fatalf("Failed to get profile: %v", err)
+	}
+	var profile UserResponse
+	decodeData(t, resp, &profile)
+
+	forgot := func(email string) (int, string) {
+		resp, err := makeRequest(http.MethodPost, authPath+"/password/forgot", map[string]string{"email": email}, nil)
+		if err != nil {
+			t.Fatalf("Failed to request reset: %v", err)
+		}
+
+		testResp, err := parseResponse(resp)
+		if err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		return resp.StatusCode, testResp.Message
+	}
+
+	t.Run("Unknown Email Looks Like Known Email", func(t *testing.T) {
+		knownStatus, knownMessage := forgot(profile.Email)
+		unknownStatus, unknownMessage := forgot("nobody_" + uuid.New().String()[:8] + "@example.com")
+
+		if knownStatus != http.StatusOK || unknownStatus != http.StatusOK {
+			t.Errorf("Expected status 200 for both, got %d and %d", knownStatus, unknownStatus)
+		}
+		if knownMessage != unknownMessage {
+			t.Errorf("Expected identical messages, got %q and %q", knownMessage, unknownMessage)
+		}
+	})
+
+	t.Run("Invalid Token Is Rejected", func(t *testing.T) {
+		body := map[string]string{"token": "not-a-real-token", "new_password": "newpassword123"}
+
+		resp, err := makeRequest(http.MethodPost, authPath+"/password/reset", body, nil)
+		if err != nil {
+			t.Fatalf("Failed to reset password: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", resp.StatusCode)
+		}
+	})
+}
+
+// ============================================================================
+// EMAIL VERIFICATION TESTS
+// ============================================================================
+
+var verificationTokenPattern = regexp.MustCompile(`token=([0-9a-f]+)`)
+
+// readMailedToken finds the newest mail to the given address in MAIL_FILE_DIR
+// (the server must run with MAIL_DRIVER=file) and returns the token in its link.
+func readMailedToken(t *testing.T, email string) string {
+	t.Helper()
+
+	dir := os.Getenv("MAIL_FILE_DIR")
+	if dir == "" {
+		t.Skip("MAIL_FILE_DIR not set, cannot read mailed tokens")
+	}
+
+	// File names start with a timestamp, so the last match is the newest mail
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil {
+		t.Fatalf("Failed to list mail: %v", err)
+	}
+	sort.Strings(files)
+
+	for i := len(files) - 1; i >= 0; i-- {
+		raw, err := os.ReadFile(files[i])
+		if err != nil {
+			t.Fatalf("Failed to read mail: %v", err)
+		}
+		if !strings.Contains(string(raw), "To: "+email+"\r\n") {
+			continue
+		}
+		if match := verificationTokenPattern.FindSubmatch(raw); match != nil {
+			return string(match[1])
+		}
+	}
+
+	t.Fatalf("No mail with a token found for %s", email)
+	return ""
+}
+
+func TestEmailVerification(t *testing.T) {
+	suffix := uuid.New().String()[:8]
+	email := fmt.Sprintf("verify_%s@example.com", suffix)
+	registerReq := map[string]string{
+		"username": "verify_" + suffix,
+		"email":    email,
+		"password": "password123",
+	}
+
+	resp, err := makeRequest(http.MethodPost, authPath+"/register", registerReq, nil)
+	if err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+	resp.Body.Close()
+	cookies := resp.Cookies()
+
+	wallet := createWallet(t, cookies, "Unverified Wallet")
+	url := fmt.Sprintf("%s/%s/deposit", walletPath, wallet.ID)
+	resp, err = makeRequest(http.MethodPost, url, WalletTransactionRequest{Amount: "5000"}, cookies)
+	if err != nil {
+		t.Fatalf("Failed to deposit: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Deposit should work before verification, status: %d", resp.StatusCode)
+	}
+
+	withdraw := func() int {
+		url := fmt.Sprintf("%s/%s/withdraw", walletPath, wallet.ID)
+		resp, err := makeRequest(http.MethodPost, url, WalletTransactionRequest{Amount: "1000"}, cookies)
+		if err != nil {
+			t.Fatalf("Failed to withdraw: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	t.Run("Withdraw Blocked Until Verified", func(t *testing.T) {
+		if status := withdraw(); status != http.StatusForbidden {
+			t.Errorf("Expected status 403, got %d", status)
+		}
+	})
+
+	t.Run("Invalid Token Is Rejected", func(t *testing.T) {
+		resp, err := makeRequest(http.MethodPost, authPath+"/email/verify", map[string]string{"token": "not-a-real-token"}, nil)
+		if err != nil {
+			t.Fatalf("Failed to verify email: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Verified Email Unblocks Withdraw", func(t *testing.T) {
+		token := readMailedToken(t, email)
+
+		resp, err := makeRequest(http.MethodPost, authPath+"/email/verify", map[string]string{"token": token}, nil)
+		if err != nil {
+			t.Fatalf("Failed to verify email: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+
+		if status := withdraw(); status != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", status)
+		}
+	})
+
+	t.Run("Changed Email Waits For Verification", func(t *testing.T) {
+		newEmail := fmt.Sprintf("verify_new_%s@example.com", suffix)
+		body := map[string]string{"username": "verify_" + suffix, "email": newEmail}
+
+		resp, err := makeRequest(http.MethodPut, userPath+"/profile", body, cookies)
+		if err != nil {
+			t.Fatalf("Failed to update profile: %v", err)
+		}
+
+		var profile struct {
+			Email        string `json:"email"`
+			PendingEmail string `json:"pending_email"`
+		}
+		decodeData(t, resp, &profile)
+
+		if profile.Email != email {
+			t.Errorf("Expected email to stay %s until verified, got %s", email, profile.Email)
+		}
+		if profile.PendingEmail != newEmail {
+			t.Errorf("Expected pending email %s, got %s", newEmail, profile.PendingEmail)
+		}
+	})
+}
+
+// ============================================================================
+// PASSWORD CHANGE TESTS
+// ============================================================================
+
+func TestChangePassword(t *testing.T) {
+	suffix := uuid.New().String()[:8]
+	username := "changepw_" + suffix
+	registerReq := map[string]string{
+		"username": username,
+		"email":    fmt.Sprintf("changepw_%s@example.com", suffix),
+		"password": "password123",
+	}
+
+	resp, err := makeRequest(http.MethodPost, authPath+"/register", registerReq, nil)
+	if err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+	resp.Body.Close()
+	current := resp.Cookies()
+
+	login := func(password string) (int, []*http.Cookie) {
+		resp, err := makeRequest(http.MethodPost, authPath+"/login", LoginRequest{Username: username, Password: password}, nil)
+		if err != nil {
+			t.Fatalf("Failed to login: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode, resp.Cookies()
+	}
+
+	_, other := login("password123")
+
+	changePassword := func(currentPassword, newPassword string) int {
+		body := map[string]string{"current_password": currentPassword, "new_password": newPassword}
+		resp, err := makeRequest(http.MethodPut, userPath+"/password", body, current)
+		if err != nil {
+			t.Fatalf("Failed to change password: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	profileStatus := func(cookies []*http.Cookie) int {
+		resp, err := makeRequest(http.MethodGet, userPath+"/profile", nil, cookies)
+		if err != nil {
+			t.Fatalf("Failed to get profile: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	t.Run("Wrong Current Password", func(t *testing.T) {
+		if status := changePassword("wrongpassword", "a much better passphrase"); status != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", status)
+		}
+	})
+
+	t.Run("Common Password Is Rejected", func(t *testing.T) {
+		if status := changePassword("password123", "qwerty123"); status != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", status)
+		}
+	})
+
+	t.Run("Change Signs Out Other Sessions", func(t *testing.T) {
+		if status := changePassword("password123", "a much better passphrase"); status != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", status)
+		}
+
+		if status := profileStatus(current); status != http.StatusOK {
+			t.Errorf("Expected current session to stay valid, got %d", status)
+		}
+		if status := profileStatus(other); status != http.StatusUnauthorized {
+			t.Errorf("Expected other session to be revoked, got %d", status)
+		}
+	})
+
+	t.Run("Login Uses New Password", func(t *testing.T) {
+		if status, _ := login("password123"); status != http.StatusUnauthorized {
+			t.Errorf("Expected old password to fail, got %d", status)
+		}
+		if status, _ := login("a much better passphrase"); status != http.StatusOK {
+			t.Errorf("Expected new password to work, got %d", status)
+		}
+	})
+}
+
+// verifyEmail follows the verification link mailed to the address
+func verifyEmail(t *testing.T, email string) {
+	t.Helper()
+
+	token := readMailedToken(t, email)
+	resp, err := makeRequest(http.MethodPost, authPath+"/email/verify", map[string]string{"token": token}, nil)
+	if err != nil {
+		t.Fatalf("Failed to verify email: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Failed to verify email, status: %d", resp.StatusCode)
+	}
+}
+
+// ============================================================================
+// TRANSACTION PIN TESTS
+// ============================================================================
+
+func TestTransactionPIN(t *testing.T) {
+	suffix := uuid.New().String()[:8]
+	email := fmt.Sprintf("pin_%s@example.com", suffix)
+	registerReq := map[string]string{
+		"username": "pin_" + suffix,
+		"email":    email,
+		"password": "password123",
+	}
+
+	resp, err := makeRequest(http.MethodPost, authPath+"/register", registerReq, nil)
+	if err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+	resp.Body.Close()
+	cookies := resp.Cookies()
+
+	verifyEmail(t, email)
+
+	wallet := createWallet(t, cookies, "PIN Wallet")
+	url := fmt.Sprintf("%s/%s/deposit", walletPath, wallet.ID)
+	resp, err = makeRequest(http.MethodPost, url, WalletTransactionRequest{Amount: "5000000"}, cookies)
+	if err != nil {
+		t.Fatalf("Failed to deposit: %v", err)
+	}
+	resp.Body.Close()
+
+	withdraw := func(body map[string]string) int {
+		url := fmt.Sprintf("%s/%s/withdraw", walletPath, wallet.ID)
+		resp, err := makeRequest(http.MethodPost, url, body, cookies)
+		if err != nil {
+			t.Fatalf("Failed to withdraw: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	t.Run("Small Withdraw Needs No PIN", func(t *testing.T) {
+		if status := withdraw(map[string]string{"amount": "1000"}); status != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", status)
+		}
+	})
+
+	t.Run("Large Withdraw Without PIN", func(t *testing.T) {
+		if status := withdraw(map[string]string{"amount": "1500000"}); status != http.StatusForbidden {
+			t.Errorf("Expected status 403, got %d", status)
+		}
+	})
+
+	resp, err = makeRequest(http.MethodPut, userPath+"/pin", map[string]string{"password": "password123", "pin": "482913"}, cookies)
+	if err != nil {
+		t.Fatalf("Failed to set PIN: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Failed to set PIN, status: %d", resp.StatusCode)
+	}
+
+	t.Run("Large Withdraw With Wrong PIN", func(t *testing.T) {
+		if status := withdraw(map[string]string{"amount": "1500000", "pin": "000000"}); status != http.StatusForbidden {
+			t.Errorf("Expected status 403, got %d", status)
+		}
+	})
+
+	t.Run("Large Withdraw With PIN", func(t *testing.T) {
+		if status := withdraw(map[string]string{"amount": "1500000", "pin": "482913"}); status != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", status)
+		}
+	})
+
+	t.Run("Large Withdraw With Step-Up Token", func(t *testing.T) {
+		resp, err := makeRequest(http.MethodPost, userPath+"/step-up", map[string]string{"pin": "482913"}, cookies)
+		if err != nil {
+			t.Fatalf("Failed to step up: %v", err)
+		}
+
+		var stepUp struct {
+			StepUpToken string `json:"step_up_token"`
+		}
+		decodeData(t, resp, &stepUp)
+
+		if status := withdraw(map[string]string{"amount": "1500000", "step_up_token": stepUp.StepUpToken}); status != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", status)
+		}
+	})
+}
+
+// ============================================================================
+// CSRF TESTS
+// ============================================================================
+
+func TestCSRFProtection(t *testing.T) {
+	cookies := registerWalletUser(t, "csrf")
+
+	// Only the auth cookies, as a cross-site form post would send them
+	var authCookies []*http.Cookie
+	for _, cookie := range cookies {
+		if cookie.Name != "csrf_token" {
+			authCookies = append(authCookies, cookie)
+		}
+	}
+
+	createWalletStatus := func(cookies []*http.Cookie, headers map[string]string) int {
+		body := CreateWalletRequest{WalletName: "CSRF Wallet", Currency: "IDR"}
+		resp, err := makeRequestWithHeaders(http.MethodPost, walletPath, body, cookies, headers)
+		if err != nil {
+			t.Fatalf("Failed to create wallet: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	t.Run("Cookie Post Without Token Is Rejected", func(t *testing.T) {
+		if status := createWalletStatus(authCookies, nil); status != http.StatusForbidden {
+			t.Errorf("Expected status 403, got %d", status)
+		}
+	})
+
+	t.Run("Cookie Post With Wrong Token Is Rejected", func(t *testing.T) {
+		if status := createWalletStatus(cookies, map[string]string{"X-CSRF-Token": "forged"}); status != http.StatusForbidden {
+			t.Errorf("Expected status 403, got %d", status)
+		}
+	})
+
+	t.Run("Cookie Post With Token Is Accepted", func(t *testing.T) {
+		if status := createWalletStatus(cookies, nil); status != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", status)
+		}
+	})
+
+	t.Run("Cookie Get Needs No Token", func(t *testing.T) {
+		resp, err := makeRequest(http.MethodGet, walletPath, nil, authCookies)
+		if err != nil {
+			t.Fatalf("Failed to list wallets: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Bearer Post Is Exempt", func(t *testing.T) {
+		accessToken := findCookie(cookies, "access_token")
+		if accessToken == nil {
+			t.Fatal("access_token cookie not found")
+		}
+
+		headers := map[string]string{"Authorization": "Bearer " + accessToken.Value}
+		if status := createWalletStatus(nil, headers); status != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", status)
+		}
+	})
+}
+
+// ============================================================================
+// SESSION MANAGEMENT TESTS
+// ============================================================================
+
+type SessionResponse struct {
+	ID         string `json:"id"`
+	UserAgent  string `json:"user_agent"`
+	IPAddress  string `json:"ip_address"`
+	LastSeenAt string `json:"last_seen_at"`
+	Current    bool   `json:"current"`
+}
+
+func TestSessionManagement(t *testing.T) {
+	suffix := uuid.New().String()[:8]
+	username := "sessions_" + suffix
+	registerReq := map[string]string{
+		"username": username,
+		"email":    fmt.Sprintf("sessions_%s@example.com", suffix),
+		"password": "password123",
+	}
+
+	resp, err := makeRequest(http.MethodPost, authPath+"/register", registerReq, nil)
+	if err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+	resp.Body.Close()
+	current := resp.Cookies()
+
+	login := func() []*http.Cookie {
+		resp, err := makeRequest(http.MethodPost, authPath+"/login", LoginRequest{Username: username, Password: "password123"}, nil)
+		if err != nil {
+			t.Fatalf("Failed to login: %v", err)
+		}
+		resp.Body.Close()
+		return resp.Cookies()
+	}
+
+	listSessions := func() []SessionResponse {
+		resp, err := makeRequest(http.MethodGet, userPath+"/sessions", nil, current)
+		if err != nil {
+			t.Fatalf("Failed to list sessions: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+
+		var sessions []SessionResponse
+		decodeData(t, resp, &sessions)
+		return sessions
+	}
+
+	revoke := func(path string) int {
+		resp, err := makeRequest(http.MethodDelete, userPath+path, nil, current)
+		if err != nil {
+			t.Fatalf("Failed to revoke session: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	profileStatus := func(cookies []*http.Cookie) int {
+		resp, err := makeRequest(http.MethodGet, userPath+"/profile", nil, cookies)
+		if err != nil {
+			t.Fatalf("Failed to get profile: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	other := login()
+
+	t.Run("List Marks Current Session", func(t *testing.T) {
+		sessions := listSessions()
+		if len(sessions) != 2 {
+			t.Fatalf("Expected 2 sessions, got %d", len(sessions))
+		}
+
+		currentCount := 0
+		for _, session := range sessions {
+			if session.Current {
+				currentCount++
+			}
+			if session.LastSeenAt == "" {
+				t.Errorf("Expected last_seen_at for session %s", session.ID)
+			}
+		}
+		if currentCount != 1 {
+			t.Errorf("Expected exactly one current session, got %d", currentCount)
+		}
+	})
+
+	t.Run("Revoke One Session", func(t *testing.T) {
+		var otherID string
+		for _, session := range listSessions() {
+			if !session.Current {
+				otherID = session.ID
+			}
+		}
+		if otherID == "" {
+			t.Fatal("Other session not found")
+		}
+
+		if status := revoke("/sessions/" + otherID); status != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", status)
+		}
+		if status := profileStatus(other); status != http.StatusUnauthorized {
+			t.Errorf("Expected revoked session to be rejected, got %d", status)
+		}
+		if status := profileStatus(current); status != http.StatusOK {
+			t.Errorf("Expected current session to stay valid, got %d", status)
+		}
+	})
+
+	t.Run("Unknown Session Returns 404", func(t *testing.T) {
+		if status := revoke("/sessions/" + uuid.New().String()); status != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", status)
+		}
+		if status := revoke("/sessions/not-a-uuid"); status != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", status)
+		}
+	})
+
+	t.Run("Foreign Session Returns 404", func(t *testing.T) {
+		stranger := registerWalletUser(t, "sessions_stranger")
+		resp, err := makeRequest(http.MethodGet, userPath+"/sessions", nil, stranger)
+		if err != nil {
+			t.Fatalf("Failed to list sessions: %v", err)
+		}
+		var sessions []SessionResponse
+		decodeData(t, resp, &sessions)
+		if len(sessions) != 1 {
+			t.Fatalf("Expected 1 session, got %d", len(sessions))
+		}
+
+		if status := revoke("/sessions/" + sessions[0].ID); status != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", status)
+		}
+		if status := profileStatus(stranger); status != http.StatusOK {
+			t.Errorf("Expected foreign session to stay valid, got %d", status)
+		}
+	})
+
+	t.Run("Revoke All Other Sessions", func(t *testing.T) {
+		others := [][]*http.Cookie{login(), login()}
+
+		if status := revoke("/sessions"); status != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", status)
+		}
+		for _, cookies := range others {
+			if status := profileStatus(cookies); status != http.StatusUnauthorized {
+				t.Errorf("Expected other session to be revoked, got %d", status)
+			}
+		}
+
+		sessions := listSessions()
+		if len(sessions) != 1 || !sessions[0].Current {
+			t.Errorf("Expected only the current session to remain, got %+v", sessions)
+		}
+	})
+}
+
+// ============================================================================
+// IDEMPOTENCY KEY TESTS
+// ============================================================================
+
+func TestIdempotencyKeys(t *testing.T) {
+	suffix := uuid.New().String()[:8]
+	email := fmt.Sprintf("idem_%s@example.com", suffix)
+	registerReq := map[string]string{
+		"username": "idem_" + suffix,
+		"email":    email,
+		"password": "password123",
+	}
+
+	resp, err := makeRequest(http.MethodPost, authPath+"/register", registerReq, nil)
+	if err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+	resp.Body.Close()
+	cookies := resp.Cookies()
+
+	wallet := createWallet(t, cookies, "Idempotent Wallet")
+	depositURL := fmt.Sprintf("%s/%s/deposit", walletPath, wallet.ID)
+
+	deposit := func(key string, amount string) (int, string) {
+		headers := map[string]string{"Idempotency-Key": key}
+		resp, err := makeRequestWithHeaders(http.MethodPost, depositURL, WalletTransactionRequest{Amount: amount}, cookies, headers)
+		if err != nil {
+			t.Fatalf("Failed to deposit: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode, resp.Header.Get("Idempotent-Replayed")
+	}
+
+	balance := func(walletID string) string {
+		resp, err := makeRequest(http.MethodGet, walletPath+"/"+walletID, nil, cookies)
+		if err != nil {
+			t.Fatalf("Failed to get wallet: %v", err)
+		}
+		var w WalletResponse
+		decodeData(t, resp, &w)
+		return w.Balance
+	}
+
+	t.Run("Retry Is Replayed", func(t *testing.T) {
+		key := uuid.New().String()
+		if status, _ := deposit(key, "1000"); status != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", status)
+		}
+
+		status, replayed := deposit(key, "1000")
+		if status != http.StatusOK {
+			t.Errorf("Expected replayed status 200, got %d", status)
+		}
+		if replayed != "true" {
+			t.Errorf("Expected Idempotent-Replayed header, got %q", replayed)
+		}
+		if got := balance(wallet.ID); got != "1000" {
+			t.Errorf("Expected balance 1000, got %s", got)
+		}
+	})
+
+	t.Run("Same Key Different Body", func(t *testing.T) {
+		key := uuid.New().String()
+		deposit(key, "500")
+
+		if status, _ := deposit(key, "600"); status != http.StatusUnprocessableEntity {
+			t.Errorf("Expected status 422, got %d", status)
+		}
+	})
+
+	t.Run("Concurrent Duplicates Deposit Once", func(t *testing.T) {
+		before, _ := decimal.NewFromString(balance(wallet.ID))
+		key := uuid.New().String()
+
+		var wg sync.WaitGroup
+		statuses := make([]int, 5)
+		for i := range statuses {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				statuses[i], _ = deposit(key, "250")
+			}(i)
+		}
+		wg.Wait()
+
+		for _, status := range statuses {
+			if status != http.StatusOK {
+				t.Errorf("Expected every duplicate to get 200, got %v", statuses)
+				break
+			}
+		}
+
+		after, _ := decimal.NewFromString(balance(wallet.ID))
+		if !after.Sub(before).Equal(decimal.NewFromInt(250)) {
+			t.Errorf("Expected balance to grow by 250, grew by %s", after.Sub(before))
+		}
+	})
+
+	t.Run("Transfer Retry Is Replayed", func(t *testing.T) {
+		verifyEmail(t, email)
+
+		target := createWallet(t, cookies, "Idempotent Target")
+		key := uuid.New().String()
+		body := map[string]string{"to_wallet_id": target.ID, "amount": "100"}
+		url := fmt.Sprintf("%s/%s/transfer", walletPath, wallet.ID)
+
+		for i := 0; i < 2; i++ {
+			resp, err := makeRequestWithHeaders(http.MethodPost, url, body, cookies, map[string]string{"Idempotency-Key": key})
+			if err != nil {
+				t.Fatalf("Failed to transfer: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+			}
+		}
+
+		if got := balance(target.ID); got != "100" {
+			t.Errorf("Expected target balance 100, got %s", got)
+		}
+	})
+}
+
+// ============================================================================
+// LEDGER TESTS
+// ============================================================================
+
+type WalletTransactionResponse struct {
+	ID             string `json:"id"`
+	WalletID       string `json:"wallet_id"`
+	ReferenceID    string `json:"reference_id"`
+	Type           string `json:"type"`
+	Amount         string `json:"amount"`
+	BalanceBefore  string `json:"balance_before"`
+	BalanceAfter   string `json:"balance_after"`
+	JournalEntryID string `json:"journal_entry_id"`
+}
+
+// walletTransactions lists the newest transactions of a wallet
+func walletTransactions(t *testing.T, cookies []*http.Cookie, walletID string) []WalletTransactionResponse {
+	t.Helper()
+
+	resp, err := makeRequest(http.MethodGet, fmt.Sprintf("%s/%s/transactions", walletPath, walletID), nil, cookies)
+	if err != nil {
+		t.Fatalf("Failed to get transactions: %v", err)
+	}
+
+	var transactions []WalletTransactionResponse
+	decodeData(t, resp, &transactions)
+	return transactions
+}
+
+func TestLedgerJournalEntries(t *testing.T) {
+	suffix := uuid.New().String()[:8]
+	email := fmt.Sprintf("ledger_%s@example.com", suffix)
+	registerReq := map[string]string{
+		"username": "ledger_" + suffix,
+		"email":    email,
+		"password": "password123",
+	}
+
+	resp, err := makeRequest(http.MethodPost, authPath+"/register", registerReq, nil)
+	if err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+	resp.Body.Close()
+	cookies := resp.Cookies()
+
+	source := createWallet(t, cookies, "Ledger Source")
+	target := createWallet(t, cookies, "Ledger Target")
+
+	resp, err = makeRequest(http.MethodPost, fmt.Sprintf("%s/%s/deposit", walletPath, source.ID), WalletTransactionRequest{Amount: "1000"}, cookies)
+	if err != nil {
+		t.Fatalf("Failed to deposit: %v", err)
+	}
+	resp.Body.Close()
+
+	t.Run("Deposit Has Journal Entry", func(t *testing.T) {
+		transactions := walletTransactions(t, cookies, source.ID)
+		if len(transactions) != 1 {
+			t.Fatalf("Expected 1 transaction, got %d", len(transactions))
+		}
+		if transactions[0].JournalEntryID == "" {
+			t.Error("Expected deposit to reference a journal entry")
+		}
+	})
+
+	t.Run("Transfer Legs Share One Entry", func(t *testing.T) {
+		verifyEmail(t, email)
+
+		body := WalletTransferRequest{ToWalletID: target.ID, Amount: "400"}
+		resp, err := makeRequest(http.MethodPost, fmt.Sprintf("%s/%s/transfer", walletPath, source.ID), body, cookies)
+		if err != nil {
+			t.Fatalf("Failed to transfer: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+
+		outgoing := walletTransactions(t, cookies, source.ID)[0]
+		incoming := walletTransactions(t, cookies, target.ID)[0]
+		if outgoing.JournalEntryID == "" || outgoing.JournalEntryID != incoming.JournalEntryID {
+			t.Errorf("Expected both legs on one journal entry, got %q and %q", outgoing.JournalEntryID, incoming.JournalEntryID)
+		}
+		if outgoing.ReferenceID != incoming.ReferenceID {
+			t.Errorf("Expected both legs to share reference %q, got %q", outgoing.ReferenceID, incoming.ReferenceID)
+		}
+	})
+}
+
+type HoldResponse struct {
+	ID                 string `json:"id"`
+	Amount             string `json:"amount"`
+	CapturedAmount     string `json:"captured_amount"`
+	Status             string `json:"status"`
+	CaptureReferenceID string `json:"capture_reference_id"`
+}
+
+func TestFundHolds(t *testing.T) {
+	suffix := uuid.New().String()[:8]
+	email := fmt.Sprintf("holds_%s@example.com", suffix)
+	registerReq := map[string]string{
+		"username": "holds_" + suffix,
+		"email":    email,
+		"password": "password123",
+	}
+
+	resp, err := makeRequest(http.MethodPost, authPath+"/register", registerReq, nil)
+	if err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+	resp.Body.Close()
+	cookies := resp.Cookies()
+
+	payer := createWallet(t, cookies, "Holds Payer")
+	merchant := createWallet(t, cookies, "Holds Merchant")
+
+	resp, err = makeRequest(http.MethodPost, fmt.Sprintf("%s/%s/deposit", walletPath, payer.ID), WalletTransactionRequest{Amount: "1000"}, cookies)
+	if err != nil {
+		t.Fatalf("Failed to deposit: %v", err)
+	}
+	resp.Body.Close()
+
+	getWallet := func(walletID string) WalletResponse {
+		resp, err := makeRequest(http.MethodGet, walletPath+"/"+walletID, nil, cookies)
+		if err != nil {
+			t.Fatalf("Failed to get wallet: %v", err)
+		}
+		var wallet WalletResponse
+		decodeData(t, resp, &wallet)
+		return wallet
+	}
+
+	createHold := func(amount string) HoldResponse {
+		resp, err := makeRequest(http.MethodPost, fmt.Sprintf("%s/%s/holds", walletPath, payer.ID), map[string]string{"amount": amount}, cookies)
+		if err != nil {
+			t.Fatalf("Failed to create hold: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		var hold HoldResponse
+		decodeData(t, resp, &hold)
+		return hold
+	}
+
+	t.Run("Hold Lifecycle", func(t *testing.T) {
+		verifyEmail(t, email)
+
+		hold := createHold("600")
+		wallet := getWallet(payer.ID)
+		if wallet.Balance != "1000" || wallet.AvailableBalance != "400" {
+			t.Fatalf("Expected balance 1000 and available 400, got %s and %s", wallet.Balance, wallet.AvailableBalance)
+		}
+
+		// Held money cannot be withdrawn
+		resp, err := makeRequest(http.MethodPost, fmt.Sprintf("%s/%s/withdraw", walletPath, payer.ID), WalletTransactionRequest{Amount: "500"}, cookies)
+		if err != nil {
+			t.Fatalf("Failed to withdraw: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400 withdrawing held money, got %d", resp.StatusCode)
+		}
+
+		// Partial capture into a transfer releases the rest
+		capture := map[string]string{"amount": "250", "to_wallet_id": merchant.ID}
+		resp, err = makeRequest(http.MethodPost, fmt.Sprintf("%s/%s/holds/%s/capture", walletPath, payer.ID, hold.ID), capture, cookies)
+		if err != nil {
+			t.Fatalf("Failed to capture hold: %v", err)
+		}
+		var captured HoldResponse
+		decodeData(t, resp, &captured)
+		if captured.Status != "captured" || captured.CapturedAmount != "250" || captured.CaptureReferenceID == "" {
+			t.Errorf("Expected hold captured for 250, got %+v", captured)
+		}
+
+		wallet = getWallet(payer.ID)
+		if wallet.Balance != "750" || wallet.AvailableBalance != "750" {
+			t.Errorf("Expected balance and available 750, got %s and %s", wallet.Balance, wallet.AvailableBalance)
+		}
+		if got := getWallet(merchant.ID).Balance; got != "250" {
+			t.Errorf("Expected merchant balance 250, got %s", got)
+		}
+
+		resp, err = makeRequest(http.MethodPost, fmt.Sprintf("%s/%s/holds/%s/capture", walletPath, payer.ID, hold.ID), map[string]string{}, cookies)
+		if err != nil {
+			t.Fatalf("Failed to capture hold: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusConflict {
+			t.Errorf("Expected status 409 capturing twice, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Void Releases Hold", func(t *testing.T) {
+		verifyEmail(t, email)
+
+		hold := createHold("100")
+		resp, err := makeRequest(http.MethodPost, fmt.Sprintf("%s/%s/holds/%s/void", walletPath, payer.ID, hold.ID), nil, cookies)
+		if err != nil {
+			t.Fatalf("Failed to void hold: %v", err)
+		}
+		var voided HoldResponse
+		decodeData(t, resp, &voided)
+		if voided.Status != "voided" {
+			t.Errorf("Expected hold voided, got %s", voided.Status)
+		}
+
+		wallet := getWallet(payer.ID)
+		if wallet.AvailableBalance != wallet.Balance {
+			t.Errorf("Expected available balance %s after void, got %s", wallet.Balance, wallet.AvailableBalance)
+		}
+	})
+}
+
+type ScheduleResponse struct {
+	ID        string  `json:"id"`
+	Frequency string  `json:"frequency"`
+	RunCount  int     `json:"run_count"`
+	NextRunAt *string `json:"next_run_at"`
+	Status    string  `json:"status"`
+}
+
+func TestTransferSchedules(t *testing.T) {
+	suffix := uuid.New().String()[:8]
+	email := fmt.Sprintf("schedules_%s@example.com", suffix)
+	registerReq := map[string]string{
+		"username": "schedules_" + suffix,
+		"email":    email,
+		"password": "password123",
+	}
+
+	resp, err := makeRequest(http.MethodPost, authPath+"/register", registerReq, nil)
+	if err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+	resp.Body.Close()
+	cookies := resp.Cookies()
+
+	verifyEmail(t, email)
+
+	checking := createWallet(t, cookies, "Schedule Checking")
+	savings := createWallet(t, cookies, "Schedule Savings")
+
+	createReq := map[string]interface{}{
+		"from_wallet_id": checking.ID,
+		"to_wallet_id":   savings.ID,
+		"amount":         "100",
+		"frequency":      "weekly",
+		"start_at":       time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339),
+	}
+
+	t.Run("Start In The Past", func(t *testing.T) {
+		pastReq := map[string]interface{}{}
+		for k, v := range createReq {
+			pastReq[k] = v
+		}
+		pastReq["start_at"] = time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+
+		resp, err := makeRequest(http.MethodPost, schedulePath, pastReq, cookies)
+		if err != nil {
+			t.Fatalf("Failed to create schedule: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for a start in the past, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Schedule Lifecycle", func(t *testing.T) {
+		resp, err := makeRequest(http.MethodPost, schedulePath, createReq, cookies)
+		if err != nil {
+			t.Fatalf("Failed to create schedule: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		var schedule ScheduleResponse
+		decodeData(t, resp, &schedule)
+		if schedule.Status != "active" || schedule.NextRunAt == nil || schedule.RunCount != 0 {
+			t.Fatalf("Expected an active schedule waiting for its first run, got %+v", schedule)
+		}
+
+		resp, err = makeRequest(http.MethodPatch, schedulePath+"/"+schedule.ID, map[string]string{"status": "paused"}, cookies)
+		if err != nil {
+			t.Fatalf("Failed to pause schedule: %v", err)
+		}
+		var paused ScheduleResponse
+		decodeData(t, resp, &paused)
+		if paused.Status != "paused" {
+			t.Errorf("Expected status paused, got %s", paused.Status)
+		}
+
+		resp, err = makeRequest(http.MethodDelete, schedulePath+"/"+schedule.ID, nil, cookies)
+		if err != nil {
+			t.Fatalf("Failed to cancel schedule: %v", err)
+		}
+		var cancelled ScheduleResponse
+		decodeData(t, resp, &cancelled)
+		if cancelled.Status != "cancelled" || cancelled.NextRunAt != nil {
+			t.Errorf("Expected a cancelled schedule without next run, got %+v", cancelled)
+		}
+
+		// A cancelled schedule cannot be resumed
+		resp, err = makeRequest(http.MethodPatch, schedulePath+"/"+schedule.ID, map[string]string{"status": "active"}, cookies)
+		if err != nil {
+			t.Fatalf("Failed to resume schedule: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusConflict {
+			t.Errorf("Expected status 409 resuming a cancelled schedule, got %d", resp.StatusCode)
+		}
+
+		resp, err = makeRequest(http.MethodGet, schedulePath+"/"+schedule.ID+"/runs", nil, cookies)
+		if err != nil {
+			t.Fatalf("Failed to get runs: %v", err)
+		}
+		var runs []map[string]interface{}
+		decodeData(t, resp, &runs)
+		if len(runs) != 0 {
+			t.Errorf("Expected no runs before the start date, got %d", len(runs))
+		}
+	})
+}
+
+// ============================================================================
+// CONCURRENCY TESTS
+// ============================================================================
+
+func TestConcurrentOppositeTransfers(t *testing.T) {
+	suffix := uuid.New().String()[:8]
+	email := fmt.Sprintf("hammer_%s@example.com", suffix)
+	registerReq := map[string]string{
+		"username": "hammer_" + suffix,
+		"email":    email,
+		"password": "password123",
+	}
+
+	resp, err := makeRequest(http.MethodPost, authPath+"/register", registerReq, nil)
+	if err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+	resp.Body.Close()
+	cookies := resp.Cookies()
+
+	verifyEmail(t, email)
+
+	walletA := createWallet(t, cookies, "Hammer A")
+	walletB := createWallet(t, cookies, "Hammer B")
+	for _, wallet := range []WalletResponse{walletA, walletB} {
+		resp, err := makeRequest(http.MethodPost, fmt.Sprintf("%s/%s/deposit", walletPath, wallet.ID), WalletTransactionRequest{Amount: "10000"}, cookies)
+		if err != nil {
+			t.Fatalf("Failed to deposit: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	const rounds = 40
+	var wg sync.WaitGroup
+	statuses := make([]int, rounds)
+	for i := 0; i < rounds; i++ {
+		from, to := walletA, walletB
+		if i%2 == 1 {
+			from, to = walletB, walletA
+		}
+
+		wg.Add(1)
+		go func(i int, from, to WalletResponse) {
+			defer wg.Done()
+
+			body := WalletTransferRequest{ToWalletID: to.ID, Amount: "10"}
+			resp, err := makeRequest(http.MethodPost, fmt.Sprintf("%s/%s/transfer", walletPath, from.ID), body, cookies)
+			if err != nil {
+				t.Errorf("Failed to transfer: %v", err)
+				return
+			}
+			resp.Body.Close()
+			statuses[i] = resp.StatusCode
+		}(i, from, to)
+	}
+	wg.Wait()
+
+	for i, status := range statuses {
+		if status != http.StatusOK {
+			t.Errorf("Transfer %d: expected status 200, got %d", i, status)
+		}
+	}
+
+	balance := func(walletID string) decimal.Decimal {
+		resp, err := makeRequest(http.MethodGet, walletPath+"/"+walletID, nil, cookies)
+		if err != nil {
+			t.Fatalf("Failed to get wallet: %v", err)
+		}
+		var wallet WalletResponse
+		decodeData(t, resp, &wallet)
+		amount, _ := decimal.NewFromString(wallet.Balance)
+		return amount
+	}
+
+	// Same number of transfers each way, so both wallets end where they started
+	for _, wallet := range []WalletResponse{walletA, walletB} {
+		if got := balance(wallet.ID); !got.Equal(decimal.NewFromInt(10000)) {
+			t.Errorf("Wallet %s: expected balance 10000, got %s", wallet.WalletName, got)
+		}
+	}
+}
+
+func TestFXQuotes(t *testing.T) {
+	cookies := registerWalletUser(t, "fx")
+	wallet := createWallet(t, cookies, "FX Wallet")
+
+	quoteCases := []struct {
+		name       string
+		body       map[string]string
+		wantStatus int
+	}{
+		// XTS and XXX are the ISO 4217 codes reserved for testing, they are not in the registry
+		{"Unsupported Currency", map[string]string{"source_currency": "XTS", "destination_currency": "XXX", "amount": "10"}, http.StatusBadRequest},
+		{"No Rate For Pair", map[string]string{"source_currency": "BHD", "destination_currency": "OMR", "amount": "10"}, http.StatusNotFound},
+		{"Too Many Decimals", map[string]string{"source_currency": "BHD", "destination_currency": "OMR", "amount": "10.0001"}, http.StatusBadRequest},
+		{"Invalid Currency", map[string]string{"source_currency": "usdx", "destination_currency": "IDR", "amount": "10"}, http.StatusBadRequest},
+		{"Same Currency", map[string]string{"source_currency": "IDR", "destination_currency": "IDR", "amount": "10"}, http.StatusBadRequest},
+	}
+
+	for _, tc := range quoteCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := makeRequest(http.MethodPost, basePathV1+"/fx/quotes", tc.body, cookies)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tc.wantStatus {
+				t.Errorf("Expected status %d, got %d", tc.wantStatus, resp.StatusCode)
+			}
+		})
+	}
+
+	t.Run("Unknown Quote", func(t *testing.T) {
+		resp, err := makeRequest(http.MethodGet, basePathV1+"/fx/quotes/"+uuid.New().String(), nil, cookies)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Transfer With Unknown Quote", func(t *testing.T) {
+		transferReq := map[string]string{
+			"quote_id":       uuid.New().String(),
+			"from_wallet_id": wallet.ID,
+			"to_wallet_id":   wallet.ID,
+		}
+		resp, err := makeRequest(http.MethodPost, basePathV1+"/fx/transfers", transferReq, cookies)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", resp.StatusCode)
+		}
+	})
+}
+
+func TestCurrencyPrecision(t *testing.T) {
+	cookies := registerWalletUser(t, "precision")
+
+	t.Run("Registry Is Public", func(t *testing.T) {
+		resp, err := makeRequest(http.MethodGet, basePathV1+"/currencies", nil, nil)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+
+		var currencies []struct {
+			Code     string `json:"code"`
+			Exponent int    `json:"exponent"`
+		}
+		decodeData(t, resp, &currencies)
+
+		exponents := map[string]int{}
+		for _, currency := range currencies {
+			exponents[currency.Code] = currency.Exponent
+		}
+		for code, want := range map[string]int{"IDR": 2, "JPY": 0, "KWD": 3} {
+			if got, ok := exponents[code]; !ok || got != want {
+				t.Errorf("%s exponent = %d (listed %v), want %d", code, got, ok, want)
+			}
+		}
+	})
+
+	createIn := func(currency string) (int, WalletResponse) {
+		resp, err := makeRequest(http.MethodPost, walletPath, CreateWalletRequest{WalletName: currency + " Wallet", Currency: currency}, cookies)
+		if err != nil {
+			t.Fatalf("Failed to create wallet: %v", err)
+		}
+		var wallet WalletResponse
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return resp.StatusCode, wallet
+		}
+		decodeData(t, resp, &wallet)
+		return http.StatusOK, wallet
+	}
+
+	t.Run("Unknown Currency", func(t *testing.T) {
+		if status, _ := createIn("XTS"); status != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", status)
+		}
+	})
+
+	deposits := []struct {
+		currency   string
+		amount     string
+		wantStatus int
+	}{
+		{"JPY", "500", http.StatusOK},
+		{"JPY", "500.5", http.StatusBadRequest},
+		{"IDR", "10.25", http.StatusOK},
+		{"IDR", "10.255", http.StatusBadRequest},
+		{"KWD", "1.125", http.StatusOK},
+		{"KWD", "1.1255", http.StatusBadRequest},
+	}
+
+	wallets := map[string]WalletResponse{}
+	for _, tc := range deposits {
+		t.Run("Deposit "+tc.amount+" "+tc.currency, func(t *testing.T) {
+			wallet, ok := wallets[tc.currency]
+			if !ok {
+				status, created := createIn(tc.currency)
+				if status != http.StatusOK {
+					t.Fatalf("Failed to create %s wallet, status %d", tc.currency, status)
+				}
+				wallet, wallets[tc.currency] = created, created
+			}
+
+			url := fmt.Sprintf("%s/%s/deposit", walletPath, wallet.ID)
+			resp, err := makeRequest(http.MethodPost, url, WalletTransactionRequest{Amount: tc.amount}, cookies)
+			if err != nil {
+				t.Fatalf("Failed to deposit: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tc.wantStatus {
+				t.Errorf("Expected status %d, got %d", tc.wantStatus, resp.StatusCode)
+			}
+		})
+	}
+}
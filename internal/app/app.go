@@ -5,11 +5,14 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"wallet_api/config"
 	"wallet_api/internal/router"
+	"wallet_api/internal/utils"
 	"wallet_api/pkg/httpserver"
 	"wallet_api/pkg/logger"
+	"wallet_api/pkg/mailer"
 	"wallet_api/pkg/postgres"
 )
 
@@ -23,13 +26,36 @@ func Run(cfg *config.Config) {
 	}
 	defer pg.Close()
 
+	// JWT signing keys, refuse to start without one
+	keys, err := utils.LoadKeySet(cfg.JWT.KeysDir, cfg.JWT.ActiveKeyID)
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - utils.LoadKeySet: %w", err))
+	}
+	jwtManager := utils.NewJWTManager(
+		keys,
+		time.Duration(cfg.JWT.AccessTokenExpiry)*time.Minute,
+		time.Duration(cfg.JWT.RefreshTokenExpiry)*24*time.Hour,
+	)
+
+	// Mail delivery
+	mail, err := newMailer(cfg, l)
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - newMailer: %w", err))
+	}
+
 	// Initialize Router Modules (auto-injects all module dependencies)
-	routerModule := router.NewModule(pg.DB, l)
+	routerModule := router.NewModule(pg.DB, l, cfg, jwtManager, mail)
 
 	// HTTP Server
 	httpServer := httpserver.New(l, httpserver.Port(cfg.HTTP.Port), httpserver.Prefork(cfg.HTTP.UsePreforkMode))
 	router.NewRouter(httpServer.App, cfg, routerModule, l)
 
+	// Transfer schedule worker, stopped before the database pool is closed
+	if cfg.Schedule.WorkerEnabled {
+		routerModule.Schedule.Worker.Start()
+		defer routerModule.Schedule.Worker.Stop()
+	}
+
 	// Start server
 	httpServer.Start()
 
@@ -50,3 +76,21 @@ func Run(cfg *config.Config) {
 		l.Error(fmt.Errorf("app - Run - httpServer.Shutdown: %w", err))
 	}
 }
+
+// newMailer picks the mail driver from config
+func newMailer(cfg *config.Config, l logger.Interface) (mailer.Interface, error) {
+	switch cfg.Mail.Driver {
+	case "smtp":
+		if cfg.Mail.SMTPHost == "" {
+			return nil, fmt.Errorf("MAIL_SMTP_HOST is required for the smtp driver")
+		}
+		return mailer.NewSMTP(cfg.Mail.SMTPHost, cfg.Mail.SMTPPort, cfg.Mail.From,
+			mailer.Auth(cfg.Mail.SMTPUsername, cfg.Mail.SMTPPassword)), nil
+	case "file":
+		return mailer.NewFile(cfg.Mail.FileDir, cfg.Mail.From)
+	case "log":
+		return mailer.NewLog(l), nil
+	default:
+		return nil, fmt.Errorf("unknown MAIL_DRIVER %q", cfg.Mail.Driver)
+	}
+}
@@ -283,15 +283,14 @@ func (qb *QueryBuilder[T]) Count(ctx context.Context) (int64, error) {
 }
 
 
+// WithTransaction retries fn on deadlocks and serialization failures, see RunInTransaction
 func (r *BaseRepository[T]) WithTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
-	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		return fn(tx)
-	})
+	return RunInTransaction(ctx, r.db, fn)
 }
 
 func Transaction[T any](ctx context.Context, db *gorm.DB, fn func(repo Repository[T]) error) error {
 	repo := NewBaseRepository[T](db)
-	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	return RunInTransaction(ctx, db, func(tx *gorm.DB) error {
 		return fn(repo.WithTx(tx))
 	})
 }
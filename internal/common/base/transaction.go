@@ -0,0 +1,77 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// Postgres aborts one side of a deadlock or a serialization conflict, running it again usually succeeds
+	sqlStateDeadlockDetected     = "40P01"
+	sqlStateSerializationFailure = "40001"
+	sqlStateUniqueViolation      = "23505"
+
+	txMaxAttempts = 5
+	txBaseBackoff = 20 * time.Millisecond
+	txMaxBackoff  = 500 * time.Millisecond
+)
+
+// RunInTransaction runs fn in a transaction and runs it again, with a short jittered backoff,
+// when Postgres aborted it with a deadlock or serialization failure. fn must not keep state
+// between attempts.
+func RunInTransaction(ctx context.Context, db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	return retryTransaction(ctx, func() error {
+		return db.WithContext(ctx).Transaction(fn)
+	})
+}
+
+func retryTransaction(ctx context.Context, attempt func() error) error {
+	backoff := txBaseBackoff
+	for i := 1; ; i++ {
+		err := attempt()
+		if err == nil || !IsRetryableTxError(err) || i == txMaxAttempts {
+			return err
+		}
+
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff)))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+
+		backoff *= 2
+		if backoff > txMaxBackoff {
+			backoff = txMaxBackoff
+		}
+	}
+}
+
+// IsRetryableTxError reports a deadlock or serialization failure. It checks the SQLSTATE
+// through an interface so the base package does not depend on the driver.
+func IsRetryableTxError(err error) bool {
+	var pgErr interface{ SQLState() string }
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+
+	code := pgErr.SQLState()
+	return code == sqlStateDeadlockDetected || code == sqlStateSerializationFailure
+}
+
+// IsUniqueViolation reports an insert that hit a unique constraint
+func IsUniqueViolation(err error) bool {
+	var pgErr interface{ SQLState() string }
+	return errors.As(err, &pgErr) && pgErr.SQLState() == sqlStateUniqueViolation
+}
+
+// IsUniqueViolationOf reports a unique violation of the named constraint. Postgres quotes the
+// constraint in the message, so this works without depending on the driver's error type.
+func IsUniqueViolationOf(err error, constraint string) bool {
+	return IsUniqueViolation(err) && strings.Contains(err.Error(), `"`+constraint+`"`)
+}
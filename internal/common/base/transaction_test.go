@@ -0,0 +1,82 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "sqlstate " + string(e) }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+// uniqueViolation carries the constraint in the message like the Postgres driver does
+type uniqueViolation string
+
+func (e uniqueViolation) Error() string {
+	return `ERROR: duplicate key value violates unique constraint "` + string(e) + `" (SQLSTATE 23505)`
+}
+func (e uniqueViolation) SQLState() string { return "23505" }
+
+func TestRetryTransaction(t *testing.T) {
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   bool
+	}{
+		{name: "success", errs: []error{nil}, wantCalls: 1},
+		{name: "deadlock then success", errs: []error{sqlStateError("40P01"), nil}, wantCalls: 2},
+		{name: "wrapped serialization failure", errs: []error{fmt.Errorf("update: %w", sqlStateError("40001")), nil}, wantCalls: 2},
+		{name: "other sqlstate is not retried", errs: []error{sqlStateError("23505")}, wantCalls: 1, wantErr: true},
+		{name: "plain error is not retried", errs: []error{errors.New("boom")}, wantCalls: 1, wantErr: true},
+		{
+			name:      "gives up after max attempts",
+			errs:      []error{sqlStateError("40P01"), sqlStateError("40P01"), sqlStateError("40P01"), sqlStateError("40P01"), sqlStateError("40P01"), nil},
+			wantCalls: txMaxAttempts,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := retryTransaction(context.Background(), func() error {
+				err := tt.errs[calls]
+				calls++
+				return err
+			})
+
+			if calls != tt.wantCalls {
+				t.Errorf("attempts = %d, want %d", calls, tt.wantCalls)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsUniqueViolation(t *testing.T) {
+	if !IsUniqueViolation(fmt.Errorf("insert: %w", sqlStateError("23505"))) {
+		t.Error("IsUniqueViolation(23505) = false, want true")
+	}
+	if IsUniqueViolation(sqlStateError("40P01")) || IsUniqueViolation(errors.New("boom")) {
+		t.Error("IsUniqueViolation() = true for another error")
+	}
+}
+
+func TestIsUniqueViolationOf(t *testing.T) {
+	err := fmt.Errorf("insert: %w", uniqueViolation("idx_transactions_wallet_reference"))
+	if !IsUniqueViolationOf(err, "idx_transactions_wallet_reference") {
+		t.Error("IsUniqueViolationOf() = false for the named constraint")
+	}
+	if IsUniqueViolationOf(err, "idx_transactions_wallet") || IsUniqueViolationOf(err, "idx_holds_wallet_reference") {
+		t.Error("IsUniqueViolationOf() = true for another constraint")
+	}
+	if IsUniqueViolationOf(errors.New(`"idx_transactions_wallet_reference"`), "idx_transactions_wallet_reference") {
+		t.Error("IsUniqueViolationOf() = true for an error without a sqlstate")
+	}
+}
@@ -11,8 +11,118 @@ const (
 	WalletStatusFrozen   = "frozen"
 )
 
+// DefaultCurrency is used for wallets created without a currency, same as the column default
+const DefaultCurrency = "IDR"
+
 const (
 	TransactionTypeDeposit    = "deposit"
 	TransactionTypeWithdrawal = "withdrawal"
 	TransactionTypeTransfer   = "transfer"
+	// Written by reconciliation to bring history and ledger in line with the stored balance
+	TransactionTypeAdjustment = "adjustment"
+	// Compensating entry written by an admin reversal, linked to the original row
+	TransactionTypeReversal = "reversal"
+	// Transfer between wallets of different currencies at a quoted rate, cannot be reversed
+	TransactionTypeFXTransfer = "fx_transfer"
+)
+
+const (
+	// What a reversal does when the wallet it takes money back from cannot cover it
+	ReversalPolicyFail     = "fail"
+	ReversalPolicyNegative = "negative"
+	// Take what the wallet has and place a hold for the shortfall
+	ReversalPolicyHold = "hold"
+)
+
+const (
+	LedgerAccountKindWallet = "wallet"
+	LedgerAccountKindSystem = "system"
+)
+
+const (
+	// System ledger accounts, one of each per currency
+	LedgerAccountCashIn   = "cash_in"
+	LedgerAccountCashOut  = "cash_out"
+	LedgerAccountFees     = "fees"
+	LedgerAccountSuspense = "suspense"
+	// FX position, takes in the source currency and pays out the destination currency of a conversion
+	LedgerAccountFX = "fx"
+)
+
+const (
+	PostingDirectionDebit  = "debit"
+	PostingDirectionCredit = "credit"
+)
+
+const (
+	// A hold reserves money until it is captured, voided or passes its expiry
+	HoldStatusActive   = "active"
+	HoldStatusCaptured = "captured"
+	HoldStatusVoided   = "voided"
+	// Never stored, an active hold past expires_at is reported as expired
+	HoldStatusExpired = "expired"
+)
+
+const (
+	// How often a transfer schedule repeats, once runs a single time at its start
+	ScheduleFrequencyOnce    = "once"
+	ScheduleFrequencyDaily   = "daily"
+	ScheduleFrequencyWeekly  = "weekly"
+	ScheduleFrequencyMonthly = "monthly"
+)
+
+const (
+	ScheduleStatusActive = "active"
+	ScheduleStatusPaused = "paused"
+	// No occurrence left before the end date
+	ScheduleStatusCompleted = "completed"
+	ScheduleStatusCancelled = "cancelled"
+)
+
+const (
+	ScheduleRunSucceeded = "succeeded"
+	ScheduleRunFailed    = "failed"
+)
+
+const (
+	// A claimed Idempotency-Key moves from processing to completed once its response is stored
+	IdempotencyStatusProcessing = "processing"
+	IdempotencyStatusCompleted  = "completed"
+)
+
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+	// Short-lived token handed out after the password step of a 2FA login
+	TokenTypeTwoFactor = "two_factor"
+	// Short-lived token proving a recent PIN check, for large withdrawals and transfers
+	TokenTypeStepUp = "step_up"
+)
+
+const (
+	SecurityEventRefreshTokenReuse = "refresh_token_reuse"
+	SecurityEventPasswordReset     = "password_reset"
+	SecurityEventEmailVerified     = "email_verified"
+	SecurityEventPasswordChanged   = "password_changed"
+	SecurityEventPINChanged        = "pin_changed"
+	SecurityEventPINLocked         = "pin_locked"
+)
+
+const (
+	// Where login and refresh hand the token pair back to the client
+	TokenDeliveryCookie = "cookie"
+	TokenDeliveryBody   = "body"
+)
+
+const (
+	// X-Client-Type values that receive tokens in the response body by default
+	ClientTypeMobile  = "mobile"
+	ClientTypeService = "service"
+)
+
+const (
+	// Scopes an API key can be granted
+	ScopeWalletsRead     = "wallets:read"
+	ScopeWalletsWrite    = "wallets:write"
+	ScopeTransfersCreate = "transfers:create"
 )
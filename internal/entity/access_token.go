@@ -11,9 +11,11 @@ type AccessToken struct {
 	SessionID uuid.UUID      `json:"session_id" gorm:"type:uuid;not null;index"`
 	Session   Session        `json:"session,omitempty" gorm:"foreignKey:SessionID"`
 	TokenHash string         `json:"-" gorm:"uniqueIndex;not null;size:500"`
+	TokenType string         `json:"token_type" gorm:"not null;size:20;default:'access'"`
 	UserID    uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;index"`
 	User      User           `json:"user,omitempty" gorm:"foreignKey:UserID"`
 	ExpiredAt time.Time      `json:"expired_at" gorm:"not null"`
+	UsedAt    *time.Time     `json:"used_at"`
 	CreatedAt time.Time      `json:"created_at"`
 }
 
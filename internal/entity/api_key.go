@@ -0,0 +1,39 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type APIKey struct {
+	ID         uuid.UUID   `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	UserID     uuid.UUID   `json:"user_id" gorm:"type:uuid;not null;index"`
+	User       User        `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Name       string      `json:"name" gorm:"not null;size:100"`
+	Prefix     string      `json:"prefix" gorm:"not null;size:16"`
+	KeyHash    string      `json:"-" gorm:"uniqueIndex;not null;size:255"`
+	Scopes     StringArray `json:"scopes" gorm:"type:text[];not null"`
+	WalletIDs  StringArray `json:"wallet_ids" gorm:"type:text[];not null"` // Kosong = semua wallet milik user
+	ExpiresAt  time.Time   `json:"expires_at" gorm:"not null"`
+	LastUsedAt *time.Time  `json:"last_used_at"`
+	RevokedAt  *time.Time  `json:"revoked_at"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+func (k *APIKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && now.Before(k.ExpiresAt)
+}
+
+func (k *APIKey) HasScope(scope string) bool {
+	return k.Scopes.Contains(scope)
+}
+
+// AllowsWallet reports whether the key may act on the wallet
+func (k *APIKey) AllowsWallet(walletID uuid.UUID) bool {
+	return len(k.WalletIDs) == 0 || k.WalletIDs.Contains(walletID.String())
+}
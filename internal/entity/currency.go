@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Currency is an ISO 4217 currency in the registry. Exponent is the number of minor unit
+// digits: 2 for IDR and USD, 0 for JPY and KRW, 3 for KWD and BHD. The money columns have
+// scale 4, the largest exponent in ISO 4217.
+type Currency struct {
+	Code     string `json:"code" gorm:"primary_key;size:10"`
+	Name     string `json:"name" gorm:"size:100;not null"`
+	Exponent int32  `json:"exponent" gorm:"not null"`
+	// Disabled currencies take no new wallets and no incoming money, existing balances can still leave
+	Enabled   bool      `json:"enabled" gorm:"not null;default:true"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (Currency) TableName() string {
+	return "currencies"
+}
+
+// Fits reports whether amount has no more decimal places than the currency has minor units
+func (c *Currency) Fits(amount decimal.Decimal) bool {
+	return amount.Equal(amount.Truncate(c.Exponent))
+}
+
+// Truncate rounds amount down to the smallest unit of the currency
+func (c *Currency) Truncate(amount decimal.Decimal) decimal.Decimal {
+	return amount.Truncate(c.Exponent)
+}
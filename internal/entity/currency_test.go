@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestCurrencyFits(t *testing.T) {
+	tests := []struct {
+		exponent int32
+		amount   string
+		want     bool
+	}{
+		{exponent: 2, amount: "10.25", want: true},
+		{exponent: 2, amount: "10.250", want: true},
+		{exponent: 2, amount: "10.255", want: false},
+		{exponent: 0, amount: "500", want: true},
+		{exponent: 0, amount: "500.5", want: false},
+		{exponent: 3, amount: "1.125", want: true},
+		{exponent: 3, amount: "1.1255", want: false},
+	}
+
+	for _, tt := range tests {
+		currency := &Currency{Code: "XTS", Exponent: tt.exponent}
+		if got := currency.Fits(decimal.RequireFromString(tt.amount)); got != tt.want {
+			t.Errorf("Fits(%s) with exponent %d = %v, want %v", tt.amount, tt.exponent, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// FXRate is the mid rate of one currency pair for a validity period:
+// 1 BaseCurrency buys Rate QuoteCurrency before the spread
+type FXRate struct {
+	ID            uuid.UUID       `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	BaseCurrency  string          `json:"base_currency" gorm:"not null;size:10;index:idx_fx_rates_pair"`
+	QuoteCurrency string          `json:"quote_currency" gorm:"not null;size:10;index:idx_fx_rates_pair"`
+	Rate          decimal.Decimal `json:"rate" gorm:"type:numeric(20,10);not null"`
+	// Fraction taken off the rate for the customer, 0.005 = 0.5%
+	Spread    decimal.Decimal `json:"spread" gorm:"type:numeric(10,6);not null"`
+	ValidFrom time.Time       `json:"valid_from" gorm:"not null"`
+	// Nil = valid until a newer rate starts
+	ValidTo   *time.Time `json:"valid_to"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (FXRate) TableName() string {
+	return "fx_rates"
+}
+
+// CustomerRate is the rate after the spread
+func (r *FXRate) CustomerRate() decimal.Decimal {
+	return customerRate(r.Rate, r.Spread)
+}
+
+// FXQuote locks a rate for one conversion until ExpiresAt. It can be used once.
+type FXQuote struct {
+	ID                  uuid.UUID       `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	UserID              uuid.UUID       `json:"user_id" gorm:"type:uuid;not null;index"`
+	RateID              uuid.UUID       `json:"rate_id" gorm:"type:uuid;not null"`
+	SourceCurrency      string          `json:"source_currency" gorm:"not null;size:10"`
+	DestinationCurrency string          `json:"destination_currency" gorm:"not null;size:10"`
+	Rate                decimal.Decimal `json:"rate" gorm:"type:numeric(20,10);not null"`
+	Spread              decimal.Decimal `json:"spread" gorm:"type:numeric(10,6);not null"`
+	SourceAmount        decimal.Decimal `json:"source_amount" gorm:"type:numeric(22,4);not null"`
+	DestinationAmount   decimal.Decimal `json:"destination_amount" gorm:"type:numeric(22,4);not null"`
+	ExpiresAt           time.Time       `json:"expires_at" gorm:"not null"`
+	UsedAt              *time.Time      `json:"used_at"`
+	// Reference of the transfer that used the quote
+	ReferenceID *string   `json:"reference_id" gorm:"size:500"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (FXQuote) TableName() string {
+	return "fx_quotes"
+}
+
+// CustomerRate is the rate the destination amount was priced at
+func (q *FXQuote) CustomerRate() decimal.Decimal {
+	return customerRate(q.Rate, q.Spread)
+}
+
+func customerRate(rate, spread decimal.Decimal) decimal.Decimal {
+	return rate.Mul(decimal.NewFromInt(1).Sub(spread))
+}
@@ -0,0 +1,47 @@
+package entity
+
+import (
+	"time"
+
+	"wallet_api/internal/common/consts"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Hold reserves part of a wallet balance. It lowers the available balance but not the balance
+// itself, money only moves when the hold is captured into a withdrawal or transfer.
+type Hold struct {
+	ID             uuid.UUID       `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	WalletID       uuid.UUID       `json:"wallet_id" gorm:"type:uuid;not null;index;uniqueIndex:idx_holds_wallet_reference"`
+	ReferenceID    string          `json:"reference_id" gorm:"not null;size:500;uniqueIndex:idx_holds_wallet_reference"`
+	Amount         decimal.Decimal `json:"amount" gorm:"type:numeric(22,4);not null"`
+	CapturedAmount decimal.Decimal `json:"captured_amount" gorm:"type:numeric(22,4);not null;default:0"`
+	Currency       string          `json:"currency" gorm:"not null;size:10"`
+	Status         string          `json:"status" gorm:"not null;size:20;comment:active, captured, voided"`
+	Description    string          `json:"description" gorm:"type:text"`
+	// Reference of the withdrawal or transfer the hold was captured into
+	CaptureReferenceID *string `json:"capture_reference_id" gorm:"size:500"`
+	// Set on the hold a reversal places for what the wallet could not cover
+	ReversalOfID *uuid.UUID `json:"reversal_of_id" gorm:"type:uuid"`
+	ExpiresAt    time.Time  `json:"expires_at" gorm:"not null"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (Hold) TableName() string {
+	return "holds"
+}
+
+// Active reports whether the hold still reserves money
+func (h *Hold) Active(now time.Time) bool {
+	return h.Status == consts.HoldStatusActive && now.Before(h.ExpiresAt)
+}
+
+// EffectiveStatus is the stored status, except that an active hold past its expiry is expired
+func (h *Hold) EffectiveStatus(now time.Time) string {
+	if h.Status == consts.HoldStatusActive && !h.Active(now) {
+		return consts.HoldStatusExpired
+	}
+	return h.Status
+}
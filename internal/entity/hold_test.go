@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"wallet_api/internal/common/consts"
+)
+
+func TestHoldEffectiveStatus(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name       string
+		status     string
+		expiresAt  time.Time
+		wantStatus string
+		wantActive bool
+	}{
+		{name: "active", status: consts.HoldStatusActive, expiresAt: now.Add(time.Minute), wantStatus: consts.HoldStatusActive, wantActive: true},
+		{name: "active past expiry", status: consts.HoldStatusActive, expiresAt: now.Add(-time.Minute), wantStatus: consts.HoldStatusExpired},
+		{name: "captured stays captured", status: consts.HoldStatusCaptured, expiresAt: now.Add(-time.Minute), wantStatus: consts.HoldStatusCaptured},
+		{name: "voided", status: consts.HoldStatusVoided, expiresAt: now.Add(time.Minute), wantStatus: consts.HoldStatusVoided},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hold := &Hold{Status: tt.status, ExpiresAt: tt.expiresAt}
+			if got := hold.EffectiveStatus(now); got != tt.wantStatus {
+				t.Errorf("EffectiveStatus() = %s, want %s", got, tt.wantStatus)
+			}
+			if got := hold.Active(now); got != tt.wantActive {
+				t.Errorf("Active() = %v, want %v", got, tt.wantActive)
+			}
+		})
+	}
+}
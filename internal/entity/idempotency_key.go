@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"time"
+
+	"wallet_api/internal/common/consts"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKey remembers a money-moving request by its Idempotency-Key header so a retry
+// gets the first response back instead of running again
+type IdempotencyKey struct {
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	UserID         uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_idempotency_keys_user_key"`
+	Key            string    `json:"key" gorm:"not null;size:255;uniqueIndex:idx_idempotency_keys_user_key"`
+	RequestHash    string    `json:"-" gorm:"not null;size:64"`
+	Status         string    `json:"status" gorm:"not null;size:20;comment:processing, completed"`
+	ResponseStatus *int      `json:"response_status"`
+	ResponseBody   []byte    `json:"-"`
+	LockedUntil    time.Time `json:"locked_until" gorm:"not null"`
+	ExpiresAt      time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt      time.Time `json:"created_at"`
+	// Set when this request took the key over from one whose lock ran out. That run may
+	// have committed, so the claim must not be released.
+	TakenOver bool `json:"-" gorm:"-"`
+}
+
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
+
+func (k *IdempotencyKey) Completed() bool {
+	return k.Status == consts.IdempotencyStatusCompleted
+}
@@ -0,0 +1,75 @@
+package entity
+
+import (
+	"time"
+
+	"wallet_api/internal/common/consts"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// LedgerAccount is either a wallet or a system account (cash_in, cash_out, fees, suspense) of one currency
+type LedgerAccount struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	Kind      string     `json:"kind" gorm:"not null;size:20;comment:wallet, system"`
+	WalletID  *uuid.UUID `json:"wallet_id" gorm:"type:uuid;uniqueIndex"`
+	Code      *string    `json:"code" gorm:"size:50;uniqueIndex:idx_ledger_accounts_code_currency"`
+	Currency  string     `json:"currency" gorm:"not null;size:10;uniqueIndex:idx_ledger_accounts_code_currency"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (LedgerAccount) TableName() string {
+	return "ledger_accounts"
+}
+
+// JournalEntry is one money movement. It is append-only, a correction is a new entry.
+type JournalEntry struct {
+	ID          uuid.UUID       `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	ReferenceID string          `json:"reference_id" gorm:"not null;size:500;index"`
+	Type        string          `json:"type" gorm:"not null;size:50"`
+	Description string          `json:"description" gorm:"type:text"`
+	Postings    []LedgerPosting `json:"postings" gorm:"foreignKey:JournalEntryID"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+func (JournalEntry) TableName() string {
+	return "journal_entries"
+}
+
+// Balanced reports whether debits equal credits for every currency in the entry
+func (e *JournalEntry) Balanced() bool {
+	net := map[string]decimal.Decimal{}
+	for _, posting := range e.Postings {
+		net[posting.Currency] = net[posting.Currency].Add(posting.Signed())
+	}
+
+	for _, amount := range net {
+		if !amount.IsZero() {
+			return false
+		}
+	}
+	return len(e.Postings) > 0
+}
+
+type LedgerPosting struct {
+	ID              uuid.UUID       `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	JournalEntryID  uuid.UUID       `json:"journal_entry_id" gorm:"type:uuid;not null;index"`
+	LedgerAccountID uuid.UUID       `json:"ledger_account_id" gorm:"type:uuid;not null;index"`
+	Direction       string          `json:"direction" gorm:"not null;size:10;comment:debit, credit"`
+	Amount          decimal.Decimal `json:"amount" gorm:"type:numeric(22,4);not null"`
+	Currency        string          `json:"currency" gorm:"not null;size:10"`
+	CreatedAt       time.Time       `json:"created_at"`
+}
+
+func (LedgerPosting) TableName() string {
+	return "ledger_postings"
+}
+
+// Signed returns the amount as it affects a wallet balance: credits add, debits subtract
+func (p *LedgerPosting) Signed() decimal.Decimal {
+	if p.Direction == consts.PostingDirectionDebit {
+		return p.Amount.Neg()
+	}
+	return p.Amount
+}
@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"testing"
+
+	"wallet_api/internal/common/consts"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestJournalEntryBalanced(t *testing.T) {
+	posting := func(direction, amount, currency string) LedgerPosting {
+		return LedgerPosting{Direction: direction, Amount: decimal.RequireFromString(amount), Currency: currency}
+	}
+	debit, credit := consts.PostingDirectionDebit, consts.PostingDirectionCredit
+
+	tests := []struct {
+		name     string
+		postings []LedgerPosting
+		want     bool
+	}{
+		{name: "simple transfer", postings: []LedgerPosting{posting(debit, "100.50", "IDR"), posting(credit, "100.50", "IDR")}, want: true},
+		{name: "split credit", postings: []LedgerPosting{posting(debit, "100", "IDR"), posting(credit, "99", "IDR"), posting(credit, "1", "IDR")}, want: true},
+		{name: "short credit", postings: []LedgerPosting{posting(debit, "100", "IDR"), posting(credit, "99.99", "IDR")}, want: false},
+		{name: "balanced per currency only", postings: []LedgerPosting{posting(debit, "100", "IDR"), posting(credit, "100", "USD")}, want: false},
+		{name: "no postings", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := &JournalEntry{Postings: tt.postings}
+			if got := entry.Balanced(); got != tt.want {
+				t.Errorf("Balanced() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
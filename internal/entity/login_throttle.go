@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+// LoginThrottle counts failed logins for one key ("user:<username>" or "ip:<address>")
+type LoginThrottle struct {
+	Key             string     `json:"key" gorm:"primary_key;size:255"`
+	Failures        int        `json:"failures" gorm:"not null;default:0"`
+	WindowStartedAt time.Time  `json:"window_started_at" gorm:"not null"`
+	LockedUntil     *time.Time `json:"locked_until"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+func (LoginThrottle) TableName() string {
+	return "login_throttles"
+}
@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SecurityEvent struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	UserID    *uuid.UUID `json:"user_id" gorm:"type:uuid;index"`
+	SessionID *uuid.UUID `json:"session_id" gorm:"type:uuid"`
+	EventType string     `json:"event_type" gorm:"not null;size:100;index"`
+	IPAddress string     `json:"ip_address" gorm:"size:45"`
+	UserAgent string     `json:"user_agent" gorm:"type:text"`
+	Details   string     `json:"details" gorm:"type:text"`
+	CreatedAt time.Time  `json:"created_at" gorm:"index"`
+}
+
+func (SecurityEvent) TableName() string {
+	return "security_events"
+}
@@ -15,6 +15,7 @@ type Session struct {
 	IPAddress    string         `json:"ip_address" gorm:"size:45"`
 	IsRevoked    bool           `json:"is_revoked" gorm:"default:false"`
 	ExpiredAt    time.Time      `json:"expired_at" gorm:"not null;index"`
+	LastSeenAt   *time.Time     `json:"last_seen_at"`
 	CreatedAt    time.Time      `json:"created_at"`
 }
 
@@ -0,0 +1,83 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// StringArray maps a Go string slice to a Postgres text[] column
+type StringArray []string
+
+func (a StringArray) Value() (driver.Value, error) {
+	if a == nil {
+		return "{}", nil
+	}
+
+	quoted := make([]string, len(a))
+	for i, s := range a {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+		s = strings.ReplaceAll(s, `"`, `\"`)
+		quoted[i] = `"` + s + `"`
+	}
+	return "{" + strings.Join(quoted, ",") + "}", nil
+}
+
+func (a *StringArray) Scan(src interface{}) error {
+	var literal string
+	switch v := src.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case string:
+		literal = v
+	case []byte:
+		literal = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into StringArray", src)
+	}
+
+	if len(literal) < 2 || literal[0] != '{' || literal[len(literal)-1] != '}' {
+		return fmt.Errorf("invalid array literal %q", literal)
+	}
+	literal = literal[1 : len(literal)-1]
+
+	result := StringArray{}
+	if literal == "" {
+		*a = result
+		return nil
+	}
+
+	// Parse one element at a time; quoted elements may contain commas and escapes
+	var current strings.Builder
+	inQuotes, escaped := false, false
+	for _, r := range literal {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			inQuotes = !inQuotes
+		case r == ',' && !inQuotes:
+			result = append(result, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	result = append(result, current.String())
+
+	*a = result
+	return nil
+}
+
+func (a StringArray) Contains(s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -8,16 +8,24 @@ import (
 )
 
 type Transaction struct {
-	ID            uuid.UUID       `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
-	WalletID      uuid.UUID       `json:"wallet_id" gorm:"type:uuid;not null;index"`
-	Wallet        Wallet          `json:"wallet,omitempty" gorm:"foreignKey:WalletID"`
-	ReferenceID   string          `json:"reference_id" gorm:"uniqueIndex;not null;size:500;comment:Untuk idempotency key"`
-	Type          string          `json:"type" gorm:"not null;size:50;comment:deposit, withdrawal, transfer, payment"`
-	Amount        decimal.Decimal `json:"amount" gorm:"type:numeric(20,2);not null"`
-	BalanceBefore decimal.Decimal `json:"balance_before" gorm:"type:numeric(20,2);not null"`
-	BalanceAfter  decimal.Decimal `json:"balance_after" gorm:"type:numeric(20,2);not null"`
-	Description   string          `json:"description" gorm:"type:text"`
-	CreatedAt     time.Time       `json:"created_at" gorm:"index"`
+	ID             uuid.UUID       `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	WalletID       uuid.UUID       `json:"wallet_id" gorm:"type:uuid;not null;index;uniqueIndex:idx_transactions_wallet_reference"`
+	Wallet         Wallet          `json:"wallet,omitempty" gorm:"foreignKey:WalletID"`
+	ReferenceID    string          `json:"reference_id" gorm:"uniqueIndex:idx_transactions_wallet_reference;not null;size:500;comment:Untuk idempotency key, kedua sisi transfer memakai reference yang sama"`
+	Type           string          `json:"type" gorm:"not null;size:50;comment:deposit, withdrawal, transfer, adjustment, reversal, fx_transfer"`
+	Amount         decimal.Decimal `json:"amount" gorm:"type:numeric(22,4);not null"`
+	BalanceBefore  decimal.Decimal `json:"balance_before" gorm:"type:numeric(22,4);not null"`
+	BalanceAfter   decimal.Decimal `json:"balance_after" gorm:"type:numeric(22,4);not null"`
+	Description    string          `json:"description" gorm:"type:text"`
+	JournalEntryID *uuid.UUID      `json:"journal_entry_id" gorm:"type:uuid;index"`
+	ReversalOfID   *uuid.UUID      `json:"reversal_of_id" gorm:"type:uuid;index;comment:Transaksi asli yang dibalik oleh reversal ini"`
+	// Only set on fx_transfer rows, both legs carry the same values
+	FXQuoteID           *uuid.UUID       `json:"fx_quote_id" gorm:"type:uuid;index"`
+	FXRate              *decimal.Decimal `json:"fx_rate" gorm:"type:numeric(20,10)"`
+	FXSpread            *decimal.Decimal `json:"fx_spread" gorm:"type:numeric(10,6)"`
+	FXSourceAmount      *decimal.Decimal `json:"fx_source_amount" gorm:"type:numeric(22,4)"`
+	FXDestinationAmount *decimal.Decimal `json:"fx_destination_amount" gorm:"type:numeric(22,4)"`
+	CreatedAt           time.Time        `json:"created_at" gorm:"index"`
 }
 
 func (Transaction) TableName() string {
@@ -0,0 +1,103 @@
+package entity
+
+import (
+	"time"
+
+	"wallet_api/internal/common/consts"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// TransferSchedule is a transfer that runs once at a future time or repeats every
+// Interval days, weeks or months until EndAt
+type TransferSchedule struct {
+	ID           uuid.UUID       `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	UserID       uuid.UUID       `json:"user_id" gorm:"type:uuid;not null;index"`
+	FromWalletID uuid.UUID       `json:"from_wallet_id" gorm:"type:uuid;not null"`
+	ToWalletID   uuid.UUID       `json:"to_wallet_id" gorm:"type:uuid;not null"`
+	Amount       decimal.Decimal `json:"amount" gorm:"type:numeric(22,4);not null"`
+	Description  string          `json:"description" gorm:"type:text"`
+	Frequency    string          `json:"frequency" gorm:"not null;size:20;comment:once, daily, weekly, monthly"`
+	Interval     int             `json:"interval" gorm:"column:interval_count;not null;default:1"`
+	StartAt      time.Time       `json:"start_at" gorm:"not null"`
+	EndAt        *time.Time      `json:"end_at"`
+	// Occurrences already passed, run or skipped while paused. The next one is OccurrenceAt(RunCount)
+	RunCount int `json:"run_count" gorm:"not null;default:0"`
+	// Nil once nothing is left to run
+	NextRunAt *time.Time `json:"next_run_at" gorm:"index"`
+	Status    string     `json:"status" gorm:"not null;size:20;comment:active, paused, completed, cancelled"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func (TransferSchedule) TableName() string {
+	return "transfer_schedules"
+}
+
+// OccurrenceAt returns occurrence n, counting from 0 at StartAt. Monthly schedules keep the
+// day of StartAt and fall back to the last day of shorter months, without drifting after them.
+func (s *TransferSchedule) OccurrenceAt(n int) time.Time {
+	switch s.Frequency {
+	case consts.ScheduleFrequencyDaily:
+		return s.StartAt.AddDate(0, 0, n*s.Interval)
+	case consts.ScheduleFrequencyWeekly:
+		return s.StartAt.AddDate(0, 0, 7*n*s.Interval)
+	case consts.ScheduleFrequencyMonthly:
+		firstOfMonth := time.Date(s.StartAt.Year(), s.StartAt.Month(), 1, 0, 0, 0, 0, s.StartAt.Location()).AddDate(0, n*s.Interval, 0)
+		lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+		day := s.StartAt.Day()
+		if day > lastDay {
+			day = lastDay
+		}
+		return time.Date(firstOfMonth.Year(), firstOfMonth.Month(), day,
+			s.StartAt.Hour(), s.StartAt.Minute(), s.StartAt.Second(), s.StartAt.Nanosecond(), s.StartAt.Location())
+	}
+	return s.StartAt
+}
+
+// NextOccurrence is the occurrence after RunCount runs, nil when the schedule is finished
+func (s *TransferSchedule) NextOccurrence() *time.Time {
+	if s.Frequency == consts.ScheduleFrequencyOnce && s.RunCount > 0 {
+		return nil
+	}
+
+	next := s.OccurrenceAt(s.RunCount)
+	if s.EndAt != nil && next.After(*s.EndAt) {
+		return nil
+	}
+	return &next
+}
+
+// Advance moves past the current occurrence and completes the schedule when none is left
+func (s *TransferSchedule) Advance() {
+	s.RunCount++
+	s.NextRunAt = s.NextOccurrence()
+	if s.NextRunAt == nil {
+		s.Status = consts.ScheduleStatusCompleted
+	}
+}
+
+// SkipUntil advances past every occurrence before now, used when a paused schedule resumes
+// so the ones missed while paused are not all run at once
+func (s *TransferSchedule) SkipUntil(now time.Time) {
+	for s.NextRunAt != nil && s.NextRunAt.Before(now) {
+		s.Advance()
+	}
+}
+
+// TransferScheduleRun records one occurrence of a schedule and how it went
+type TransferScheduleRun struct {
+	ID           uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	ScheduleID   uuid.UUID `json:"schedule_id" gorm:"type:uuid;not null;uniqueIndex:idx_transfer_schedule_runs_occurrence"`
+	OccurrenceAt time.Time `json:"occurrence_at" gorm:"not null;uniqueIndex:idx_transfer_schedule_runs_occurrence"`
+	// Reference of the transfer, unique per occurrence so a retried run cannot transfer twice
+	ReferenceID string    `json:"reference_id" gorm:"not null;size:500"`
+	Status      string    `json:"status" gorm:"not null;size:20;comment:succeeded, failed"`
+	Error       string    `json:"error" gorm:"type:text"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (TransferScheduleRun) TableName() string {
+	return "transfer_schedule_runs"
+}
@@ -0,0 +1,77 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"wallet_api/internal/common/consts"
+)
+
+func TestTransferScheduleOccurrenceAt(t *testing.T) {
+	start := time.Date(2026, time.January, 31, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		frequency string
+		interval  int
+		n         int
+		want      time.Time
+	}{
+		{name: "first occurrence is the start", frequency: consts.ScheduleFrequencyMonthly, interval: 1, n: 0, want: start},
+		{name: "daily", frequency: consts.ScheduleFrequencyDaily, interval: 1, n: 3, want: start.AddDate(0, 0, 3)},
+		{name: "every two weeks", frequency: consts.ScheduleFrequencyWeekly, interval: 2, n: 2, want: start.AddDate(0, 0, 28)},
+		{name: "monthly clamps to the end of february", frequency: consts.ScheduleFrequencyMonthly, interval: 1, n: 1, want: time.Date(2026, time.February, 28, 9, 0, 0, 0, time.UTC)},
+		{name: "monthly keeps the day after a short month", frequency: consts.ScheduleFrequencyMonthly, interval: 1, n: 2, want: time.Date(2026, time.March, 31, 9, 0, 0, 0, time.UTC)},
+		{name: "monthly every three months", frequency: consts.ScheduleFrequencyMonthly, interval: 3, n: 1, want: time.Date(2026, time.April, 30, 9, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := &TransferSchedule{Frequency: tt.frequency, Interval: tt.interval, StartAt: start}
+			if got := schedule.OccurrenceAt(tt.n); !got.Equal(tt.want) {
+				t.Errorf("OccurrenceAt(%d) = %s, want %s", tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransferScheduleAdvance(t *testing.T) {
+	start := time.Date(2026, time.March, 1, 9, 0, 0, 0, time.UTC)
+
+	once := &TransferSchedule{Frequency: consts.ScheduleFrequencyOnce, Interval: 1, StartAt: start, NextRunAt: &start, Status: consts.ScheduleStatusActive}
+	once.Advance()
+	if once.NextRunAt != nil || once.Status != consts.ScheduleStatusCompleted {
+		t.Errorf("once after a run: next = %v, status = %s, want nil and completed", once.NextRunAt, once.Status)
+	}
+
+	end := start.AddDate(0, 0, 14)
+	weekly := &TransferSchedule{Frequency: consts.ScheduleFrequencyWeekly, Interval: 1, StartAt: start, EndAt: &end, NextRunAt: &start, Status: consts.ScheduleStatusActive}
+	for i := 0; i < 2; i++ {
+		weekly.Advance()
+		if weekly.Status != consts.ScheduleStatusActive {
+			t.Fatalf("weekly completed after %d runs, want 3 occurrences up to the end date", i+1)
+		}
+	}
+	if !weekly.NextRunAt.Equal(end) {
+		t.Errorf("third occurrence = %s, want the end date %s", weekly.NextRunAt, end)
+	}
+	weekly.Advance()
+	if weekly.Status != consts.ScheduleStatusCompleted {
+		t.Errorf("status after the end date = %s, want completed", weekly.Status)
+	}
+}
+
+func TestTransferScheduleSkipUntil(t *testing.T) {
+	start := time.Date(2026, time.March, 1, 9, 0, 0, 0, time.UTC)
+	schedule := &TransferSchedule{Frequency: consts.ScheduleFrequencyDaily, Interval: 1, StartAt: start, NextRunAt: &start, Status: consts.ScheduleStatusActive}
+
+	schedule.SkipUntil(start.AddDate(0, 0, 3).Add(time.Hour))
+
+	want := start.AddDate(0, 0, 4)
+	if schedule.NextRunAt == nil || !schedule.NextRunAt.Equal(want) {
+		t.Errorf("NextRunAt = %v, want %s", schedule.NextRunAt, want)
+	}
+	if schedule.RunCount != 4 {
+		t.Errorf("RunCount = %d, want 4", schedule.RunCount)
+	}
+}
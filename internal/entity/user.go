@@ -11,9 +11,41 @@ type User struct {
 	Username     string         `json:"username" gorm:"uniqueIndex;not null;size:255"`
 	Email        string         `json:"email" gorm:"uniqueIndex;size:255"`
 	PasswordHash string         `json:"-" gorm:"not null;size:255"`
+	Role         string         `json:"role" gorm:"not null;size:20;default:'user';index"`
 	CreatedAt    time.Time      `json:"created_at"`
+
+	// Email verification, a changed address waits in PendingEmail until it is confirmed
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	PendingEmail    *string    `json:"pending_email" gorm:"size:255"`
+
+	// Two-factor authentication
+	TOTPSecret              string     `json:"-" gorm:"column:totp_secret;size:64"`
+	TOTPEnabledAt           *time.Time `json:"totp_enabled_at" gorm:"column:totp_enabled_at"`
+	TOTPLastUsedStep        int64      `json:"-" gorm:"column:totp_last_used_step;not null;default:0"`
+	TwoFactorFailedAttempts int        `json:"-" gorm:"not null;default:0"`
+	TwoFactorLockedUntil    *time.Time `json:"-"`
+
+	// Transaction PIN for large withdrawals and transfers
+	PINHash           string     `json:"-" gorm:"column:pin_hash;size:255"`
+	PINFailedAttempts int        `json:"-" gorm:"column:pin_failed_attempts;not null;default:0"`
+	PINLockedUntil    *time.Time `json:"-" gorm:"column:pin_locked_until"`
 }
 
 func (User) TableName() string {
 	return "users"
 }
+
+// TwoFactorEnabled reports whether the user has confirmed a TOTP enrollment
+func (u *User) TwoFactorEnabled() bool {
+	return u.TOTPEnabledAt != nil
+}
+
+// EmailVerified reports whether the user has confirmed their current email address
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// HasPIN reports whether the user has set a transaction PIN
+func (u *User) HasPIN() bool {
+	return u.PINHash != ""
+}
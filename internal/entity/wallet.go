@@ -13,12 +13,19 @@ type Wallet struct {
 	User        User           `json:"user,omitempty" gorm:"foreignKey:UserID"`
 	WalletName  string         `json:"wallet_name" gorm:"size:255"`
 	Currency    string         `json:"currency" gorm:"default:'IDR';size:10"`
-	Balance     decimal.Decimal `json:"balance" gorm:"type:numeric(20,2);default:0"`
+	Balance     decimal.Decimal `json:"balance" gorm:"type:numeric(22,4);default:0"`
 	Status      string         `json:"status" gorm:"default:'active';size:50;comment:active, disabled"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
+	// Sum of the active holds, loaded by the usecase and not stored
+	HeldBalance decimal.Decimal `json:"held_balance" gorm:"-"`
 }
 
 func (Wallet) TableName() string {
 	return "wallets"
 }
+
+// AvailableBalance is what can still be withdrawn or transferred, held money is reserved
+func (w *Wallet) AvailableBalance() decimal.Decimal {
+	return w.Balance.Sub(w.HeldBalance)
+}
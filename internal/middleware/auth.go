@@ -1,6 +1,10 @@
 package middleware
 
 import (
+	"context"
+	stdErrors "errors"
+
+	"wallet_api/internal/common/errors"
 	"wallet_api/internal/common/response"
 	"wallet_api/internal/utils"
 
@@ -8,10 +12,15 @@ import (
 	"github.com/google/uuid"
 )
 
-func JWTAuth() fiber.Handler {
+// SessionValidator checks that a token was issued by the server and that its session is still alive
+type SessionValidator interface {
+	ValidateAccessToken(ctx context.Context, claims *utils.Claims, token string) error
+}
+
+func JWTAuth(jwtManager *utils.JWTManager, sessions SessionValidator) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		// Get token from cookie only
-		tokenString := utils.GetAccessTokenFromCookie(c)
+		// Get token from the Authorization header, or from the cookie when no header is sent
+		tokenString, authMethod := utils.GetAccessToken(c)
 
 		// If no token, return unauthorized
 		if tokenString == "" {
@@ -19,8 +28,7 @@ func JWTAuth() fiber.Handler {
 		}
 
 		// 4. Validate token
-		jwtManager := utils.NewJWTManager(utils.GetSecretKey())
-		claims, err := jwtManager.ValidateToken(tokenString)
+		claims, err := jwtManager.ValidateAccessToken(tokenString)
 		if err != nil {
 			switch err {
 			case utils.ErrExpiredToken:
@@ -32,28 +40,50 @@ func JWTAuth() fiber.Handler {
 			}
 		}
 
+		// Check the server-side session so logout and revocation apply immediately
+		if err := sessions.ValidateAccessToken(c.Context(), claims, tokenString); err != nil {
+			var appErr *errors.AppError
+			if stdErrors.As(err, &appErr) {
+				return c.Status(401).JSON(response.Error(401, appErr.Message))
+			}
+			return c.Status(401).JSON(response.Error(401, "Authentication failed"))
+		}
+
 		// 5. Store user info in context for use in handlers
 		c.Locals("user_id", claims.UserID)
 		c.Locals("username", claims.Username)
+		c.Locals("role", claims.Role)
+		c.Locals("session_id", claims.SessionID)
+		c.Locals("auth_method", authMethod)
+
+		// Cookies are sent by the browser on its own, so cookie auth needs a CSRF token too
+		if !passesCSRF(c) {
+			return rejectCSRF(c)
+		}
 
 		return c.Next()
 	}
 }
 
 // Berguna untuk routes yang bisa diakses public tapi dengan extra features jika logged in
-func OptionalJWTAuth() fiber.Handler {
+func OptionalJWTAuth(jwtManager *utils.JWTManager, sessions SessionValidator) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		// Try to get token from cookie
-		tokenString := utils.GetAccessTokenFromCookie(c)
+		// Try to get token from the Authorization header or cookie
+		tokenString, authMethod := utils.GetAccessToken(c)
 
 		// If we have a token, validate it
 		if tokenString != "" {
-			jwtManager := utils.NewJWTManager(utils.GetSecretKey())
-			claims, err := jwtManager.ValidateToken(tokenString)
+			claims, err := jwtManager.ValidateAccessToken(tokenString)
+			if err == nil {
+				err = sessions.ValidateAccessToken(c.Context(), claims, tokenString)
+			}
 			if err == nil {
 				// Token valid, store user info
 				c.Locals("user_id", claims.UserID)
 				c.Locals("username", claims.Username)
+				c.Locals("role", claims.Role)
+				c.Locals("session_id", claims.SessionID)
+				c.Locals("auth_method", authMethod)
 				c.Locals("authenticated", true)
 			} else {
 				// Token invalid but we don't block the request
@@ -77,6 +107,22 @@ func GetUsername(c *fiber.Ctx) (string, bool) {
 	return username, ok
 }
 
+func GetRole(c *fiber.Ctx) (string, bool) {
+	role, ok := c.Locals("role").(string)
+	return role, ok
+}
+
+func GetSessionID(c *fiber.Ctx) (uuid.UUID, bool) {
+	sessionID, ok := c.Locals("session_id").(uuid.UUID)
+	return sessionID, ok
+}
+
+// GetAuthMethod returns utils.AuthMethodBearer, utils.AuthMethodCookie or AuthMethodAPIKey for authenticated requests
+func GetAuthMethod(c *fiber.Ctx) (string, bool) {
+	method, ok := c.Locals("auth_method").(string)
+	return method, ok
+}
+
 func IsAuthenticated(c *fiber.Ctx) bool {
 	authenticated, ok := c.Locals("authenticated").(bool)
 	if !ok {
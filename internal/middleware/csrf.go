@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"wallet_api/internal/common/response"
+	"wallet_api/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CSRF rejects state-changing requests authenticated by cookie unless the X-CSRF-Token
+// header matches the csrf_token cookie. Bearer and API key requests are exempt: a
+// browser never attaches those on its own.
+//
+// JWTAuth already runs this check, use CSRF alone on routes that read auth cookies
+// without JWTAuth (e.g. cookie refresh).
+func CSRF() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !passesCSRF(c) {
+			return rejectCSRF(c)
+		}
+		return c.Next()
+	}
+}
+
+func passesCSRF(c *fiber.Ctx) bool {
+	return !requiresCSRF(c) || utils.ValidCSRFToken(c)
+}
+
+func rejectCSRF(c *fiber.Ctx) error {
+	return c.Status(403).JSON(response.Error(403, "Invalid or missing CSRF token"))
+}
+
+func requiresCSRF(c *fiber.Ctx) bool {
+	switch c.Method() {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+		return false
+	}
+
+	method, ok := GetAuthMethod(c)
+	if !ok {
+		// Not authenticated yet, only the cookie refresh route uses CSRF this way
+		return true
+	}
+
+	return method == utils.AuthMethodCookie
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"wallet_api/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestCSRF(t *testing.T) {
+	app := fiber.New()
+	app.Post("/cookie", func(c *fiber.Ctx) error {
+		c.Locals("auth_method", utils.AuthMethodCookie)
+		return c.Next()
+	}, CSRF(), okHandler)
+	app.Post("/bearer", func(c *fiber.Ctx) error {
+		c.Locals("auth_method", utils.AuthMethodBearer)
+		return c.Next()
+	}, CSRF(), okHandler)
+	app.Get("/cookie", func(c *fiber.Ctx) error {
+		c.Locals("auth_method", utils.AuthMethodCookie)
+		return c.Next()
+	}, CSRF(), okHandler)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		cookie string
+		header string
+		want   int
+	}{
+		{name: "matching token", method: http.MethodPost, path: "/cookie", cookie: "abc", header: "abc", want: 200},
+		{name: "missing header", method: http.MethodPost, path: "/cookie", cookie: "abc", want: 403},
+		{name: "mismatched token", method: http.MethodPost, path: "/cookie", cookie: "abc", header: "abd", want: 403},
+		{name: "both empty", method: http.MethodPost, path: "/cookie", want: 403},
+		{name: "bearer is exempt", method: http.MethodPost, path: "/bearer", want: 200},
+		{name: "safe method is exempt", method: http.MethodGet, path: "/cookie", want: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: utils.CSRFCookie, Value: tt.cookie})
+			}
+			if tt.header != "" {
+				req.Header.Set(utils.CSRFHeader, tt.header)
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("app.Test() error = %v", err)
+			}
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func okHandler(c *fiber.Ctx) error {
+	return c.SendStatus(200)
+}
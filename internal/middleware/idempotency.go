@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stdErrors "errors"
+
+	"wallet_api/internal/common/errors"
+	"wallet_api/internal/common/response"
+	"wallet_api/internal/entity"
+	"wallet_api/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// Set on responses replayed from an earlier request with the same key
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// IdempotencyStore claims Idempotency-Key values and keeps the first response for replays
+type IdempotencyStore interface {
+	Begin(ctx context.Context, userID uuid.UUID, key, requestHash string) (*entity.IdempotencyKey, error)
+	Complete(ctx context.Context, id uuid.UUID, status int, body []byte) error
+	Release(ctx context.Context, id uuid.UUID) error
+}
+
+// Idempotency makes a route safe to retry when the client sends an Idempotency-Key header.
+// Only successful responses are stored, a failed request released its key and can be sent again.
+// A takeover is never released: its first run may have moved the money before it died.
+// It must run after authentication, keys are scoped to the user.
+func Idempotency(store IdempotencyStore, l logger.Interface) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(IdempotencyKeyHeader)
+		if key == "" {
+			return c.Next()
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return c.Status(400).JSON(response.Error(400, "Idempotency-Key is too long"))
+		}
+
+		userID, ok := GetUserID(c)
+		if !ok {
+			return c.Status(401).JSON(response.Error(401, "Authentication required"))
+		}
+
+		record, err := store.Begin(c.Context(), userID, key, requestHash(c))
+		if err != nil {
+			var appErr *errors.AppError
+			if stdErrors.As(err, &appErr) {
+				return c.Status(appErr.Code).JSON(response.Error(appErr.Code, appErr.Message))
+			}
+			return c.Status(500).JSON(response.Error(500, "Failed to process Idempotency-Key"))
+		}
+
+		if record.Completed() {
+			c.Set(IdempotentReplayedHeader, "true")
+			c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			return c.Status(*record.ResponseStatus).Send(record.ResponseBody)
+		}
+
+		c.Locals("idempotency_key", record)
+
+		completed := false
+		defer func() {
+			// Handler error or panic, let the client retry
+			if !completed && !record.TakenOver {
+				_ = store.Release(context.Background(), record.ID)
+			}
+		}()
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		status := c.Response().StatusCode()
+		if status < 200 || status >= 300 {
+			return nil
+		}
+
+		// The money already moved, so the claim is kept even when storing the response fails.
+		// Retries then get a 409 until the lock runs out and one takes the key over.
+		body := append([]byte(nil), c.Response().Body()...)
+		if err := store.Complete(context.Background(), record.ID, status, body); err != nil {
+			l.Error("failed to store response for idempotency key %s: %v", record.ID, err)
+		}
+		completed = true
+
+		return nil
+	}
+}
+
+// GetIdempotencyReference returns the ID of the claimed key, used as the transaction reference
+func GetIdempotencyReference(c *fiber.Ctx) string {
+	record, ok := c.Locals("idempotency_key").(*entity.IdempotencyKey)
+	if !ok {
+		return ""
+	}
+	return record.ID.String()
+}
+
+// requestHash fingerprints method, path and body. JSON bodies are compacted first
+// so whitespace differences between retries do not count as a different request.
+func requestHash(c *fiber.Ctx) string {
+	body := c.Body()
+	var compacted bytes.Buffer
+	if json.Compact(&compacted, body) == nil {
+		body = compacted.Bytes()
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(c.Method() + " " + c.Path() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
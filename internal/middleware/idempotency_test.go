@@ -0,0 +1,190 @@
+package middleware
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"wallet_api/internal/common/consts"
+	"wallet_api/internal/common/errors"
+	"wallet_api/internal/entity"
+	"wallet_api/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+var errTestKeyReused = errors.New(422, "Idempotency-Key was already used for a different request", nil)
+
+// memoryIdempotencyStore keeps one record per key, without the waiting done by the real store
+type memoryIdempotencyStore struct {
+	records  map[string]*entity.IdempotencyKey
+	released int
+	// Hand out new claims as if they took over a key whose lock ran out
+	takeOver bool
+	// Returned by Complete when set
+	completeErr error
+}
+
+// errorLogger records Error calls and drops everything else
+type errorLogger struct {
+	logger.Interface
+	errors []string
+}
+
+func (l *errorLogger) Error(message interface{}, args ...interface{}) {
+	l.errors = append(l.errors, fmt.Sprintf(fmt.Sprint(message), args...))
+}
+
+func (s *memoryIdempotencyStore) Begin(_ context.Context, userID uuid.UUID, key, requestHash string) (*entity.IdempotencyKey, error) {
+	if record, ok := s.records[key]; ok {
+		if record.RequestHash != requestHash {
+			return nil, errTestKeyReused
+		}
+		return record, nil
+	}
+
+	record := &entity.IdempotencyKey{ID: uuid.New(), UserID: userID, Key: key, RequestHash: requestHash, Status: consts.IdempotencyStatusProcessing, TakenOver: s.takeOver}
+	s.records[key] = record
+	return record, nil
+}
+
+func (s *memoryIdempotencyStore) Complete(_ context.Context, id uuid.UUID, status int, body []byte) error {
+	if s.completeErr != nil {
+		return s.completeErr
+	}
+	for _, record := range s.records {
+		if record.ID == id {
+			record.Status = consts.IdempotencyStatusCompleted
+			record.ResponseStatus = &status
+			record.ResponseBody = body
+		}
+	}
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(_ context.Context, id uuid.UUID) error {
+	for key, record := range s.records {
+		if record.ID == id {
+			delete(s.records, key)
+		}
+	}
+	s.released++
+	return nil
+}
+
+func TestIdempotency(t *testing.T) {
+	store := &memoryIdempotencyStore{records: map[string]*entity.IdempotencyKey{}}
+	calls := 0
+	log := &errorLogger{}
+
+	app := fiber.New()
+	app.Post("/deposit", func(c *fiber.Ctx) error {
+		c.Locals("user_id", uuid.New())
+		return c.Next()
+	}, Idempotency(store, log), func(c *fiber.Ctx) error {
+		calls++
+		if strings.Contains(string(c.Body()), "fail") {
+			return c.Status(400).JSON(fiber.Map{"ok": false})
+		}
+		return c.JSON(fiber.Map{"call": calls, "reference": GetIdempotencyReference(c)})
+	})
+
+	send := func(key, body string) (*http.Response, string) {
+		req := httptest.NewRequest(http.MethodPost, "/deposit", strings.NewReader(body))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("app.Test() error = %v", err)
+		}
+		data, _ := io.ReadAll(resp.Body)
+		return resp, string(data)
+	}
+
+	t.Run("replay returns the first response", func(t *testing.T) {
+		_, first := send("k1", `{"amount":"10"}`)
+		resp, second := send("k1", `{ "amount": "10" }`)
+
+		if second != first {
+			t.Errorf("replayed body = %s, want %s", second, first)
+		}
+		if resp.Header.Get(IdempotentReplayedHeader) != "true" {
+			t.Errorf("missing %s header", IdempotentReplayedHeader)
+		}
+		if calls != 1 {
+			t.Errorf("handler ran %d times, want 1", calls)
+		}
+		if !strings.Contains(first, store.records["k1"].ID.String()) {
+			t.Errorf("response %s does not carry the key reference", first)
+		}
+	})
+
+	t.Run("different body is rejected", func(t *testing.T) {
+		resp, _ := send("k1", `{"amount":"20"}`)
+		if resp.StatusCode != 422 {
+			t.Errorf("status = %d, want 422", resp.StatusCode)
+		}
+	})
+
+	t.Run("failed request releases the key", func(t *testing.T) {
+		resp, _ := send("k2", `{"amount":"fail"}`)
+		if resp.StatusCode != 400 {
+			t.Fatalf("status = %d, want 400", resp.StatusCode)
+		}
+		if _, ok := store.records["k2"]; ok || store.released != 1 {
+			t.Errorf("key was not released")
+		}
+	})
+
+	t.Run("failed takeover keeps the key", func(t *testing.T) {
+		store.takeOver = true
+		defer func() { store.takeOver = false }()
+
+		released := store.released
+		resp, _ := send("k3", `{"amount":"fail"}`)
+		if resp.StatusCode != 400 {
+			t.Fatalf("status = %d, want 400", resp.StatusCode)
+		}
+		if _, ok := store.records["k3"]; !ok || store.released != released {
+			t.Errorf("taken over key was released")
+		}
+	})
+
+	t.Run("failing to store the response is logged", func(t *testing.T) {
+		store.completeErr = stdErrors.New("connection reset")
+		defer func() { store.completeErr = nil }()
+
+		resp, _ := send("k4", `{"amount":"10"}`)
+		if resp.StatusCode != 200 {
+			t.Fatalf("status = %d, want 200", resp.StatusCode)
+		}
+		if _, ok := store.records["k4"]; !ok || len(log.errors) != 1 || !strings.Contains(log.errors[0], "connection reset") {
+			t.Errorf("key kept = %v, logged errors = %v, want the key kept and the error logged", ok, log.errors)
+		}
+	})
+
+	t.Run("no header is not tracked", func(t *testing.T) {
+		before := calls
+		send("", `{"amount":"10"}`)
+		send("", `{"amount":"10"}`)
+		if calls != before+2 {
+			t.Errorf("handler ran %d times, want 2", calls-before)
+		}
+	})
+
+	t.Run("key too long", func(t *testing.T) {
+		resp, _ := send(strings.Repeat("k", maxIdempotencyKeyLength+1), `{}`)
+		if resp.StatusCode != 400 {
+			t.Errorf("status = %d, want 400", resp.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"wallet_api/internal/common/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireRole only lets requests through when the authenticated user has one of the given roles.
+// It must be installed after JWTAuth, which puts the role from the token into the context.
+func RequireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, ok := GetRole(c)
+		if !ok {
+			return c.Status(401).JSON(response.Error(401, "Authentication required"))
+		}
+
+		for _, allowed := range roles {
+			if role == allowed {
+				return c.Next()
+			}
+		}
+
+		return c.Status(403).JSON(response.Error(403, "Forbidden"))
+	}
+}
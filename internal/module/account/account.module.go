@@ -1,27 +1,48 @@
 package account
 
 import (
+	"wallet_api/config"
+	"wallet_api/internal/middleware"
 	"wallet_api/internal/module/account/handler"
 	"wallet_api/internal/module/account/repository"
 	accountusecase "wallet_api/internal/module/account/usecase"
 	"wallet_api/pkg/logger"
 
+	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
 
 type Module struct {
-	UseCase accountusecase.UseCase
-	Handler *handler.Handler
+	UseCase            accountusecase.UseCase
+	IdempotencyUseCase accountusecase.IdempotencyUseCase
+	ReconcileUseCase   accountusecase.ReconcileUseCase
+	Handler            *handler.Handler
+	// Idempotency middleware for money-moving routes, shared with the FX module
+	Idempotency fiber.Handler
 }
 
-func NewModule(db *gorm.DB, log logger.Interface) *Module {
-	accountRepo := repository.New(db)
-	transactionRepo := repository.NewTransactionRepository(db)
-	uc := accountusecase.New(accountRepo, transactionRepo)
-	h := handler.New(uc, log)
+func NewModule(
+	db *gorm.DB,
+	log logger.Interface,
+	cfg *config.Config,
+	users accountusecase.EmailVerification,
+	stepUp accountusecase.StepUp,
+	currencies accountusecase.Currencies,
+) *Module {
+	repos := repository.NewRepositories(db)
+	uow := repository.NewUnitOfWork(db)
+	holdLimits := accountusecase.HoldLimits{DefaultTTL: cfg.Hold.DefaultTTL, MaxTTL: cfg.Hold.MaxTTL}
+	uc := accountusecase.New(repos.Wallets, repos.Transactions, repos.Holds, uow, users, stepUp, currencies, cfg.StepUp.Thresholds, holdLimits)
+	idempotencyUC := accountusecase.NewIdempotencyUseCase(repository.NewIdempotencyKeyRepository(db), cfg.Idempotency.KeyTTL)
+	reconcileUC := accountusecase.NewReconcileUseCase(repos.Reconcile, uow)
+	reversalUC := accountusecase.NewReversalUseCase(repos.Transactions, uow, currencies, cfg.Reversal.InsufficientBalancePolicy)
+	h := handler.New(uc, reconcileUC, reversalUC, log)
 
 	return &Module{
-		UseCase: uc,
-		Handler: h,
+		UseCase:            uc,
+		IdempotencyUseCase: idempotencyUC,
+		ReconcileUseCase:   reconcileUC,
+		Handler:            h,
+		Idempotency:        middleware.Idempotency(idempotencyUC, log),
 	}
 }
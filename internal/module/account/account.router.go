@@ -1,21 +1,51 @@
 package account
 
 import (
+	"wallet_api/internal/common/consts"
 	"wallet_api/internal/middleware"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func (m *Module) RegisterRoutes(app *fiber.App) {
-	wallets := app.Group("/v1/wallets", middleware.JWTAuth())
+// walletAuth also accepts API keys, so every wallet route declares the scope it needs
+func (m *Module) RegisterRoutes(app *fiber.App, auth, walletAuth fiber.Handler) {
+	// Retries with the same Idempotency-Key replay the first response instead of moving money twice
+	idempotent := m.Idempotency
+
+	wallets := app.Group("/v1/wallets", walletAuth)
+	{
+
+		wallets.Post("/", middleware.RequireScope(consts.ScopeWalletsWrite), m.Handler.CreateAccount)
+		wallets.Get("/", middleware.RequireScope(consts.ScopeWalletsRead), m.Handler.GetUserAccounts)
+		wallets.Get("/:id", middleware.RequireScope(consts.ScopeWalletsRead), m.Handler.GetAccount)
+		wallets.Post("/:id/deposit", middleware.RequireScope(consts.ScopeWalletsWrite), idempotent, m.Handler.Deposit)
+		wallets.Post("/:id/withdraw", middleware.RequireScope(consts.ScopeWalletsWrite), idempotent, m.Handler.Withdraw)
+		wallets.Post("/:id/transfer", middleware.RequireScope(consts.ScopeTransfersCreate), idempotent, m.Handler.Transfer)
+		wallets.Get("/:id/transactions", middleware.RequireScope(consts.ScopeWalletsRead), m.Handler.GetTransactions)
+		wallets.Post("/:id/holds", middleware.RequireScope(consts.ScopeWalletsWrite), idempotent, m.Handler.CreateHold)
+		wallets.Get("/:id/holds", middleware.RequireScope(consts.ScopeWalletsRead), m.Handler.GetHolds)
+		wallets.Get("/:id/holds/:hold_id", middleware.RequireScope(consts.ScopeWalletsRead), m.Handler.GetHold)
+		wallets.Post("/:id/holds/:hold_id/capture", middleware.RequireScope(consts.ScopeWalletsWrite), idempotent, m.Handler.CaptureHold)
+		wallets.Post("/:id/holds/:hold_id/void", middleware.RequireScope(consts.ScopeWalletsWrite), m.Handler.VoidHold)
+	}
+
+	admin := app.Group("/v1/admin/wallets", auth, middleware.RequireRole(consts.RoleAdmin))
 	{
+		admin.Get("/", m.Handler.ListWallets)
+		admin.Get("/:id", m.Handler.AdminGetWallet)
+		admin.Get("/:id/transactions", m.Handler.AdminGetTransactions)
+		admin.Post("/:id/freeze", m.Handler.FreezeWallet)
+		admin.Post("/:id/unfreeze", m.Handler.UnfreezeWallet)
+	}
 
-		wallets.Post("/", m.Handler.CreateAccount)
-		wallets.Get("/", m.Handler.GetUserAccounts)
-		wallets.Get("/:id", m.Handler.GetAccount)
-		wallets.Post("/:id/deposit", m.Handler.Deposit)
-		wallets.Post("/:id/withdraw", m.Handler.Withdraw)
-		wallets.Post("/:id/transfer", m.Handler.Transfer)
-		wallets.Get("/:id/transactions", m.Handler.GetTransactions)
+	transactions := app.Group("/v1/admin/transactions", auth, middleware.RequireRole(consts.RoleAdmin))
+	{
+		transactions.Post("/:reference_id/reverse", idempotent, m.Handler.ReverseTransaction)
+	}
+
+	reconciliation := app.Group("/v1/admin/reconciliation", auth, middleware.RequireRole(consts.RoleAdmin))
+	{
+		reconciliation.Get("/", m.Handler.Reconcile)
+		reconciliation.Post("/fix", m.Handler.FixReconciliation)
 	}
 }
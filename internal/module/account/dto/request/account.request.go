@@ -8,10 +8,48 @@ type CreateAccountRequest struct {
 type TransactionRequest struct {
 	Amount      string `json:"amount" validate:"required,gt=0"`
 	Description string `json:"description"`
+	// Wajib untuk tarik di atas batas step-up, isi salah satu
+	PIN         string `json:"pin"`
+	StepUpToken string `json:"step_up_token"`
 }
 
 type TransferRequest struct {
 	ToWalletID string `json:"to_wallet_id" validate:"required"`
 	Amount     string `json:"amount" validate:"required,gt=0"`
 	Description string `json:"description"`
+	// Wajib untuk transfer di atas batas step-up, isi salah satu
+	PIN         string `json:"pin"`
+	StepUpToken string `json:"step_up_token"`
+}
+
+type CreateHoldRequest struct {
+	Amount      string `json:"amount" validate:"required,gt=0"`
+	Description string `json:"description"`
+	// Kosong = HOLD_DEFAULT_TTL
+	ExpiresInSeconds int `json:"expires_in_seconds"`
+	// Wajib untuk hold di atas batas step-up, isi salah satu
+	PIN         string `json:"pin"`
+	StepUpToken string `json:"step_up_token"`
+}
+
+type CaptureHoldRequest struct {
+	// Kosong = capture seluruh hold, sisa capture sebagian dilepas
+	Amount string `json:"amount"`
+	// Kosong = capture menjadi penarikan, diisi = transfer ke wallet ini
+	ToWalletID  string `json:"to_wallet_id"`
+	Description string `json:"description"`
+}
+
+type ReverseTransactionRequest struct {
+	// Kosong = balik semua sisa amount yang belum dibalik
+	Amount string `json:"amount"`
+	Reason string `json:"reason" validate:"required"`
+	// fail, negative atau hold. Kosong = REVERSAL_INSUFFICIENT_BALANCE_POLICY
+	InsufficientBalancePolicy string `json:"insufficient_balance_policy"`
+}
+
+type ReconcileFixRequest struct {
+	// Dicatat di deskripsi setiap adjustment
+	Reason   string `json:"reason" validate:"required"`
+	WalletID string `json:"wallet_id"`
 }
@@ -11,9 +11,11 @@ type WalletResponse struct {
 	WalletName string `json:"wallet_name"`
 	Currency   string `json:"currency"`
 	Balance    string `json:"balance"`
-	Status     string `json:"status"`
-	CreatedAt  string `json:"created_at"`
-	UpdatedAt  string `json:"updated_at"`
+	// Saldo dikurangi hold yang masih aktif
+	AvailableBalance string `json:"available_balance"`
+	Status           string `json:"status"`
+	CreatedAt        string `json:"created_at"`
+	UpdatedAt        string `json:"updated_at"`
 }
 
 type TransactionResponse struct {
@@ -25,19 +27,34 @@ type TransactionResponse struct {
 	BalanceBefore string `json:"balance_before"`
 	BalanceAfter  string `json:"balance_after"`
 	Description   string `json:"description"`
-	CreatedAt     string `json:"created_at"`
+	// Kedua sisi transfer menunjuk journal entry yang sama
+	JournalEntryID string `json:"journal_entry_id,omitempty"`
+	// Diisi pada baris reversal, menunjuk transaksi yang dibalik
+	ReversalOfID string `json:"reversal_of_id,omitempty"`
+	// Diisi pada fx_transfer, kedua sisi membawa quote yang sama
+	FX        *FXDetailResponse `json:"fx,omitempty"`
+	CreatedAt string            `json:"created_at"`
+}
+
+type FXDetailResponse struct {
+	QuoteID           string `json:"quote_id"`
+	Rate              string `json:"rate"`
+	Spread            string `json:"spread"`
+	SourceAmount      string `json:"source_amount"`
+	DestinationAmount string `json:"destination_amount"`
 }
 
 func ToWalletDto(wallet *entity.Wallet) WalletResponse {
 	return WalletResponse{
-		ID:         wallet.ID.String(),
-		UserID:     wallet.UserID.String(),
-		WalletName: wallet.WalletName,
-		Currency:   wallet.Currency,
-		Balance:    wallet.Balance.String(),
-		Status:     wallet.Status,
-		CreatedAt:  wallet.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:  wallet.UpdatedAt.Format(time.RFC3339),
+		ID:               wallet.ID.String(),
+		UserID:           wallet.UserID.String(),
+		WalletName:       wallet.WalletName,
+		Currency:         wallet.Currency,
+		Balance:          wallet.Balance.String(),
+		AvailableBalance: wallet.AvailableBalance().String(),
+		Status:           wallet.Status,
+		CreatedAt:        wallet.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:        wallet.UpdatedAt.Format(time.RFC3339),
 	}
 }
 
@@ -50,16 +67,41 @@ func ToWalletDtos(wallets []*entity.Wallet) []WalletResponse {
 }
 
 func ToTransactionDto(transaction *entity.Transaction) TransactionResponse {
+	var journalEntryID string
+	if transaction.JournalEntryID != nil {
+		journalEntryID = transaction.JournalEntryID.String()
+	}
+
+	var reversalOfID string
+	if transaction.ReversalOfID != nil {
+		reversalOfID = transaction.ReversalOfID.String()
+	}
+
+	var fx *FXDetailResponse
+	if transaction.FXQuoteID != nil && transaction.FXRate != nil && transaction.FXSpread != nil &&
+		transaction.FXSourceAmount != nil && transaction.FXDestinationAmount != nil {
+		fx = &FXDetailResponse{
+			QuoteID:           transaction.FXQuoteID.String(),
+			Rate:              transaction.FXRate.String(),
+			Spread:            transaction.FXSpread.String(),
+			SourceAmount:      transaction.FXSourceAmount.String(),
+			DestinationAmount: transaction.FXDestinationAmount.String(),
+		}
+	}
+
 	return TransactionResponse{
-		ID:            transaction.ID.String(),
-		WalletID:      transaction.WalletID.String(),
-		ReferenceID:   transaction.ReferenceID,
-		Type:          transaction.Type,
-		Amount:        transaction.Amount.String(),
-		BalanceBefore: transaction.BalanceBefore.String(),
-		BalanceAfter:  transaction.BalanceAfter.String(),
-		Description:   transaction.Description,
-		CreatedAt:     transaction.CreatedAt.Format(time.RFC3339),
+		ID:             transaction.ID.String(),
+		WalletID:       transaction.WalletID.String(),
+		ReferenceID:    transaction.ReferenceID,
+		Type:           transaction.Type,
+		Amount:         transaction.Amount.String(),
+		BalanceBefore:  transaction.BalanceBefore.String(),
+		BalanceAfter:   transaction.BalanceAfter.String(),
+		Description:    transaction.Description,
+		JournalEntryID: journalEntryID,
+		ReversalOfID:   reversalOfID,
+		FX:             fx,
+		CreatedAt:      transaction.CreatedAt.Format(time.RFC3339),
 	}
 }
 
@@ -0,0 +1,62 @@
+package response
+
+import (
+	"time"
+
+	"wallet_api/internal/entity"
+)
+
+type HoldResponse struct {
+	ID             string `json:"id"`
+	WalletID       string `json:"wallet_id"`
+	ReferenceID    string `json:"reference_id"`
+	Amount         string `json:"amount"`
+	CapturedAmount string `json:"captured_amount"`
+	Currency       string `json:"currency"`
+	// active, captured, voided atau expired
+	Status      string `json:"status"`
+	Description string `json:"description"`
+	// Reference transaksi penarikan atau transfer hasil capture
+	CaptureReferenceID string `json:"capture_reference_id,omitempty"`
+	// Diisi untuk hold kekurangan dari reversal, hanya bisa dilepas dengan reversal berikutnya
+	ReversalOfID string `json:"reversal_of_id,omitempty"`
+	ExpiresAt    string `json:"expires_at"`
+	CreatedAt    string `json:"created_at"`
+	UpdatedAt    string `json:"updated_at"`
+}
+
+func ToHoldDto(hold *entity.Hold) HoldResponse {
+	var captureReferenceID string
+	if hold.CaptureReferenceID != nil {
+		captureReferenceID = *hold.CaptureReferenceID
+	}
+
+	var reversalOfID string
+	if hold.ReversalOfID != nil {
+		reversalOfID = hold.ReversalOfID.String()
+	}
+
+	return HoldResponse{
+		ID:                 hold.ID.String(),
+		WalletID:           hold.WalletID.String(),
+		ReferenceID:        hold.ReferenceID,
+		Amount:             hold.Amount.String(),
+		CapturedAmount:     hold.CapturedAmount.String(),
+		Currency:           hold.Currency,
+		Status:             hold.EffectiveStatus(time.Now()),
+		Description:        hold.Description,
+		CaptureReferenceID: captureReferenceID,
+		ReversalOfID:       reversalOfID,
+		ExpiresAt:          hold.ExpiresAt.Format(time.RFC3339),
+		CreatedAt:          hold.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:          hold.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func ToHoldDtos(holds []*entity.Hold) []HoldResponse {
+	responses := make([]HoldResponse, len(holds))
+	for i, hold := range holds {
+		responses[i] = ToHoldDto(hold)
+	}
+	return responses
+}
@@ -0,0 +1,71 @@
+package response
+
+import (
+	"encoding/csv"
+	"io"
+	"time"
+
+	accountusecase "wallet_api/internal/module/account/usecase"
+)
+
+type DiscrepancyResponse struct {
+	WalletID            string `json:"wallet_id"`
+	TransactionID       string `json:"transaction_id,omitempty"`
+	Kind                string `json:"kind"`
+	Expected            string `json:"expected"`
+	Actual              string `json:"actual"`
+	AdjustmentReference string `json:"adjustment_reference,omitempty"`
+}
+
+type ReconcileReportResponse struct {
+	CheckedWallets int                   `json:"checked_wallets"`
+	Discrepancies  []DiscrepancyResponse `json:"discrepancies"`
+	GeneratedAt    string                `json:"generated_at"`
+}
+
+func ToReconcileReportDto(report *accountusecase.ReconcileReport) ReconcileReportResponse {
+	discrepancies := make([]DiscrepancyResponse, len(report.Discrepancies))
+	for i, discrepancy := range report.Discrepancies {
+		discrepancies[i] = DiscrepancyResponse{
+			WalletID:            discrepancy.WalletID.String(),
+			Kind:                discrepancy.Kind,
+			Expected:            discrepancy.Expected.String(),
+			Actual:              discrepancy.Actual.String(),
+			AdjustmentReference: discrepancy.AdjustmentReference,
+		}
+		if discrepancy.TransactionID != nil {
+			discrepancies[i].TransactionID = discrepancy.TransactionID.String()
+		}
+	}
+
+	return ReconcileReportResponse{
+		CheckedWallets: report.CheckedWallets,
+		Discrepancies:  discrepancies,
+		GeneratedAt:    report.GeneratedAt.Format(time.RFC3339),
+	}
+}
+
+// WriteReconcileCSV writes one row per discrepancy, with a header row
+func WriteReconcileCSV(w io.Writer, report *accountusecase.ReconcileReport) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"wallet_id", "transaction_id", "kind", "expected", "actual", "adjustment_reference"}); err != nil {
+		return err
+	}
+
+	for _, discrepancy := range ToReconcileReportDto(report).Discrepancies {
+		row := []string{
+			discrepancy.WalletID,
+			discrepancy.TransactionID,
+			discrepancy.Kind,
+			discrepancy.Expected,
+			discrepancy.Actual,
+			discrepancy.AdjustmentReference,
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
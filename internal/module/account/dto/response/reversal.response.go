@@ -0,0 +1,31 @@
+package response
+
+import (
+	accountusecase "wallet_api/internal/module/account/usecase"
+)
+
+type ReversalResponse struct {
+	ReferenceID         string                `json:"reference_id"`
+	OriginalReferenceID string                `json:"original_reference_id"`
+	Amount              string                `json:"amount"`
+	Remaining           string                `json:"remaining"`
+	Hold                *HoldResponse         `json:"hold,omitempty"`
+	Transactions        []TransactionResponse `json:"transactions"`
+}
+
+func ToReversalDto(reversal *accountusecase.Reversal) ReversalResponse {
+	var hold *HoldResponse
+	if reversal.Hold != nil {
+		dto := ToHoldDto(reversal.Hold)
+		hold = &dto
+	}
+
+	return ReversalResponse{
+		ReferenceID:         reversal.ReferenceID,
+		OriginalReferenceID: reversal.OriginalReferenceID,
+		Amount:              reversal.Amount.String(),
+		Remaining:           reversal.Remaining.String(),
+		Hold:                hold,
+		Transactions:        ToTransactionDtos(reversal.Transactions),
+	}
+}
@@ -1,7 +1,12 @@
 package handler
 
 import (
+	stdErrors "errors"
+
+	"wallet_api/internal/common/errors"
 	"wallet_api/internal/common/response"
+	"wallet_api/internal/entity"
+	"wallet_api/internal/middleware"
 	"wallet_api/internal/module/account/dto/request"
 	resp "wallet_api/internal/module/account/dto/response"
 	accountusecase "wallet_api/internal/module/account/usecase"
@@ -12,14 +17,23 @@ import (
 )
 
 type Handler struct {
-	uc  accountusecase.UseCase
-	log logger.Interface
+	uc        accountusecase.UseCase
+	reconcile accountusecase.ReconcileUseCase
+	reversal  accountusecase.ReversalUseCase
+	log       logger.Interface
 }
 
-func New(uc accountusecase.UseCase, log logger.Interface) *Handler {
+func New(
+	uc accountusecase.UseCase,
+	reconcile accountusecase.ReconcileUseCase,
+	reversal accountusecase.ReversalUseCase,
+	log logger.Interface,
+) *Handler {
 	return &Handler{
-		uc:  uc,
-		log: log,
+		uc:        uc,
+		reconcile: reconcile,
+		reversal:  reversal,
+		log:       log,
 	}
 }
 
@@ -34,23 +48,25 @@ func (h *Handler) CreateAccount(c *fiber.Ctx) error {
 	wallet, err := h.uc.CreateWallet(c.Context(), userID, req.AccountName, req.Currency)
 	if err != nil {
 		h.log.Error("failed to create wallet: %v", err)
-		return c.Status(500).JSON(response.Error(500, "Failed to create wallet"))
+		return writeError(c, err, 500, "Failed to create wallet")
 	}
 
 	return c.JSON(response.Success(resp.ToWalletDto(wallet), "Wallet created successfully"))
 }
 
 func (h *Handler) GetAccount(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
 	idParam := c.Params("id")
 	walletID, err := uuid.Parse(idParam)
 	if err != nil {
 		return c.Status(400).JSON(response.Error(400, "Invalid wallet ID"))
 	}
 
-	wallet, err := h.uc.GetWallet(c.Context(), walletID)
+	wallet, err := h.uc.GetWallet(c.Context(), userID, walletID)
 	if err != nil {
 		h.log.Error("failed to get wallet: %v", err)
-		return c.Status(404).JSON(response.Error(404, "Wallet not found"))
+		return writeError(c, err, 404, "Wallet not found")
 	}
 
 	return c.JSON(response.Success(resp.ToWalletDto(wallet), "Wallet retrieved"))
@@ -65,10 +81,21 @@ func (h *Handler) GetUserAccounts(c *fiber.Ctx) error {
 		return c.Status(500).JSON(response.Error(500, "Failed to get wallets"))
 	}
 
+	// API keys restricted to some wallets only see those
+	allowed := make([]*entity.Wallet, 0, len(wallets))
+	for _, wallet := range wallets {
+		if middleware.AllowsWallet(c, wallet.ID) {
+			allowed = append(allowed, wallet)
+		}
+	}
+	wallets = allowed
+
 	return c.JSON(response.Success(resp.ToWalletDtos(wallets), "Wallets retrieved"))
 }
 
 func (h *Handler) Deposit(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
 	idParam := c.Params("id")
 	walletID, err := uuid.Parse(idParam)
 	if err != nil {
@@ -86,15 +113,17 @@ func (h *Handler) Deposit(c *fiber.Ctx) error {
 		return c.Status(400).JSON(response.Error(400, "Invalid amount format"))
 	}
 
-	if err := h.uc.Deposit(c.Context(), walletID, amount, req.Description); err != nil {
+	if err := h.uc.Deposit(c.Context(), userID, walletID, amount, req.Description, middleware.GetIdempotencyReference(c)); err != nil {
 		h.log.Error("failed to deposit: %v", err)
-		return c.Status(400).JSON(response.Error(400, err.Error()))
+		return writeError(c, err, 400, err.Error())
 	}
 
 	return c.JSON(response.Success(nil, "Deposit successful"))
 }
 
 func (h *Handler) Withdraw(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
 	idParam := c.Params("id")
 	walletID, err := uuid.Parse(idParam)
 	if err != nil {
@@ -112,15 +141,18 @@ func (h *Handler) Withdraw(c *fiber.Ctx) error {
 		return c.Status(400).JSON(response.Error(400, "Invalid amount format"))
 	}
 
-	if err := h.uc.Withdraw(c.Context(), walletID, amount, req.Description); err != nil {
+	proof := accountusecase.StepUpProof{PIN: req.PIN, StepUpToken: req.StepUpToken}
+	if err := h.uc.Withdraw(c.Context(), userID, walletID, amount, req.Description, proof, middleware.GetIdempotencyReference(c)); err != nil {
 		h.log.Error("failed to withdraw: %v", err)
-		return c.Status(400).JSON(response.Error(400, err.Error()))
+		return writeError(c, err, 400, err.Error())
 	}
 
 	return c.JSON(response.Success(nil, "Withdrawal successful"))
 }
 
 func (h *Handler) GetTransactions(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
 	idParam := c.Params("id")
 	walletID, err := uuid.Parse(idParam)
 	if err != nil {
@@ -137,16 +169,18 @@ func (h *Handler) GetTransactions(c *fiber.Ctx) error {
 		offset = o
 	}
 
-	transactions, err := h.uc.GetTransactions(c.Context(), walletID, limit, offset)
+	transactions, err := h.uc.GetTransactions(c.Context(), userID, walletID, limit, offset)
 	if err != nil {
 		h.log.Error("failed to get transactions: %v", err)
-		return c.Status(500).JSON(response.Error(500, "Failed to get transactions"))
+		return writeError(c, err, 500, "Failed to get transactions")
 	}
 
 	return c.JSON(response.Success(resp.ToTransactionDtos(transactions), "Transactions retrieved"))
 }
 
 func (h *Handler) Transfer(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
 	fromWalletIDParam := c.Params("id")
 	fromWalletID, err := uuid.Parse(fromWalletIDParam)
 	if err != nil {
@@ -169,10 +203,22 @@ func (h *Handler) Transfer(c *fiber.Ctx) error {
 		return c.Status(400).JSON(response.Error(400, "Invalid amount format"))
 	}
 
-	if err := h.uc.Transfer(c.Context(), fromWalletID, toWalletID, amount, req.Description); err != nil {
+	proof := accountusecase.StepUpProof{PIN: req.PIN, StepUpToken: req.StepUpToken}
+	if err := h.uc.Transfer(c.Context(), userID, fromWalletID, toWalletID, amount, req.Description, proof, middleware.GetIdempotencyReference(c)); err != nil {
 		h.log.Error("failed to transfer: %v", err)
-		return c.Status(400).JSON(response.Error(400, err.Error()))
+		return writeError(c, err, 400, err.Error())
 	}
 
 	return c.JSON(response.Success(nil, "Transfer successful"))
 }
+
+// writeError responds with the status carried by an AppError, falling back to
+// the given status and message for any other error.
+func writeError(c *fiber.Ctx, err error, status int, message string) error {
+	var appErr *errors.AppError
+	if stdErrors.As(err, &appErr) {
+		return c.Status(appErr.Code).JSON(response.Error(appErr.Code, appErr.Message))
+	}
+
+	return c.Status(status).JSON(response.Error(status, message))
+}
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"bytes"
+
+	"wallet_api/internal/common/consts"
+	"wallet_api/internal/common/response"
+	"wallet_api/internal/middleware"
+	"wallet_api/internal/module/account/dto/request"
+	resp "wallet_api/internal/module/account/dto/response"
+	accountusecase "wallet_api/internal/module/account/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func (h *Handler) ListWallets(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Query("user_id"))
+	if err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid user ID"))
+	}
+
+	wallets, err := h.uc.GetUserWallets(c.Context(), userID)
+	if err != nil {
+		h.log.Error("failed to get user wallets: %v", err)
+		return c.Status(500).JSON(response.Error(500, "Failed to get wallets"))
+	}
+
+	return c.JSON(response.Success(resp.ToWalletDtos(wallets), "Wallets retrieved"))
+}
+
+func (h *Handler) AdminGetWallet(c *fiber.Ctx) error {
+	walletID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid wallet ID"))
+	}
+
+	wallet, err := h.uc.FindWallet(c.Context(), walletID)
+	if err != nil {
+		h.log.Error("failed to get wallet: %v", err)
+		return writeError(c, err, 404, "Wallet not found")
+	}
+
+	return c.JSON(response.Success(resp.ToWalletDto(wallet), "Wallet retrieved"))
+}
+
+func (h *Handler) AdminGetTransactions(c *fiber.Ctx) error {
+	walletID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid wallet ID"))
+	}
+
+	limit := 10
+	offset := 0
+
+	if l := c.QueryInt("limit", 10); l > 0 {
+		limit = l
+	}
+	if o := c.QueryInt("offset", 0); o >= 0 {
+		offset = o
+	}
+
+	transactions, err := h.uc.FindTransactions(c.Context(), walletID, limit, offset)
+	if err != nil {
+		h.log.Error("failed to get transactions: %v", err)
+		return writeError(c, err, 500, "Failed to get transactions")
+	}
+
+	return c.JSON(response.Success(resp.ToTransactionDtos(transactions), "Transactions retrieved"))
+}
+
+func (h *Handler) FreezeWallet(c *fiber.Ctx) error {
+	return h.setWalletStatus(c, consts.WalletStatusFrozen, "Wallet frozen")
+}
+
+func (h *Handler) UnfreezeWallet(c *fiber.Ctx) error {
+	return h.setWalletStatus(c, consts.WalletStatusActive, "Wallet unfrozen")
+}
+
+func (h *Handler) setWalletStatus(c *fiber.Ctx, status, message string) error {
+	walletID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid wallet ID"))
+	}
+
+	wallet, err := h.uc.SetWalletStatus(c.Context(), walletID, status)
+	if err != nil {
+		h.log.Error("failed to set wallet status: %v", err)
+		return writeError(c, err, 500, "Failed to update wallet status")
+	}
+
+	return c.JSON(response.Success(resp.ToWalletDto(wallet), message))
+}
+
+// ReverseTransaction posts compensating transactions for every row sharing the reference ID
+func (h *Handler) ReverseTransaction(c *fiber.Ctx) error {
+	req := new(request.ReverseTransactionRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid request body"))
+	}
+
+	reverseReq := accountusecase.ReversalRequest{
+		ReferenceID:         c.Params("reference_id"),
+		Reason:              req.Reason,
+		Policy:              req.InsufficientBalancePolicy,
+		ReversalReferenceID: middleware.GetIdempotencyReference(c),
+	}
+	if req.Amount != "" {
+		amount, err := decimal.NewFromString(req.Amount)
+		if err != nil {
+			return c.Status(400).JSON(response.Error(400, "Invalid amount format"))
+		}
+		reverseReq.Amount = &amount
+	}
+
+	reversal, err := h.reversal.Reverse(c.Context(), reverseReq)
+	if err != nil {
+		h.log.Error("failed to reverse transaction: %v", err)
+		return writeError(c, err, 500, "Failed to reverse transaction")
+	}
+
+	return c.JSON(response.Success(resp.ToReversalDto(reversal), "Transaction reversed"))
+}
+
+// Reconcile reports drift between wallet balances, their history and the ledger.
+// ?format=csv returns the discrepancies as CSV, ?wallet_id= checks one wallet.
+func (h *Handler) Reconcile(c *fiber.Ctx) error {
+	walletID, err := optionalWalletID(c.Query("wallet_id"))
+	if err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid wallet ID"))
+	}
+
+	report, err := h.reconcile.Reconcile(c.Context(), walletID)
+	if err != nil {
+		h.log.Error("failed to reconcile: %v", err)
+		return writeError(c, err, 500, "Failed to reconcile")
+	}
+
+	return h.writeReconcileReport(c, report, "Reconciliation completed")
+}
+
+// FixReconciliation writes adjustment entries for wallets whose history or ledger drifted
+func (h *Handler) FixReconciliation(c *fiber.Ctx) error {
+	req := new(request.ReconcileFixRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid request body"))
+	}
+
+	walletID, err := optionalWalletID(req.WalletID)
+	if err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid wallet ID"))
+	}
+
+	report, err := h.reconcile.Fix(c.Context(), walletID, req.Reason)
+	if err != nil {
+		h.log.Error("failed to fix reconciliation: %v", err)
+		return writeError(c, err, 500, "Failed to fix reconciliation")
+	}
+
+	return h.writeReconcileReport(c, report, "Reconciliation adjustments written")
+}
+
+func (h *Handler) writeReconcileReport(c *fiber.Ctx, report *accountusecase.ReconcileReport, message string) error {
+	if c.Query("format") != "csv" {
+		return c.JSON(response.Success(resp.ToReconcileReportDto(report), message))
+	}
+
+	var buf bytes.Buffer
+	if err := resp.WriteReconcileCSV(&buf, report); err != nil {
+		h.log.Error("failed to write reconciliation csv: %v", err)
+		return c.Status(500).JSON(response.Error(500, "Failed to write report"))
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="reconciliation.csv"`)
+	return c.Send(buf.Bytes())
+}
+
+// optionalWalletID parses an optional wallet ID, empty means every wallet
+func optionalWalletID(value string) (*uuid.UUID, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	walletID, err := uuid.Parse(value)
+	if err != nil {
+		return nil, err
+	}
+	return &walletID, nil
+}
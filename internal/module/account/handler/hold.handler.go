@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"time"
+
+	"wallet_api/internal/common/consts"
+	"wallet_api/internal/common/response"
+	"wallet_api/internal/middleware"
+	"wallet_api/internal/module/account/dto/request"
+	resp "wallet_api/internal/module/account/dto/response"
+	accountusecase "wallet_api/internal/module/account/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func (h *Handler) CreateHold(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	walletID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid wallet ID"))
+	}
+
+	req := new(request.CreateHoldRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid request body"))
+	}
+
+	amount, err := decimal.NewFromString(req.Amount)
+	if err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid amount format"))
+	}
+
+	if req.ExpiresInSeconds < 0 {
+		return c.Status(400).JSON(response.Error(400, "Invalid expiry"))
+	}
+	ttl := time.Duration(req.ExpiresInSeconds) * time.Second
+
+	proof := accountusecase.StepUpProof{PIN: req.PIN, StepUpToken: req.StepUpToken}
+	hold, err := h.uc.CreateHold(c.Context(), userID, walletID, amount, req.Description, ttl, proof, middleware.GetIdempotencyReference(c))
+	if err != nil {
+		h.log.Error("failed to create hold: %v", err)
+		return writeError(c, err, 500, "Failed to create hold")
+	}
+
+	return c.JSON(response.Success(resp.ToHoldDto(hold), "Hold created"))
+}
+
+func (h *Handler) GetHolds(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	walletID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid wallet ID"))
+	}
+
+	limit := 10
+	offset := 0
+
+	if l := c.QueryInt("limit", 10); l > 0 {
+		limit = l
+	}
+	if o := c.QueryInt("offset", 0); o >= 0 {
+		offset = o
+	}
+
+	holds, err := h.uc.GetHolds(c.Context(), userID, walletID, limit, offset)
+	if err != nil {
+		h.log.Error("failed to get holds: %v", err)
+		return writeError(c, err, 500, "Failed to get holds")
+	}
+
+	return c.JSON(response.Success(resp.ToHoldDtos(holds), "Holds retrieved"))
+}
+
+func (h *Handler) GetHold(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	walletID, holdID, err := holdParams(c)
+	if err != nil {
+		return c.Status(400).JSON(response.Error(400, err.Error()))
+	}
+
+	hold, err := h.uc.GetHold(c.Context(), userID, walletID, holdID)
+	if err != nil {
+		h.log.Error("failed to get hold: %v", err)
+		return writeError(c, err, 500, "Failed to get hold")
+	}
+
+	return c.JSON(response.Success(resp.ToHoldDto(hold), "Hold retrieved"))
+}
+
+func (h *Handler) CaptureHold(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	walletID, holdID, err := holdParams(c)
+	if err != nil {
+		return c.Status(400).JSON(response.Error(400, err.Error()))
+	}
+
+	req := new(request.CaptureHoldRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid request body"))
+	}
+
+	capture := accountusecase.HoldCapture{
+		Description: req.Description,
+		ReferenceID: middleware.GetIdempotencyReference(c),
+	}
+	if req.Amount != "" {
+		amount, err := decimal.NewFromString(req.Amount)
+		if err != nil {
+			return c.Status(400).JSON(response.Error(400, "Invalid amount format"))
+		}
+		capture.Amount = &amount
+	}
+	if req.ToWalletID != "" {
+		toWalletID, err := uuid.Parse(req.ToWalletID)
+		if err != nil {
+			return c.Status(400).JSON(response.Error(400, "Invalid to wallet ID"))
+		}
+		// Capturing into a transfer is a transfer, API keys need the transfer scope for it
+		if key, ok := middleware.GetAPIKey(c); ok && !key.HasScope(consts.ScopeTransfersCreate) {
+			return c.Status(403).JSON(response.Error(403, "API key is missing scope "+consts.ScopeTransfersCreate))
+		}
+		capture.ToWalletID = &toWalletID
+	}
+
+	hold, err := h.uc.CaptureHold(c.Context(), userID, walletID, holdID, capture)
+	if err != nil {
+		h.log.Error("failed to capture hold: %v", err)
+		return writeError(c, err, 500, "Failed to capture hold")
+	}
+
+	return c.JSON(response.Success(resp.ToHoldDto(hold), "Hold captured"))
+}
+
+func (h *Handler) VoidHold(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	walletID, holdID, err := holdParams(c)
+	if err != nil {
+		return c.Status(400).JSON(response.Error(400, err.Error()))
+	}
+
+	hold, err := h.uc.VoidHold(c.Context(), userID, walletID, holdID)
+	if err != nil {
+		h.log.Error("failed to void hold: %v", err)
+		return writeError(c, err, 500, "Failed to void hold")
+	}
+
+	return c.JSON(response.Success(resp.ToHoldDto(hold), "Hold voided"))
+}
+
+// holdParams parses the :id wallet and :hold_id route params, the error message is safe to return
+func holdParams(c *fiber.Ctx) (uuid.UUID, uuid.UUID, error) {
+	walletID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, fiber.NewError(400, "Invalid wallet ID")
+	}
+
+	holdID, err := uuid.Parse(c.Params("hold_id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, fiber.NewError(400, "Invalid hold ID")
+	}
+
+	return walletID, holdID, nil
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"wallet_api/internal/common/base"
+	"wallet_api/internal/common/consts"
+	"wallet_api/internal/entity"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// HoldReferenceConstraint keeps a reference to one hold per wallet
+const HoldReferenceConstraint = "idx_holds_wallet_reference"
+
+type HoldRepository interface {
+	Create(ctx context.Context, hold *entity.Hold) error
+	FindByID(ctx context.Context, id uuid.UUID) (*entity.Hold, error)
+	FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*entity.Hold, error)
+	FindByWalletID(ctx context.Context, walletID uuid.UUID, limit, offset int) ([]*entity.Hold, error)
+	FindByReferenceID(ctx context.Context, walletID uuid.UUID, referenceID string) (*entity.Hold, error)
+	// FindActiveReversalHold returns the shortfall hold a reversal of transactionID left on the wallet, nil if none
+	FindActiveReversalHold(ctx context.Context, walletID, transactionID uuid.UUID) (*entity.Hold, error)
+	Update(ctx context.Context, hold *entity.Hold) error
+	// HeldAmounts sums the active, unexpired holds per wallet. Wallets without holds are left out.
+	HeldAmounts(ctx context.Context, walletIDs []uuid.UUID, now time.Time) (map[uuid.UUID]decimal.Decimal, error)
+}
+
+type holdRepository struct {
+	*base.BaseRepository[entity.Hold]
+	db *gorm.DB
+}
+
+func NewHoldRepository(db *gorm.DB) HoldRepository {
+	return &holdRepository{
+		BaseRepository: base.NewBaseRepository[entity.Hold](db),
+		db:             db,
+	}
+}
+
+func (r *holdRepository) FindByWalletID(ctx context.Context, walletID uuid.UUID, limit, offset int) ([]*entity.Hold, error) {
+	return r.NewQueryBuilder().
+		Where("wallet_id", walletID).
+		OrderBy("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(ctx)
+}
+
+func (r *holdRepository) FindByReferenceID(ctx context.Context, walletID uuid.UUID, referenceID string) (*entity.Hold, error) {
+	return r.NewQueryBuilder().
+		Where("wallet_id", walletID).
+		Where("reference_id", referenceID).
+		FindOne(ctx)
+}
+
+func (r *holdRepository) FindActiveReversalHold(ctx context.Context, walletID, transactionID uuid.UUID) (*entity.Hold, error) {
+	hold, err := r.NewQueryBuilder().
+		Where("wallet_id", walletID).
+		Where("reversal_of_id", transactionID).
+		Where("status", consts.HoldStatusActive).
+		FindOne(ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return hold, err
+}
+
+func (r *holdRepository) HeldAmounts(ctx context.Context, walletIDs []uuid.UUID, now time.Time) (map[uuid.UUID]decimal.Decimal, error) {
+	held := make(map[uuid.UUID]decimal.Decimal, len(walletIDs))
+	if len(walletIDs) == 0 {
+		return held, nil
+	}
+
+	var rows []struct {
+		WalletID uuid.UUID
+		Amount   decimal.Decimal
+	}
+	err := r.db.WithContext(ctx).
+		Model(&entity.Hold{}).
+		Select("wallet_id, SUM(amount) AS amount").
+		Where("wallet_id IN ? AND status = ? AND expires_at > ?", walletIDs, consts.HoldStatusActive, now).
+		Group("wallet_id").
+		Scan(&rows).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		held[row.WalletID] = row.Amount
+	}
+	return held, nil
+}
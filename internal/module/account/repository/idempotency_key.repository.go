@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	stdErrors "errors"
+	"time"
+
+	"wallet_api/internal/common/base"
+	"wallet_api/internal/common/consts"
+	"wallet_api/internal/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type IdempotencyKeyRepository interface {
+	Claim(ctx context.Context, key *entity.IdempotencyKey, now time.Time) (bool, error)
+	FindByKey(ctx context.Context, userID uuid.UUID, key string) (*entity.IdempotencyKey, error)
+	TakeOver(ctx context.Context, id uuid.UUID, now, lockedUntil time.Time) (bool, error)
+	Complete(ctx context.Context, id uuid.UUID, status int, body []byte) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type idempotencyKeyRepository struct {
+	*base.BaseRepository[entity.IdempotencyKey]
+	db *gorm.DB
+}
+
+func NewIdempotencyKeyRepository(db *gorm.DB) IdempotencyKeyRepository {
+	return &idempotencyKeyRepository{
+		BaseRepository: base.NewBaseRepository[entity.IdempotencyKey](db),
+		db:             db,
+	}
+}
+
+// Claim inserts the key in processing state. An expired row for the same key is replaced,
+// a live one is left alone and Claim reports false.
+func (r *idempotencyKeyRepository) Claim(ctx context.Context, key *entity.IdempotencyKey, now time.Time) (bool, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).Raw(`
+		INSERT INTO idempotency_keys (id, user_id, key, request_hash, status, locked_until, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id, key) DO UPDATE SET
+			id = EXCLUDED.id,
+			request_hash = EXCLUDED.request_hash,
+			status = EXCLUDED.status,
+			response_status = NULL,
+			response_body = NULL,
+			locked_until = EXCLUDED.locked_until,
+			expires_at = EXCLUDED.expires_at,
+			created_at = EXCLUDED.created_at
+		WHERE idempotency_keys.expires_at <= ?
+		RETURNING id`,
+		key.ID, key.UserID, key.Key, key.RequestHash, key.Status, key.LockedUntil, key.ExpiresAt, now, now,
+	).Scan(&ids).Error
+
+	return len(ids) > 0, err
+}
+
+func (r *idempotencyKeyRepository) FindByKey(ctx context.Context, userID uuid.UUID, key string) (*entity.IdempotencyKey, error) {
+	record, err := r.FindOne(ctx, map[string]interface{}{"user_id": userID, "key": key})
+	if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return record, err
+}
+
+// TakeOver moves the lock of a processing key whose locked_until has passed. The lock is never
+// renewed, so a request still running past it can be taken over as well.
+func (r *idempotencyKeyRepository) TakeOver(ctx context.Context, id uuid.UUID, now, lockedUntil time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&entity.IdempotencyKey{}).
+		Where("id = ? AND status = ? AND locked_until < ?", id, consts.IdempotencyStatusProcessing, now).
+		Update("locked_until", lockedUntil)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *idempotencyKeyRepository) Complete(ctx context.Context, id uuid.UUID, status int, body []byte) error {
+	return r.db.WithContext(ctx).
+		Model(&entity.IdempotencyKey{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":          consts.IdempotencyStatusCompleted,
+			"response_status": status,
+			"response_body":   body,
+		}).
+		Error
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+
+	"wallet_api/internal/common/consts"
+	"wallet_api/internal/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LedgerRepository interface {
+	// WalletAccount returns the ledger account of a wallet, creating it on first use
+	WalletAccount(ctx context.Context, wallet *entity.Wallet) (*entity.LedgerAccount, error)
+	// SystemAccount returns a system account of the currency, creating it on first use
+	SystemAccount(ctx context.Context, code, currency string) (*entity.LedgerAccount, error)
+	// Post writes a journal entry together with its postings
+	Post(ctx context.Context, entry *entity.JournalEntry) error
+}
+
+type ledgerRepository struct {
+	db *gorm.DB
+}
+
+func NewLedgerRepository(db *gorm.DB) LedgerRepository {
+	return &ledgerRepository{db: db}
+}
+
+func (r *ledgerRepository) WalletAccount(ctx context.Context, wallet *entity.Wallet) (*entity.LedgerAccount, error) {
+	walletID := wallet.ID
+	account := &entity.LedgerAccount{
+		ID:       uuid.New(),
+		Kind:     consts.LedgerAccountKindWallet,
+		WalletID: &walletID,
+		Currency: wallet.Currency,
+	}
+
+	return r.ensure(ctx, account, "wallet_id = ?", wallet.ID)
+}
+
+func (r *ledgerRepository) SystemAccount(ctx context.Context, code, currency string) (*entity.LedgerAccount, error) {
+	account := &entity.LedgerAccount{
+		ID:       uuid.New(),
+		Kind:     consts.LedgerAccountKindSystem,
+		Code:     &code,
+		Currency: currency,
+	}
+
+	return r.ensure(ctx, account, "code = ? AND currency = ?", code, currency)
+}
+
+// ensure inserts the account unless it exists and then loads the stored row
+func (r *ledgerRepository) ensure(ctx context.Context, account *entity.LedgerAccount, query string, args ...interface{}) (*entity.LedgerAccount, error) {
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(account).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	var stored entity.LedgerAccount
+	if err := r.db.WithContext(ctx).Where(query, args...).First(&stored).Error; err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+func (r *ledgerRepository) Post(ctx context.Context, entry *entity.JournalEntry) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
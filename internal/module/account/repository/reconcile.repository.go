@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"wallet_api/internal/entity"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// WalletSnapshot is a wallet with its full history, read from one consistent snapshot
+type WalletSnapshot struct {
+	Wallet *entity.Wallet
+	// Oldest first
+	Transactions  []*entity.Transaction
+	LedgerBalance decimal.Decimal
+}
+
+type ReconcileRepository interface {
+	// WalletIDs pages through every wallet ordered by ID, pass uuid.Nil to start
+	WalletIDs(ctx context.Context, afterID uuid.UUID, limit int) ([]uuid.UUID, error)
+	Snapshot(ctx context.Context, walletID uuid.UUID) (*WalletSnapshot, error)
+}
+
+type reconcileRepository struct {
+	db *gorm.DB
+}
+
+func NewReconcileRepository(db *gorm.DB) ReconcileRepository {
+	return &reconcileRepository{db: db}
+}
+
+func (r *reconcileRepository) WalletIDs(ctx context.Context, afterID uuid.UUID, limit int) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).
+		Model(&entity.Wallet{}).
+		Where("id > ?", afterID).
+		Order("id").
+		Limit(limit).
+		Pluck("id", &ids).
+		Error
+	return ids, err
+}
+
+// Snapshot reads in a repeatable read transaction so a transfer committing halfway
+// through does not show up as drift
+func (r *reconcileRepository) Snapshot(ctx context.Context, walletID uuid.UUID) (*WalletSnapshot, error) {
+	snapshot := &WalletSnapshot{}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var wallet entity.Wallet
+		if err := tx.First(&wallet, "id = ?", walletID).Error; err != nil {
+			return err
+		}
+		snapshot.Wallet = &wallet
+
+		if err := tx.Where("wallet_id = ?", walletID).Order("created_at, id").Find(&snapshot.Transactions).Error; err != nil {
+			return err
+		}
+
+		return tx.Raw(`
+			SELECT COALESCE(SUM(CASE p.direction WHEN 'credit' THEN p.amount ELSE -p.amount END), 0)
+			FROM ledger_postings p
+			JOIN ledger_accounts a ON a.id = p.ledger_account_id
+			WHERE a.wallet_id = ?`,
+			walletID,
+		).Row().Scan(&snapshot.LedgerBalance)
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
@@ -7,21 +7,31 @@ import (
 	"wallet_api/internal/entity"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 )
 
+// TransactionReferenceConstraint keeps a reference to one row per wallet, a retried operation hits it
+const TransactionReferenceConstraint = "idx_transactions_wallet_reference"
+
 type TransactionRepository interface {
 	Create(ctx context.Context, transaction *entity.Transaction) error
 	FindByWalletID(ctx context.Context, walletID uuid.UUID, limit, offset int) ([]*entity.Transaction, error)
+	// FindByReferenceID returns every row of one operation, both legs of a transfer share a reference
+	FindByReferenceID(ctx context.Context, referenceID string) ([]*entity.Transaction, error)
+	// ReversedAmount sums the reversals already written against a transaction
+	ReversedAmount(ctx context.Context, transactionID uuid.UUID) (decimal.Decimal, error)
 }
 
 type transactionRepository struct {
 	*base.BaseRepository[entity.Transaction]
+	db *gorm.DB
 }
 
 func NewTransactionRepository(db *gorm.DB) TransactionRepository {
 	return &transactionRepository{
 		BaseRepository: base.NewBaseRepository[entity.Transaction](db),
+		db:             db,
 	}
 }
 
@@ -34,3 +44,21 @@ func (r *transactionRepository) FindByWalletID(ctx context.Context, walletID uui
 		Offset(offset).
 		Find(ctx)
 }
+
+func (r *transactionRepository) FindByReferenceID(ctx context.Context, referenceID string) ([]*entity.Transaction, error) {
+	return r.NewQueryBuilder().
+		Where("reference_id", referenceID).
+		OrderBy("created_at, id").
+		Find(ctx)
+}
+
+func (r *transactionRepository) ReversedAmount(ctx context.Context, transactionID uuid.UUID) (decimal.Decimal, error) {
+	var amount decimal.Decimal
+	err := r.db.WithContext(ctx).
+		Model(&entity.Transaction{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("reversal_of_id = ?", transactionID).
+		Row().
+		Scan(&amount)
+	return amount, err
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+
+	"wallet_api/internal/common/base"
+
+	"gorm.io/gorm"
+)
+
+// Repositories is the set of account repositories, all bound to the same database handle
+type Repositories struct {
+	Wallets      WalletRepository
+	Transactions TransactionRepository
+	Ledger       LedgerRepository
+	Reconcile    ReconcileRepository
+	Holds        HoldRepository
+}
+
+func NewRepositories(db *gorm.DB) *Repositories {
+	return &Repositories{
+		Wallets:      New(db),
+		Transactions: NewTransactionRepository(db),
+		Ledger:       NewLedgerRepository(db),
+		Reconcile:    NewReconcileRepository(db),
+		Holds:        NewHoldRepository(db),
+	}
+}
+
+// UnitOfWork runs a group of repository calls in one database transaction
+type UnitOfWork interface {
+	// Do runs fn in a transaction. Every repository in repos is bound to it, so locks and
+	// writes inside fn must go through repos and not through repositories held elsewhere.
+	// fn runs again when the transaction deadlocks, see base.RunInTransaction.
+	Do(ctx context.Context, fn func(repos *Repositories) error) error
+}
+
+type unitOfWork struct {
+	db *gorm.DB
+}
+
+func NewUnitOfWork(db *gorm.DB) UnitOfWork {
+	return &unitOfWork{db: db}
+}
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(repos *Repositories) error) error {
+	return base.RunInTransaction(ctx, u.db, func(tx *gorm.DB) error {
+		return fn(NewRepositories(tx))
+	})
+}
@@ -1,13 +1,19 @@
 package accountusecase
 
 import (
+	"bytes"
 	"context"
+	stdErrors "errors"
 	"fmt"
+	"sort"
+	"time"
 
+	"wallet_api/internal/common/base"
 	"wallet_api/internal/common/consts"
 	"wallet_api/internal/common/errors"
 	"wallet_api/internal/entity"
 	"wallet_api/internal/module/account/repository"
+	currencyusecase "wallet_api/internal/module/currency/usecase"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
@@ -16,49 +22,148 @@ import (
 
 type UseCase interface {
 	CreateWallet(ctx context.Context, userID uuid.UUID, walletName, currency string) (*entity.Wallet, error)
-	GetWallet(ctx context.Context, walletID uuid.UUID) (*entity.Wallet, error)
+	GetWallet(ctx context.Context, userID, walletID uuid.UUID) (*entity.Wallet, error)
 	GetUserWallets(ctx context.Context, userID uuid.UUID) ([]*entity.Wallet, error)
-	Deposit(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal, description string) error
-	Withdraw(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal, description string) error
-	Transfer(ctx context.Context, fromWalletID, toWalletID uuid.UUID, amount decimal.Decimal, description string) error
-	GetTransactions(ctx context.Context, walletID uuid.UUID, limit, offset int) ([]*entity.Transaction, error)
+	// referenceID is stored on the transactions, empty generates a new one
+	Deposit(ctx context.Context, userID, walletID uuid.UUID, amount decimal.Decimal, description, referenceID string) error
+	Withdraw(ctx context.Context, userID, walletID uuid.UUID, amount decimal.Decimal, description string, proof StepUpProof, referenceID string) error
+	Transfer(ctx context.Context, userID, fromWalletID, toWalletID uuid.UUID, amount decimal.Decimal, description string, proof StepUpProof, referenceID string) error
+	GetTransactions(ctx context.Context, userID, walletID uuid.UUID, limit, offset int) ([]*entity.Transaction, error)
+	// AuthorizeOutgoing runs the withdraw/transfer checks without moving money, for callers that move it later
+	AuthorizeOutgoing(ctx context.Context, userID, walletID uuid.UUID, amount decimal.Decimal, proof StepUpProof) error
+	// ConvertTransfer moves money between wallets of different currencies at a priced conversion
+	ConvertTransfer(ctx context.Context, userID, fromWalletID, toWalletID uuid.UUID, conversion Conversion, description string, proof StepUpProof, referenceID string) error
+
+	// Holds reserve money until they are captured into a withdrawal or transfer, voided or expire
+	CreateHold(ctx context.Context, userID, walletID uuid.UUID, amount decimal.Decimal, description string, ttl time.Duration, proof StepUpProof, referenceID string) (*entity.Hold, error)
+	GetHold(ctx context.Context, userID, walletID, holdID uuid.UUID) (*entity.Hold, error)
+	GetHolds(ctx context.Context, userID, walletID uuid.UUID, limit, offset int) ([]*entity.Hold, error)
+	CaptureHold(ctx context.Context, userID, walletID, holdID uuid.UUID, capture HoldCapture) (*entity.Hold, error)
+	VoidHold(ctx context.Context, userID, walletID, holdID uuid.UUID) (*entity.Hold, error)
+
+	// Admin operations, these skip the ownership checks
+	FindWallet(ctx context.Context, walletID uuid.UUID) (*entity.Wallet, error)
+	FindTransactions(ctx context.Context, walletID uuid.UUID, limit, offset int) ([]*entity.Transaction, error)
+	SetWalletStatus(ctx context.Context, walletID uuid.UUID, status string) (*entity.Wallet, error)
+}
+
+var (
+	errWalletNotFound      = errors.New(404, "Wallet not found", nil)
+	errWalletNotActive     = errors.New(400, "Wallet is not active", nil)
+	errEmailNotVerified    = errors.New(403, "Verify your email address before withdrawing or transferring funds", nil)
+	errInsufficientBalance = errors.New(400, "Insufficient balance", nil)
+)
+
+// EmailVerification is the part of the user module used to gate outgoing money movement
+type EmailVerification interface {
+	IsEmailVerified(ctx context.Context, userID uuid.UUID) (bool, error)
+}
+
+// StepUp is the part of the user module that checks a transaction PIN or step-up token
+type StepUp interface {
+	VerifyStepUp(ctx context.Context, userID uuid.UUID, pin, stepUpToken string) error
+}
+
+// Currencies is the registry the wallet currency and the precision of every amount are checked against
+type Currencies interface {
+	Lookup(ctx context.Context, code string) (*entity.Currency, error)
+}
+
+// StepUpProof is what the caller presents for amounts above the step-up threshold
+type StepUpProof struct {
+	PIN         string
+	StepUpToken string
+	// Set by internal callers whose PIN check already happened, e.g. a transfer schedule
+	// verified when it was created. Never filled from a request.
+	Preauthorized bool
 }
 
 type useCase struct {
 	walletRepo      repository.WalletRepository
 	transactionRepo repository.TransactionRepository
+	holdRepo        repository.HoldRepository
+	// Money movement runs in a unit of work, walletRepo and transactionRepo are for reads outside it
+	uow        repository.UnitOfWork
+	users      EmailVerification
+	stepUp     StepUp
+	currencies Currencies
+	// Per currency, amounts above it need a PIN or step-up token
+	stepUpThresholds map[string]decimal.Decimal
+	holdLimits       HoldLimits
 }
 
-func New(walletRepo repository.WalletRepository, transactionRepo repository.TransactionRepository) UseCase {
+func New(
+	walletRepo repository.WalletRepository,
+	transactionRepo repository.TransactionRepository,
+	holdRepo repository.HoldRepository,
+	uow repository.UnitOfWork,
+	users EmailVerification,
+	stepUp StepUp,
+	currencies Currencies,
+	stepUpThresholds map[string]decimal.Decimal,
+	holdLimits HoldLimits,
+) UseCase {
 	return &useCase{
-		walletRepo:      walletRepo,
-		transactionRepo: transactionRepo,
+		walletRepo:       walletRepo,
+		transactionRepo:  transactionRepo,
+		holdRepo:         holdRepo,
+		uow:              uow,
+		users:            users,
+		stepUp:           stepUp,
+		currencies:       currencies,
+		stepUpThresholds: stepUpThresholds,
+		holdLimits:       holdLimits,
 	}
 }
 
 func (uc *useCase) CreateWallet(ctx context.Context, userID uuid.UUID, walletName, currency string) (*entity.Wallet, error) {
+	// An empty currency keeps the column default
+	if currency == "" {
+		currency = consts.DefaultCurrency
+	}
+
+	registered, err := uc.currencies.Lookup(ctx, currency)
+	if err != nil {
+		return nil, err
+	}
+	if !registered.Enabled {
+		return nil, currencyusecase.ErrCurrencyDisabled
+	}
+
 	wallet := &entity.Wallet{
 		UserID:     userID,
 		WalletName: walletName,
 		Balance:    decimal.Zero,
-		Currency:   currency,
+		Currency:   registered.Code,
 		Status:     consts.WalletStatusActive,
 	}
 
-	if err := uc.walletRepo.Create(ctx, wallet); err != nil {
-		return nil, fmt.Errorf("failed to create wallet: %w", err)
+	err = uc.uow.Do(ctx, func(repos *repository.Repositories) error {
+		if err := repos.Wallets.Create(ctx, wallet); err != nil {
+			return fmt.Errorf("failed to create wallet: %w", err)
+		}
+
+		if _, err := repos.Ledger.WalletAccount(ctx, wallet); err != nil {
+			return fmt.Errorf("failed to create wallet ledger account: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return wallet, nil
 }
 
-func (uc *useCase) GetWallet(ctx context.Context, walletID uuid.UUID) (*entity.Wallet, error) {
+func (uc *useCase) GetWallet(ctx context.Context, userID, walletID uuid.UUID) (*entity.Wallet, error) {
 	wallet, err := uc.walletRepo.FindByID(ctx, walletID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get wallet: %w", err)
+	if err := authorizeWallet(wallet, err, userID); err != nil {
+		return nil, err
 	}
-	if wallet == nil {
-		return nil, errors.ErrNotFound
+
+	if err := uc.loadHeldBalances(ctx, wallet); err != nil {
+		return nil, err
 	}
 
 	return wallet, nil
@@ -70,103 +175,106 @@ func (uc *useCase) GetUserWallets(ctx context.Context, userID uuid.UUID) ([]*ent
 		return nil, fmt.Errorf("failed to get user wallets: %w", err)
 	}
 
+	if err := uc.loadHeldBalances(ctx, wallets...); err != nil {
+		return nil, err
+	}
+
 	return wallets, nil
 }
 
-func (uc *useCase) Deposit(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal, description string) error {
+func (uc *useCase) Deposit(ctx context.Context, userID, walletID uuid.UUID, amount decimal.Decimal, description, referenceID string) error {
 	if amount.LessThanOrEqual(decimal.Zero) {
 		return errors.ErrBadRequest
 	}
 
-	return uc.walletRepo.WithTransaction(ctx, func(tx *gorm.DB) error {
+	referenceID = newReferenceID(referenceID)
+
+	err := uc.uow.Do(ctx, func(repos *repository.Repositories) error {
 		// Get wallet with pessimistic locking
-		wallet, err := uc.walletRepo.FindByIDForUpdate(ctx, walletID)
-		if err != nil {
-			return fmt.Errorf("failed to get wallet: %w", err)
+		wallet, err := repos.Wallets.FindByIDForUpdate(ctx, walletID)
+		if err := authorizeWallet(wallet, err, userID); err != nil {
+			return err
 		}
-		if wallet == nil {
-			return errors.ErrNotFound
+
+		if wallet.Status != consts.WalletStatusActive {
+			return errWalletNotActive
+		}
+
+		if err := uc.checkAmount(ctx, wallet.Currency, amount, true); err != nil {
+			return err
 		}
 
 		// Calculate balance before and after
 		balanceBefore := wallet.Balance
 		balanceAfter := wallet.Balance.Add(amount)
 
+		// Money enters the platform through cash_in
+		entry, err := (&ledger{repo: repos.Ledger}).post(ctx, consts.TransactionTypeDeposit, referenceID, description, wallet.Currency, amount,
+			systemLeg(consts.LedgerAccountCashIn), walletLeg(wallet))
+		if err != nil {
+			return err
+		}
+
 		// Update balance
 		wallet.Balance = balanceAfter
-		if err := uc.walletRepo.Update(ctx, wallet); err != nil {
+		if err := repos.Wallets.Update(ctx, wallet); err != nil {
 			return fmt.Errorf("failed to update wallet: %w", err)
 		}
 
 		// Create transaction
 		transaction := &entity.Transaction{
-			WalletID:      walletID,
-			ReferenceID:   uuid.New().String(),
-			Type:          consts.TransactionTypeDeposit,
-			Amount:        amount,
-			BalanceBefore: balanceBefore,
-			BalanceAfter:  balanceAfter,
-			Description:   description,
+			WalletID:       walletID,
+			ReferenceID:    referenceID,
+			JournalEntryID: &entry.ID,
+			Type:           consts.TransactionTypeDeposit,
+			Amount:         amount,
+			BalanceBefore:  balanceBefore,
+			BalanceAfter:   balanceAfter,
+			Description:    description,
 		}
 
-		if err := uc.transactionRepo.Create(ctx, transaction); err != nil {
+		if err := repos.Transactions.Create(ctx, transaction); err != nil {
 			return fmt.Errorf("failed to create transaction: %w", err)
 		}
 
 		return nil
 	})
+	return uc.alreadyApplied(ctx, walletID, referenceID, err)
 }
 
-func (uc *useCase) Withdraw(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal, description string) error {
+func (uc *useCase) Withdraw(ctx context.Context, userID, walletID uuid.UUID, amount decimal.Decimal, description string, proof StepUpProof, referenceID string) error {
 	if amount.LessThanOrEqual(decimal.Zero) {
 		return errors.ErrBadRequest
 	}
 
-	return uc.walletRepo.WithTransaction(ctx, func(tx *gorm.DB) error {
-		// Get wallet with pessimistic locking
-		wallet, err := uc.walletRepo.FindByIDForUpdate(ctx, walletID)
-		if err != nil {
-			return fmt.Errorf("failed to get wallet: %w", err)
-		}
-		if wallet == nil {
-			return errors.ErrNotFound
-		}
-
-		// Check balance
-		if wallet.Balance.LessThan(amount) {
-			return errors.New(400, "Insufficient balance", nil)
-		}
+	if err := uc.authorizeOutgoing(ctx, userID, walletID, amount, proof); err != nil {
+		return err
+	}
 
-		// Calculate balance before and after
-		balanceBefore := wallet.Balance
-		balanceAfter := wallet.Balance.Sub(amount)
+	referenceID = newReferenceID(referenceID)
 
-		// Update balance
-		wallet.Balance = balanceAfter
-		if err := uc.walletRepo.Update(ctx, wallet); err != nil {
-			return fmt.Errorf("failed to update wallet: %w", err)
+	err := uc.uow.Do(ctx, func(repos *repository.Repositories) error {
+		// Get wallet with pessimistic locking
+		wallet, err := repos.Wallets.FindByIDForUpdate(ctx, walletID)
+		if err := authorizeWallet(wallet, err, userID); err != nil {
+			return err
 		}
 
-		// Create transaction
-		transaction := &entity.Transaction{
-			WalletID:      walletID,
-			ReferenceID:   uuid.New().String(),
-			Type:          consts.TransactionTypeWithdrawal,
-			Amount:        amount,
-			BalanceBefore: balanceBefore,
-			BalanceAfter:  balanceAfter,
-			Description:   description,
+		if wallet.Status != consts.WalletStatusActive {
+			return errWalletNotActive
 		}
 
-		if err := uc.transactionRepo.Create(ctx, transaction); err != nil {
-			return fmt.Errorf("failed to create transaction: %w", err)
+		// Held money is reserved, only the available balance can leave
+		if err := requireAvailable(ctx, repos, wallet, amount, nil); err != nil {
+			return err
 		}
 
-		return nil
+		return withdraw(ctx, repos, wallet, amount, description, referenceID)
 	})
+	return uc.alreadyApplied(ctx, walletID, referenceID, err)
 }
 
-func (uc *useCase) Transfer(ctx context.Context, fromWalletID, toWalletID uuid.UUID, amount decimal.Decimal, description string) error {
+func (uc *useCase) Transfer(ctx context.Context, userID, fromWalletID, toWalletID uuid.UUID, amount decimal.Decimal, description string, proof StepUpProof, referenceID string) error {
 	if amount.LessThanOrEqual(decimal.Zero) {
 		return errors.ErrBadRequest
 	}
@@ -175,102 +283,406 @@ func (uc *useCase) Transfer(ctx context.Context, fromWalletID, toWalletID uuid.U
 		return errors.New(400, "Cannot transfer to the same wallet", nil)
 	}
 
-	referenceID := uuid.New().String()
+	if err := uc.authorizeOutgoing(ctx, userID, fromWalletID, amount, proof); err != nil {
+		return err
+	}
 
-	return uc.walletRepo.WithTransaction(ctx, func(tx *gorm.DB) error {
-		fromWallet, err := uc.walletRepo.FindByIDForUpdate(ctx, fromWalletID)
-		if err != nil {
-			return fmt.Errorf("failed to get from wallet: %w", err)
-		}
-		if fromWallet == nil {
-			return errors.New(404, "Source wallet not found", nil)
-		}
+	referenceID = newReferenceID(referenceID)
 
-		toWallet, err := uc.walletRepo.FindByIDForUpdate(ctx, toWalletID)
+	err := uc.uow.Do(ctx, func(repos *repository.Repositories) error {
+		// Lock both wallets in ID order, so opposite transfers between them cannot deadlock
+		locked, err := lockWallets(ctx, repos.Wallets, fromWalletID, toWalletID)
 		if err != nil {
-			return fmt.Errorf("failed to get to wallet: %w", err)
-		}
-		if toWallet == nil {
-			return errors.New(404, "Destination wallet not found", nil)
+			return err
 		}
 
-		if fromWallet.Status != consts.WalletStatusActive {
-			return errors.New(400, "Source wallet is not active", nil)
+		fromWallet := locked[fromWalletID]
+		if err := authorizeWallet(fromWallet, nil, userID); err != nil {
+			return err
 		}
 
-		if toWallet.Status != consts.WalletStatusActive {
-			return errors.New(400, "Destination wallet is not active", nil)
+		if err := checkTransfer(fromWallet, locked[toWalletID]); err != nil {
+			return err
 		}
 
-		if fromWallet.Currency != toWallet.Currency {
-			return errors.New(400, "Cannot transfer between different currencies", nil)
+		if err := requireAvailable(ctx, repos, fromWallet, amount, nil); err != nil {
+			return err
 		}
 
-		if fromWallet.Balance.LessThan(amount) {
-			return errors.New(400, "Insufficient balance", nil)
-		}
+		return transfer(ctx, repos, fromWallet, locked[toWalletID], amount, description, referenceID)
+	})
+	return uc.alreadyApplied(ctx, fromWalletID, referenceID, err)
+}
 
-		// Calculate balances for from wallet
-		fromBalanceBefore := fromWallet.Balance
-		fromBalanceAfter := fromWallet.Balance.Sub(amount)
-		fromWallet.Balance = fromBalanceAfter
+func (uc *useCase) GetTransactions(ctx context.Context, userID, walletID uuid.UUID, limit, offset int) ([]*entity.Transaction, error) {
+	if _, err := uc.GetWallet(ctx, userID, walletID); err != nil {
+		return nil, err
+	}
 
-		// Calculate balances for to wallet
-		toBalanceBefore := toWallet.Balance
-		toBalanceAfter := toWallet.Balance.Add(amount)
-		toWallet.Balance = toBalanceAfter
+	return uc.listTransactions(ctx, walletID, limit, offset)
+}
 
-		if err := uc.walletRepo.Update(ctx, fromWallet); err != nil {
-			return fmt.Errorf("failed to update from wallet: %w", err)
-		}
+func (uc *useCase) AuthorizeOutgoing(ctx context.Context, userID, walletID uuid.UUID, amount decimal.Decimal, proof StepUpProof) error {
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return errors.ErrBadRequest
+	}
 
-		if err := uc.walletRepo.Update(ctx, toWallet); err != nil {
-			return fmt.Errorf("failed to update to wallet: %w", err)
-		}
+	return uc.authorizeOutgoing(ctx, userID, walletID, amount, proof)
+}
 
-		withdrawalTx := &entity.Transaction{
-			WalletID:      fromWalletID,
-			ReferenceID:   referenceID,
-			Type:          consts.TransactionTypeTransfer,
-			Amount:        amount,
-			BalanceBefore: fromBalanceBefore,
-			BalanceAfter:  fromBalanceAfter,
-			Description:   fmt.Sprintf("Transfer to wallet %s", toWalletID),
-		}
-		if description != "" {
-			withdrawalTx.Description = fmt.Sprintf("%s - %s", description, withdrawalTx.Description)
-		}
+func (uc *useCase) FindWallet(ctx context.Context, walletID uuid.UUID) (*entity.Wallet, error) {
+	wallet, err := uc.walletRepo.FindByID(ctx, walletID)
+	if err != nil {
+		return nil, walletLookupError(err)
+	}
+
+	if err := uc.loadHeldBalances(ctx, wallet); err != nil {
+		return nil, err
+	}
+
+	return wallet, nil
+}
+
+func (uc *useCase) FindTransactions(ctx context.Context, walletID uuid.UUID, limit, offset int) ([]*entity.Transaction, error) {
+	if _, err := uc.FindWallet(ctx, walletID); err != nil {
+		return nil, err
+	}
+
+	return uc.listTransactions(ctx, walletID, limit, offset)
+}
+
+func (uc *useCase) SetWalletStatus(ctx context.Context, walletID uuid.UUID, status string) (*entity.Wallet, error) {
+	if status != consts.WalletStatusActive && status != consts.WalletStatusFrozen {
+		return nil, errors.New(400, "Invalid wallet status", nil)
+	}
+
+	wallet, err := uc.FindWallet(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.walletRepo.UpdateFields(ctx, walletID, map[string]interface{}{"status": status}); err != nil {
+		return nil, fmt.Errorf("failed to update wallet status: %w", err)
+	}
+	wallet.Status = status
+
+	return wallet, nil
+}
+
+func (uc *useCase) listTransactions(ctx context.Context, walletID uuid.UUID, limit, offset int) ([]*entity.Transaction, error) {
+	transactions, err := uc.transactionRepo.FindByWalletID(ctx, walletID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+
+	return transactions, nil
+}
+
+// loadHeldBalances fills HeldBalance, so responses can show the available balance
+func (uc *useCase) loadHeldBalances(ctx context.Context, wallets ...*entity.Wallet) error {
+	walletIDs := make([]uuid.UUID, len(wallets))
+	for i, wallet := range wallets {
+		walletIDs[i] = wallet.ID
+	}
+
+	held, err := uc.holdRepo.HeldAmounts(ctx, walletIDs, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to sum holds: %w", err)
+	}
 
-		if err := uc.transactionRepo.Create(ctx, withdrawalTx); err != nil {
-			return fmt.Errorf("failed to create withdrawal transaction: %w", err)
+	for _, wallet := range wallets {
+		wallet.HeldBalance = held[wallet.ID]
+	}
+	return nil
+}
+
+// lockWallets locks the wallets with SELECT FOR UPDATE in ascending ID order. Every caller
+// taking more than one wallet lock goes through here. Missing wallets are left out of the map.
+func lockWallets(ctx context.Context, walletRepo repository.WalletRepository, walletIDs ...uuid.UUID) (map[uuid.UUID]*entity.Wallet, error) {
+	ordered := append([]uuid.UUID(nil), walletIDs...)
+	sort.Slice(ordered, func(i, j int) bool {
+		return bytes.Compare(ordered[i][:], ordered[j][:]) < 0
+	})
+
+	locked := make(map[uuid.UUID]*entity.Wallet, len(ordered))
+	for _, walletID := range ordered {
+		if _, ok := locked[walletID]; ok {
+			continue
 		}
 
-		depositTx := &entity.Transaction{
-			WalletID:      toWalletID,
-			ReferenceID:   referenceID,
-			Type:          consts.TransactionTypeTransfer,
-			Amount:        amount,
-			BalanceBefore: toBalanceBefore,
-			BalanceAfter:  toBalanceAfter,
-			Description:   fmt.Sprintf("Transfer from wallet %s", fromWalletID),
+		wallet, err := walletRepo.FindByIDForUpdate(ctx, walletID)
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			continue
 		}
-		if description != "" {
-			depositTx.Description = fmt.Sprintf("%s - %s", description, depositTx.Description)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock wallet: %w", err)
 		}
+		locked[walletID] = wallet
+	}
+
+	return locked, nil
+}
+
+// withdraw moves amount out of a locked wallet through cash_out. The caller has done the checks.
+func withdraw(ctx context.Context, repos *repository.Repositories, wallet *entity.Wallet, amount decimal.Decimal, description, referenceID string) error {
+	// Calculate balance before and after
+	balanceBefore := wallet.Balance
+	balanceAfter := wallet.Balance.Sub(amount)
+
+	// Money leaves the platform through cash_out
+	entry, err := (&ledger{repo: repos.Ledger}).post(ctx, consts.TransactionTypeWithdrawal, referenceID, description, wallet.Currency, amount,
+		walletLeg(wallet), systemLeg(consts.LedgerAccountCashOut))
+	if err != nil {
+		return err
+	}
+
+	// Update balance
+	wallet.Balance = balanceAfter
+	if err := repos.Wallets.Update(ctx, wallet); err != nil {
+		return fmt.Errorf("failed to update wallet: %w", err)
+	}
+
+	// Create transaction
+	transaction := &entity.Transaction{
+		WalletID:       wallet.ID,
+		ReferenceID:    referenceID,
+		JournalEntryID: &entry.ID,
+		Type:           consts.TransactionTypeWithdrawal,
+		Amount:         amount,
+		BalanceBefore:  balanceBefore,
+		BalanceAfter:   balanceAfter,
+		Description:    description,
+	}
+
+	if err := repos.Transactions.Create(ctx, transaction); err != nil {
+		return fmt.Errorf("failed to create transaction: %w", err)
+	}
+
+	return nil
+}
+
+// checkTransfer validates the two locked wallets of a transfer, toWallet is nil when it does not exist
+func checkTransfer(fromWallet, toWallet *entity.Wallet) error {
+	if err := checkTransferStatus(fromWallet, toWallet); err != nil {
+		return err
+	}
+
+	if fromWallet.Currency != toWallet.Currency {
+		return errors.New(400, "Cannot transfer between different currencies", nil)
+	}
+
+	return nil
+}
 
-		if err := uc.transactionRepo.Create(ctx, depositTx); err != nil {
-			return fmt.Errorf("failed to create deposit transaction: %w", err)
+// checkTransferStatus checks that both sides of a transfer exist and are active
+func checkTransferStatus(fromWallet, toWallet *entity.Wallet) error {
+	if toWallet == nil {
+		return errors.New(404, "Destination wallet not found", nil)
+	}
+
+	if fromWallet.Status != consts.WalletStatusActive {
+		return errors.New(400, "Source wallet is not active", nil)
+	}
+
+	if toWallet.Status != consts.WalletStatusActive {
+		return errors.New(400, "Destination wallet is not active", nil)
+	}
+
+	return nil
+}
+
+// transfer moves amount between two locked wallets. Both legs share the reference and one
+// journal entry. The caller has done the checks.
+func transfer(ctx context.Context, repos *repository.Repositories, fromWallet, toWallet *entity.Wallet, amount decimal.Decimal, description, referenceID string) error {
+	entry, err := (&ledger{repo: repos.Ledger}).post(ctx, consts.TransactionTypeTransfer, referenceID, description, fromWallet.Currency, amount,
+		walletLeg(fromWallet), walletLeg(toWallet))
+	if err != nil {
+		return err
+	}
+
+	// Calculate balances for from wallet
+	fromBalanceBefore := fromWallet.Balance
+	fromBalanceAfter := fromWallet.Balance.Sub(amount)
+	fromWallet.Balance = fromBalanceAfter
+
+	// Calculate balances for to wallet
+	toBalanceBefore := toWallet.Balance
+	toBalanceAfter := toWallet.Balance.Add(amount)
+	toWallet.Balance = toBalanceAfter
+
+	if err := repos.Wallets.Update(ctx, fromWallet); err != nil {
+		return fmt.Errorf("failed to update from wallet: %w", err)
+	}
+
+	if err := repos.Wallets.Update(ctx, toWallet); err != nil {
+		return fmt.Errorf("failed to update to wallet: %w", err)
+	}
+
+	withdrawalTx := &entity.Transaction{
+		WalletID:       fromWallet.ID,
+		ReferenceID:    referenceID,
+		JournalEntryID: &entry.ID,
+		Type:           consts.TransactionTypeTransfer,
+		Amount:         amount,
+		BalanceBefore:  fromBalanceBefore,
+		BalanceAfter:   fromBalanceAfter,
+		Description:    fmt.Sprintf("Transfer to wallet %s", toWallet.ID),
+	}
+	if description != "" {
+		withdrawalTx.Description = fmt.Sprintf("%s - %s", description, withdrawalTx.Description)
+	}
+
+	if err := repos.Transactions.Create(ctx, withdrawalTx); err != nil {
+		return fmt.Errorf("failed to create withdrawal transaction: %w", err)
+	}
+
+	depositTx := &entity.Transaction{
+		WalletID:       toWallet.ID,
+		ReferenceID:    referenceID,
+		JournalEntryID: &entry.ID,
+		Type:           consts.TransactionTypeTransfer,
+		Amount:         amount,
+		BalanceBefore:  toBalanceBefore,
+		BalanceAfter:   toBalanceAfter,
+		Description:    fmt.Sprintf("Transfer from wallet %s", fromWallet.ID),
+	}
+	if description != "" {
+		depositTx.Description = fmt.Sprintf("%s - %s", description, depositTx.Description)
+	}
+
+	if err := repos.Transactions.Create(ctx, depositTx); err != nil {
+		return fmt.Errorf("failed to create deposit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// requireAvailable checks amount against the balance of a locked wallet minus its active holds.
+// except is left out of the sum, it is the hold being captured.
+func requireAvailable(ctx context.Context, repos *repository.Repositories, wallet *entity.Wallet, amount decimal.Decimal, except *entity.Hold) error {
+	now := time.Now()
+	held, err := repos.Holds.HeldAmounts(ctx, []uuid.UUID{wallet.ID}, now)
+	if err != nil {
+		return fmt.Errorf("failed to sum holds: %w", err)
+	}
+
+	wallet.HeldBalance = held[wallet.ID]
+	if except != nil && except.Active(now) {
+		wallet.HeldBalance = wallet.HeldBalance.Sub(except.Amount)
+	}
+
+	if wallet.AvailableBalance().LessThan(amount) {
+		return errInsufficientBalance
+	}
+
+	return nil
+}
+
+// authorizeWallet checks the result of a wallet lookup against the caller.
+// A wallet owned by another user is reported exactly like a missing one so
+// the endpoint cannot be used to probe for wallet IDs.
+func authorizeWallet(wallet *entity.Wallet, err error, userID uuid.UUID) error {
+	if err != nil {
+		return walletLookupError(err)
+	}
+	if wallet == nil || wallet.UserID != userID {
+		return errWalletNotFound
+	}
+
+	return nil
+}
+
+// IsDuplicateReference reports a transaction insert that collided with one already stored
+// under the same reference for the wallet
+func IsDuplicateReference(err error) bool {
+	return base.IsUniqueViolationOf(err, repository.TransactionReferenceConstraint)
+}
+
+// alreadyApplied turns a duplicate reference into success once the committed transaction is found.
+// A retry that took over an Idempotency-Key whose first run did commit ends up here.
+func (uc *useCase) alreadyApplied(ctx context.Context, walletID uuid.UUID, referenceID string, err error) error {
+	if !IsDuplicateReference(err) {
+		return err
+	}
+
+	transactions, findErr := uc.transactionRepo.FindByReferenceID(ctx, referenceID)
+	if findErr != nil {
+		return fmt.Errorf("failed to find transaction %s: %w", referenceID, findErr)
+	}
+	for _, transaction := range transactions {
+		if transaction.WalletID == walletID {
+			return nil
 		}
+	}
+
+	return err
+}
 
+// newReferenceID keeps the caller's reference, e.g. a claimed Idempotency-Key, so a replayed
+// request collides on the (wallet_id, reference_id) constraint instead of posting twice
+func newReferenceID(referenceID string) string {
+	if referenceID != "" {
+		return referenceID
+	}
+	return uuid.New().String()
+}
+
+func walletLookupError(err error) error {
+	if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+		return errWalletNotFound
+	}
+	return fmt.Errorf("failed to get wallet: %w", err)
+}
+
+// authorizeOutgoing runs the checks for money leaving a wallet: ownership, the precision
+// of the amount, a verified email and, above the currency threshold, a PIN or step-up
+// token. It runs before the wallet row is locked so a slow PIN hash does not hold the lock.
+func (uc *useCase) authorizeOutgoing(ctx context.Context, userID, walletID uuid.UUID, amount decimal.Decimal, proof StepUpProof) error {
+	wallet, err := uc.walletRepo.FindByID(ctx, walletID)
+	if err := authorizeWallet(wallet, err, userID); err != nil {
+		return err
+	}
+
+	if err := uc.checkAmount(ctx, wallet.Currency, amount, false); err != nil {
+		return err
+	}
+
+	verified, err := uc.users.IsEmailVerified(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to check email verification: %w", err)
+	}
+	if !verified {
+		return errEmailNotVerified
+	}
+
+	if proof.Preauthorized || !uc.requiresStepUp(wallet.Currency, amount) {
 		return nil
-	})
+	}
+
+	return uc.stepUp.VerifyStepUp(ctx, userID, proof.PIN, proof.StepUpToken)
 }
 
-func (uc *useCase) GetTransactions(ctx context.Context, walletID uuid.UUID, limit, offset int) ([]*entity.Transaction, error) {
-	transactions, err := uc.transactionRepo.FindByWalletID(ctx, walletID, limit, offset)
+// checkAmount checks amount against the minor unit of the currency. Money can still leave
+// a wallet whose currency was disabled, only incoming money needs it enabled.
+func (uc *useCase) checkAmount(ctx context.Context, code string, amount decimal.Decimal, incoming bool) error {
+	currency, err := uc.currencies.Lookup(ctx, code)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get transactions: %w", err)
+		return err
+	}
+	if incoming && !currency.Enabled {
+		return currencyusecase.ErrCurrencyDisabled
 	}
 
-	return transactions, nil
+	return currencyusecase.CheckAmount(currency, amount)
+}
+
+// requiresStepUp reports whether an amount is above the threshold of its currency.
+// Currencies without a configured threshold always require it.
+func (uc *useCase) requiresStepUp(currency string, amount decimal.Decimal) bool {
+	threshold, ok := uc.stepUpThresholds[currency]
+	if !ok {
+		return true
+	}
+
+	return amount.GreaterThan(threshold)
 }
@@ -0,0 +1,345 @@
+package accountusecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"wallet_api/internal/common/consts"
+	"wallet_api/internal/entity"
+	"wallet_api/internal/module/account/repository"
+	currencyusecase "wallet_api/internal/module/currency/usecase"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+func TestRequiresStepUp(t *testing.T) {
+	uc := &useCase{stepUpThresholds: map[string]decimal.Decimal{
+		"IDR": decimal.NewFromInt(1000000),
+	}}
+
+	tests := []struct {
+		currency string
+		amount   string
+		want     bool
+	}{
+		{currency: "IDR", amount: "999999.99", want: false},
+		{currency: "IDR", amount: "1000000", want: false},
+		{currency: "IDR", amount: "1000000.01", want: true},
+		// No threshold configured, always ask
+		{currency: "EUR", amount: "0.01", want: true},
+	}
+
+	for _, tt := range tests {
+		if got := uc.requiresStepUp(tt.currency, decimal.RequireFromString(tt.amount)); got != tt.want {
+			t.Errorf("requiresStepUp(%s, %s) = %v, want %v", tt.currency, tt.amount, got, tt.want)
+		}
+	}
+}
+
+// lockRecorder records the order wallets are locked in
+type lockRecorder struct {
+	repository.WalletRepository
+	wallets map[uuid.UUID]*entity.Wallet
+	locked  []uuid.UUID
+}
+
+func (r *lockRecorder) FindByIDForUpdate(_ context.Context, id uuid.UUID) (*entity.Wallet, error) {
+	r.locked = append(r.locked, id)
+	wallet, ok := r.wallets[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return wallet, nil
+}
+
+func TestLockWalletsLocksInIDOrder(t *testing.T) {
+	low := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	high := uuid.MustParse("ffffffff-0000-0000-0000-000000000000")
+	missing := uuid.MustParse("80000000-0000-0000-0000-000000000000")
+
+	repo := &lockRecorder{wallets: map[uuid.UUID]*entity.Wallet{
+		low:  {ID: low},
+		high: {ID: high},
+	}}
+
+	// Both directions of a transfer must take the locks in the same order
+	for _, ids := range [][]uuid.UUID{{low, high}, {high, low}} {
+		repo.locked = nil
+		locked, err := lockWallets(context.Background(), repo, ids...)
+		if err != nil {
+			t.Fatalf("lockWallets() error = %v", err)
+		}
+		if len(repo.locked) != 2 || repo.locked[0] != low || repo.locked[1] != high {
+			t.Errorf("lock order = %v, want [%s %s]", repo.locked, low, high)
+		}
+		if locked[low] == nil || locked[high] == nil {
+			t.Errorf("lockWallets() = %v, want both wallets", locked)
+		}
+	}
+
+	repo.locked = nil
+	locked, err := lockWallets(context.Background(), repo, high, missing)
+	if err != nil {
+		t.Fatalf("lockWallets() error = %v", err)
+	}
+	if _, ok := locked[missing]; ok || locked[high] == nil {
+		t.Errorf("lockWallets() = %v, want only the existing wallet", locked)
+	}
+}
+
+// txWallets is a wallet repository bound to the fake transaction
+type txWallets struct {
+	repository.WalletRepository
+	wallet *entity.Wallet
+}
+
+func (r *txWallets) FindByIDForUpdate(_ context.Context, id uuid.UUID) (*entity.Wallet, error) {
+	if id != r.wallet.ID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.wallet, nil
+}
+
+func (r *txWallets) Create(_ context.Context, wallet *entity.Wallet) error {
+	wallet.ID = uuid.New()
+	r.wallet = wallet
+	return nil
+}
+
+func (r *txWallets) Update(_ context.Context, wallet *entity.Wallet) error {
+	r.wallet = wallet
+	return nil
+}
+
+type txTransactions struct {
+	repository.TransactionRepository
+	created []*entity.Transaction
+	// Returned by Create when set
+	err error
+}
+
+func (r *txTransactions) Create(_ context.Context, transaction *entity.Transaction) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.created = append(r.created, transaction)
+	return nil
+}
+
+func (r *txTransactions) FindByReferenceID(_ context.Context, referenceID string) ([]*entity.Transaction, error) {
+	var found []*entity.Transaction
+	for _, transaction := range r.created {
+		if transaction.ReferenceID == referenceID {
+			found = append(found, transaction)
+		}
+	}
+	return found, nil
+}
+
+// uniqueViolation names the constraint in the message like the Postgres driver does
+type uniqueViolation string
+
+func (e uniqueViolation) Error() string {
+	return `ERROR: duplicate key value violates unique constraint "` + string(e) + `" (SQLSTATE 23505)`
+}
+func (e uniqueViolation) SQLState() string { return "23505" }
+
+type txLedger struct {
+	repository.LedgerRepository
+	posted []*entity.JournalEntry
+}
+
+func (r *txLedger) WalletAccount(_ context.Context, wallet *entity.Wallet) (*entity.LedgerAccount, error) {
+	return &entity.LedgerAccount{ID: uuid.New(), WalletID: &wallet.ID}, nil
+}
+
+func (r *txLedger) SystemAccount(_ context.Context, code, currency string) (*entity.LedgerAccount, error) {
+	return &entity.LedgerAccount{ID: uuid.New(), Code: &code, Currency: currency}, nil
+}
+
+func (r *txLedger) Post(_ context.Context, entry *entity.JournalEntry) error {
+	r.posted = append(r.posted, entry)
+	return nil
+}
+
+// registry has the currencies of the tests, XAU is registered but disabled
+type registry map[string]*entity.Currency
+
+func (r registry) Lookup(_ context.Context, code string) (*entity.Currency, error) {
+	currency, ok := r[currencyusecase.Normalize(code)]
+	if !ok {
+		return nil, currencyusecase.ErrUnsupportedCurrency
+	}
+	return currency, nil
+}
+
+var currencies = registry{
+	"IDR": {Code: "IDR", Exponent: 2, Enabled: true},
+	"JPY": {Code: "JPY", Exponent: 0, Enabled: true},
+	"KWD": {Code: "KWD", Exponent: 3, Enabled: true},
+	"XAU": {Code: "XAU", Exponent: 2, Enabled: false},
+}
+
+// fakeUnitOfWork hands the same tx-bound repositories to every callback
+type fakeUnitOfWork struct {
+	repos *repository.Repositories
+	calls int
+}
+
+func (u *fakeUnitOfWork) Do(_ context.Context, fn func(repos *repository.Repositories) error) error {
+	u.calls++
+	return fn(u.repos)
+}
+
+func TestDepositRunsInUnitOfWork(t *testing.T) {
+	userID := uuid.New()
+	wallet := &entity.Wallet{ID: uuid.New(), UserID: userID, Balance: decimal.NewFromInt(10), Currency: "IDR", Status: consts.WalletStatusActive}
+
+	wallets := &txWallets{wallet: wallet}
+	transactions := &txTransactions{}
+	ledgerRepo := &txLedger{}
+	uow := &fakeUnitOfWork{repos: &repository.Repositories{Wallets: wallets, Transactions: transactions, Ledger: ledgerRepo}}
+
+	// The root repositories are nil interfaces, any call outside the unit of work panics
+	uc := &useCase{walletRepo: &lockRecorder{}, transactionRepo: &txTransactions{}, uow: uow, currencies: currencies}
+
+	if err := uc.Deposit(context.Background(), userID, wallet.ID, decimal.NewFromInt(5), "", "ref-1"); err != nil {
+		t.Fatalf("Deposit() error = %v", err)
+	}
+
+	if uow.calls != 1 {
+		t.Errorf("unit of work calls = %d, want 1", uow.calls)
+	}
+	if !wallets.wallet.Balance.Equal(decimal.NewFromInt(15)) {
+		t.Errorf("balance = %s, want 15", wallets.wallet.Balance)
+	}
+	if len(transactions.created) != 1 || transactions.created[0].ReferenceID != "ref-1" {
+		t.Errorf("transactions = %+v, want one with reference ref-1", transactions.created)
+	}
+	if len(ledgerRepo.posted) != 1 || transactions.created[0].JournalEntryID == nil {
+		t.Errorf("journal entries = %d, want one linked to the transaction", len(ledgerRepo.posted))
+	}
+}
+
+func TestDepositAlreadyApplied(t *testing.T) {
+	userID := uuid.New()
+	walletID := uuid.New()
+
+	tests := []struct {
+		name      string
+		err       error
+		committed *entity.Transaction
+		wantErr   bool
+	}{
+		{
+			name:      "reference committed on this wallet",
+			err:       uniqueViolation(repository.TransactionReferenceConstraint),
+			committed: &entity.Transaction{WalletID: walletID, ReferenceID: "ref-1"},
+		},
+		{
+			name:      "reference committed on another wallet",
+			err:       uniqueViolation(repository.TransactionReferenceConstraint),
+			committed: &entity.Transaction{WalletID: uuid.New(), ReferenceID: "ref-1"},
+			wantErr:   true,
+		},
+		{
+			name:      "another constraint",
+			err:       uniqueViolation("idx_journal_entries_reference"),
+			committed: &entity.Transaction{WalletID: walletID, ReferenceID: "ref-1"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wallet := &entity.Wallet{ID: walletID, UserID: userID, Currency: "IDR", Status: consts.WalletStatusActive}
+			transactions := &txTransactions{err: fmt.Errorf("failed to create transaction: %w", tt.err)}
+			uow := &fakeUnitOfWork{repos: &repository.Repositories{Wallets: &txWallets{wallet: wallet}, Transactions: transactions, Ledger: &txLedger{}}}
+			committed := &txTransactions{created: []*entity.Transaction{tt.committed}}
+			uc := &useCase{transactionRepo: committed, uow: uow, currencies: currencies}
+
+			err := uc.Deposit(context.Background(), userID, walletID, decimal.NewFromInt(5), "", "ref-1")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Deposit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDepositChecksCurrency(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name     string
+		currency string
+		amount   string
+		wantErr  bool
+	}{
+		{name: "two decimals", currency: "IDR", amount: "10.25"},
+		{name: "too many decimals", currency: "IDR", amount: "10.255", wantErr: true},
+		{name: "zero decimal currency", currency: "JPY", amount: "500"},
+		{name: "fraction of a yen", currency: "JPY", amount: "500.5", wantErr: true},
+		{name: "three decimal currency", currency: "KWD", amount: "1.125"},
+		{name: "disabled currency", currency: "XAU", amount: "1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wallet := &entity.Wallet{ID: uuid.New(), UserID: userID, Currency: tt.currency, Status: consts.WalletStatusActive}
+			transactions := &txTransactions{}
+			uow := &fakeUnitOfWork{repos: &repository.Repositories{Wallets: &txWallets{wallet: wallet}, Transactions: transactions, Ledger: &txLedger{}}}
+			uc := &useCase{uow: uow, currencies: currencies}
+
+			err := uc.Deposit(context.Background(), userID, wallet.ID, decimal.RequireFromString(tt.amount), "", "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Deposit(%s %s) error = %v, wantErr %v", tt.amount, tt.currency, err, tt.wantErr)
+			}
+			// Rejected amounts are never stored, rounded or not
+			if tt.wantErr && len(transactions.created) != 0 {
+				t.Errorf("transactions = %+v, want none", transactions.created)
+			}
+		})
+	}
+}
+
+func TestCreateWalletChecksCurrency(t *testing.T) {
+	uow := &fakeUnitOfWork{repos: &repository.Repositories{Wallets: &txWallets{}, Ledger: &txLedger{}}}
+	uc := &useCase{uow: uow, currencies: currencies}
+	ctx := context.Background()
+
+	wallet, err := uc.CreateWallet(ctx, uuid.New(), "Yen", "jpy")
+	if err != nil {
+		t.Fatalf("CreateWallet(jpy) error = %v", err)
+	}
+	if wallet.Currency != "JPY" {
+		t.Errorf("currency = %s, want the registry code JPY", wallet.Currency)
+	}
+
+	if wallet, err := uc.CreateWallet(ctx, uuid.New(), "Default", ""); err != nil || wallet.Currency != consts.DefaultCurrency {
+		t.Errorf("CreateWallet() without currency = %v, %v, want %s", wallet, err, consts.DefaultCurrency)
+	}
+	if _, err := uc.CreateWallet(ctx, uuid.New(), "Gold", "XAU"); err != currencyusecase.ErrCurrencyDisabled {
+		t.Errorf("CreateWallet(XAU) error = %v, want ErrCurrencyDisabled", err)
+	}
+	if _, err := uc.CreateWallet(ctx, uuid.New(), "Unknown", "XTS"); err != currencyusecase.ErrUnsupportedCurrency {
+		t.Errorf("CreateWallet(XTS) error = %v, want ErrUnsupportedCurrency", err)
+	}
+}
+
+func TestCheckAmountLetsMoneyLeaveDisabledCurrency(t *testing.T) {
+	uc := &useCase{currencies: currencies}
+	ctx := context.Background()
+
+	if err := uc.checkAmount(ctx, "XAU", decimal.NewFromInt(1), false); err != nil {
+		t.Errorf("outgoing checkAmount(XAU) error = %v, want nil", err)
+	}
+	if err := uc.checkAmount(ctx, "XAU", decimal.NewFromInt(1), true); err != currencyusecase.ErrCurrencyDisabled {
+		t.Errorf("incoming checkAmount(XAU) error = %v, want ErrCurrencyDisabled", err)
+	}
+	if err := uc.checkAmount(ctx, "JPY", decimal.RequireFromString("0.5"), false); err == nil {
+		t.Errorf("outgoing checkAmount(0.5 JPY) error = nil, want a precision error")
+	}
+}
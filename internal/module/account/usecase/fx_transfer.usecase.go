@@ -0,0 +1,131 @@
+package accountusecase
+
+import (
+	"context"
+	"fmt"
+
+	"wallet_api/internal/common/consts"
+	"wallet_api/internal/common/errors"
+	"wallet_api/internal/entity"
+	"wallet_api/internal/module/account/repository"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var errConversionMismatch = errors.New(400, "Quote currencies do not match the wallets", nil)
+
+// Conversion is a priced currency exchange, the fx module builds it from a quote
+type Conversion struct {
+	QuoteID             uuid.UUID
+	Rate                decimal.Decimal
+	Spread              decimal.Decimal
+	SourceAmount        decimal.Decimal
+	SourceCurrency      string
+	DestinationAmount   decimal.Decimal
+	DestinationCurrency string
+}
+
+// ConvertTransfer debits SourceAmount from a wallet in the source currency and credits
+// DestinationAmount to a wallet in the destination currency. It runs the same checks as
+// Transfer, the step-up threshold applies to the source amount.
+func (uc *useCase) ConvertTransfer(ctx context.Context, userID, fromWalletID, toWalletID uuid.UUID, conversion Conversion, description string, proof StepUpProof, referenceID string) error {
+	if conversion.SourceAmount.LessThanOrEqual(decimal.Zero) || conversion.DestinationAmount.LessThanOrEqual(decimal.Zero) {
+		return errors.ErrBadRequest
+	}
+
+	if fromWalletID == toWalletID {
+		return errors.New(400, "Cannot transfer to the same wallet", nil)
+	}
+
+	if err := uc.authorizeOutgoing(ctx, userID, fromWalletID, conversion.SourceAmount, proof); err != nil {
+		return err
+	}
+
+	// The destination wallet is matched against DestinationCurrency once it is locked
+	if err := uc.checkAmount(ctx, conversion.DestinationCurrency, conversion.DestinationAmount, true); err != nil {
+		return err
+	}
+
+	referenceID = newReferenceID(referenceID)
+
+	err := uc.uow.Do(ctx, func(repos *repository.Repositories) error {
+		locked, err := lockWallets(ctx, repos.Wallets, fromWalletID, toWalletID)
+		if err != nil {
+			return err
+		}
+
+		fromWallet, toWallet := locked[fromWalletID], locked[toWalletID]
+		if err := authorizeWallet(fromWallet, nil, userID); err != nil {
+			return err
+		}
+
+		if err := checkTransferStatus(fromWallet, toWallet); err != nil {
+			return err
+		}
+
+		if fromWallet.Currency != conversion.SourceCurrency || toWallet.Currency != conversion.DestinationCurrency {
+			return errConversionMismatch
+		}
+
+		if err := requireAvailable(ctx, repos, fromWallet, conversion.SourceAmount, nil); err != nil {
+			return err
+		}
+
+		return convert(ctx, repos, fromWallet, toWallet, conversion, description, referenceID)
+	})
+	return uc.alreadyApplied(ctx, fromWalletID, referenceID, err)
+}
+
+// convert writes both legs of a cross-currency transfer and its journal entry.
+// The caller has done the checks.
+func convert(ctx context.Context, repos *repository.Repositories, fromWallet, toWallet *entity.Wallet, conversion Conversion, description, referenceID string) error {
+	entry, err := (&ledger{repo: repos.Ledger}).postConversion(ctx, referenceID, description, fromWallet, toWallet,
+		conversion.SourceAmount, conversion.DestinationAmount)
+	if err != nil {
+		return err
+	}
+
+	legs := []struct {
+		wallet      *entity.Wallet
+		amount      decimal.Decimal
+		description string
+	}{
+		{fromWallet, conversion.SourceAmount.Neg(), fmt.Sprintf("FX transfer to wallet %s", toWallet.ID)},
+		{toWallet, conversion.DestinationAmount, fmt.Sprintf("FX transfer from wallet %s", fromWallet.ID)},
+	}
+
+	for _, leg := range legs {
+		balanceBefore := leg.wallet.Balance
+		leg.wallet.Balance = balanceBefore.Add(leg.amount)
+
+		if err := repos.Wallets.Update(ctx, leg.wallet); err != nil {
+			return fmt.Errorf("failed to update wallet: %w", err)
+		}
+
+		transaction := &entity.Transaction{
+			WalletID:            leg.wallet.ID,
+			ReferenceID:         referenceID,
+			JournalEntryID:      &entry.ID,
+			Type:                consts.TransactionTypeFXTransfer,
+			Amount:              leg.amount.Abs(),
+			BalanceBefore:       balanceBefore,
+			BalanceAfter:        leg.wallet.Balance,
+			Description:         leg.description,
+			FXQuoteID:           &conversion.QuoteID,
+			FXRate:              &conversion.Rate,
+			FXSpread:            &conversion.Spread,
+			FXSourceAmount:      &conversion.SourceAmount,
+			FXDestinationAmount: &conversion.DestinationAmount,
+		}
+		if description != "" {
+			transaction.Description = fmt.Sprintf("%s - %s", description, transaction.Description)
+		}
+
+		if err := repos.Transactions.Create(ctx, transaction); err != nil {
+			return fmt.Errorf("failed to create fx transfer transaction: %w", err)
+		}
+	}
+
+	return nil
+}
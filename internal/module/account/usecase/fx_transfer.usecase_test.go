@@ -0,0 +1,59 @@
+package accountusecase
+
+import (
+	"context"
+	"testing"
+
+	"wallet_api/internal/common/consts"
+	"wallet_api/internal/entity"
+	"wallet_api/internal/module/account/repository"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func TestConvertPostsEachCurrency(t *testing.T) {
+	d := decimal.RequireFromString
+	from := &entity.Wallet{ID: uuid.New(), Balance: d("100"), Currency: "USD", Status: consts.WalletStatusActive}
+	to := &entity.Wallet{ID: uuid.New(), Balance: d("5000"), Currency: "IDR", Status: consts.WalletStatusActive}
+
+	transactions := &txTransactions{}
+	ledger := &txLedger{}
+	repos := &repository.Repositories{Wallets: &txWallets{wallet: from}, Transactions: transactions, Ledger: ledger}
+
+	conversion := Conversion{
+		QuoteID:             uuid.New(),
+		Rate:                d("15500"),
+		Spread:              d("0.01"),
+		SourceAmount:        d("10"),
+		SourceCurrency:      "USD",
+		DestinationAmount:   d("153450"),
+		DestinationCurrency: "IDR",
+	}
+	if err := convert(context.Background(), repos, from, to, conversion, "", "fx-ref"); err != nil {
+		t.Fatalf("convert() error = %v", err)
+	}
+
+	if !from.Balance.Equal(d("90")) || !to.Balance.Equal(d("158450")) {
+		t.Errorf("balances = %s USD, %s IDR, want 90 and 158450", from.Balance, to.Balance)
+	}
+
+	if len(ledger.posted) != 1 || len(ledger.posted[0].Postings) != 4 || !ledger.posted[0].Balanced() {
+		t.Fatalf("posted = %+v, want one balanced entry with four postings", ledger.posted)
+	}
+
+	if len(transactions.created) != 2 {
+		t.Fatalf("created %d transactions, want 2", len(transactions.created))
+	}
+	for i, want := range []decimal.Decimal{d("10"), d("153450")} {
+		transaction := transactions.created[i]
+		if transaction.Type != consts.TransactionTypeFXTransfer || !transaction.Amount.Equal(want) {
+			t.Errorf("transaction %d = %s %s, want fx_transfer %s", i, transaction.Type, transaction.Amount, want)
+		}
+		if transaction.FXQuoteID == nil || *transaction.FXQuoteID != conversion.QuoteID ||
+			!transaction.FXRate.Equal(conversion.Rate) || !transaction.FXSpread.Equal(conversion.Spread) ||
+			!transaction.FXSourceAmount.Equal(conversion.SourceAmount) || !transaction.FXDestinationAmount.Equal(conversion.DestinationAmount) {
+			t.Errorf("transaction %d does not carry the quote: %+v", i, transaction)
+		}
+	}
+}
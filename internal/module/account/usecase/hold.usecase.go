@@ -0,0 +1,285 @@
+package accountusecase
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"time"
+
+	"wallet_api/internal/common/base"
+	"wallet_api/internal/common/consts"
+	"wallet_api/internal/common/errors"
+	"wallet_api/internal/entity"
+	"wallet_api/internal/module/account/repository"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+var (
+	errHoldNotFound        = errors.New(404, "Hold not found", nil)
+	errHoldNotActive       = errors.New(409, "Hold has already been captured or voided", nil)
+	errHoldExpired         = errors.New(409, "Hold has expired", nil)
+	errHoldTTLTooLong      = errors.New(400, "Hold expiry is longer than allowed", nil)
+	errHoldCaptureTooLarge = errors.New(400, "Capture amount exceeds the held amount", nil)
+	errHoldForReversal     = errors.New(409, "Hold covers a reversal shortfall and is released by the reversal", nil)
+)
+
+// HoldLimits bounds how long a hold may reserve money
+type HoldLimits struct {
+	DefaultTTL time.Duration
+	MaxTTL     time.Duration
+}
+
+// HoldCapture says how much of a hold to capture and where the money goes
+type HoldCapture struct {
+	// Nil captures the full hold, the rest of a partial capture is released
+	Amount *decimal.Decimal
+	// Nil captures into a withdrawal, otherwise into a transfer to this wallet
+	ToWalletID  *uuid.UUID
+	Description string
+	// Reference of the withdrawal or transfer, empty generates one
+	ReferenceID string
+}
+
+// CreateHold runs the same checks as a withdrawal, capturing later does not ask for a PIN again
+func (uc *useCase) CreateHold(ctx context.Context, userID, walletID uuid.UUID, amount decimal.Decimal, description string, ttl time.Duration, proof StepUpProof, referenceID string) (*entity.Hold, error) {
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return nil, errors.ErrBadRequest
+	}
+
+	if ttl <= 0 {
+		ttl = uc.holdLimits.DefaultTTL
+	}
+	if ttl > uc.holdLimits.MaxTTL {
+		return nil, errHoldTTLTooLong
+	}
+
+	if err := uc.authorizeOutgoing(ctx, userID, walletID, amount, proof); err != nil {
+		return nil, err
+	}
+
+	referenceID = newReferenceID(referenceID)
+
+	var hold *entity.Hold
+	err := uc.uow.Do(ctx, func(repos *repository.Repositories) error {
+		wallet, err := repos.Wallets.FindByIDForUpdate(ctx, walletID)
+		if err := authorizeWallet(wallet, err, userID); err != nil {
+			return err
+		}
+
+		if wallet.Status != consts.WalletStatusActive {
+			return errWalletNotActive
+		}
+
+		if err := requireAvailable(ctx, repos, wallet, amount, nil); err != nil {
+			return err
+		}
+
+		hold = &entity.Hold{
+			WalletID:       walletID,
+			ReferenceID:    referenceID,
+			Amount:         amount,
+			CapturedAmount: decimal.Zero,
+			Currency:       wallet.Currency,
+			Status:         consts.HoldStatusActive,
+			Description:    description,
+			ExpiresAt:      time.Now().Add(ttl),
+		}
+		if err := repos.Holds.Create(ctx, hold); err != nil {
+			return fmt.Errorf("failed to create hold: %w", err)
+		}
+
+		return nil
+	})
+	// A retry of a hold that was already placed gets that hold back
+	if base.IsUniqueViolationOf(err, repository.HoldReferenceConstraint) {
+		existing, err := uc.holdRepo.FindByReferenceID(ctx, walletID, referenceID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find hold %s: %w", referenceID, err)
+		}
+		return existing, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+func (uc *useCase) GetHold(ctx context.Context, userID, walletID, holdID uuid.UUID) (*entity.Hold, error) {
+	if _, err := uc.GetWallet(ctx, userID, walletID); err != nil {
+		return nil, err
+	}
+
+	hold, err := uc.holdRepo.FindByID(ctx, holdID)
+	if err := holdLookupError(hold, err, walletID); err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+func (uc *useCase) GetHolds(ctx context.Context, userID, walletID uuid.UUID, limit, offset int) ([]*entity.Hold, error) {
+	if _, err := uc.GetWallet(ctx, userID, walletID); err != nil {
+		return nil, err
+	}
+
+	holds, err := uc.holdRepo.FindByWalletID(ctx, walletID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get holds: %w", err)
+	}
+
+	return holds, nil
+}
+
+// CaptureHold turns a hold into a withdrawal or a transfer. A hold is captured once, a partial
+// capture releases the rest.
+func (uc *useCase) CaptureHold(ctx context.Context, userID, walletID, holdID uuid.UUID, capture HoldCapture) (*entity.Hold, error) {
+	if capture.Amount != nil && !capture.Amount.IsPositive() {
+		return nil, errors.ErrBadRequest
+	}
+
+	walletIDs := []uuid.UUID{walletID}
+	if capture.ToWalletID != nil {
+		if *capture.ToWalletID == walletID {
+			return nil, errors.New(400, "Cannot transfer to the same wallet", nil)
+		}
+		walletIDs = append(walletIDs, *capture.ToWalletID)
+	}
+
+	referenceID := newReferenceID(capture.ReferenceID)
+
+	var hold *entity.Hold
+	err := uc.uow.Do(ctx, func(repos *repository.Repositories) error {
+		// Wallets are always locked before their holds
+		locked, err := lockWallets(ctx, repos.Wallets, walletIDs...)
+		if err != nil {
+			return err
+		}
+
+		wallet := locked[walletID]
+		if err := authorizeWallet(wallet, nil, userID); err != nil {
+			return err
+		}
+
+		hold, err = repos.Holds.FindByIDForUpdate(ctx, holdID)
+		if err := holdLookupError(hold, err, walletID); err != nil {
+			return err
+		}
+		if err := checkHoldActive(hold); err != nil {
+			return err
+		}
+
+		amount := hold.Amount
+		if capture.Amount != nil {
+			if capture.Amount.GreaterThan(hold.Amount) {
+				return errHoldCaptureTooLarge
+			}
+			if err := uc.checkAmount(ctx, hold.Currency, *capture.Amount, false); err != nil {
+				return err
+			}
+			amount = *capture.Amount
+		}
+
+		description := capture.Description
+		if description == "" {
+			description = hold.Description
+		}
+
+		// The hold reserved the money, but a reversal may have taken the balance below it since
+		if err := requireAvailable(ctx, repos, wallet, amount, hold); err != nil {
+			return err
+		}
+
+		if capture.ToWalletID != nil {
+			if err := checkTransfer(wallet, locked[*capture.ToWalletID]); err != nil {
+				return err
+			}
+			err = transfer(ctx, repos, wallet, locked[*capture.ToWalletID], amount, description, referenceID)
+		} else {
+			if wallet.Status != consts.WalletStatusActive {
+				return errWalletNotActive
+			}
+			err = withdraw(ctx, repos, wallet, amount, description, referenceID)
+		}
+		if err != nil {
+			return err
+		}
+
+		hold.Status = consts.HoldStatusCaptured
+		hold.CapturedAmount = amount
+		hold.CaptureReferenceID = &referenceID
+		if err := repos.Holds.Update(ctx, hold); err != nil {
+			return fmt.Errorf("failed to update hold: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+func (uc *useCase) VoidHold(ctx context.Context, userID, walletID, holdID uuid.UUID) (*entity.Hold, error) {
+	var hold *entity.Hold
+	err := uc.uow.Do(ctx, func(repos *repository.Repositories) error {
+		wallet, err := repos.Wallets.FindByIDForUpdate(ctx, walletID)
+		if err := authorizeWallet(wallet, err, userID); err != nil {
+			return err
+		}
+
+		hold, err = repos.Holds.FindByIDForUpdate(ctx, holdID)
+		if err := holdLookupError(hold, err, walletID); err != nil {
+			return err
+		}
+		if err := checkHoldActive(hold); err != nil {
+			return err
+		}
+
+		hold.Status = consts.HoldStatusVoided
+		if err := repos.Holds.Update(ctx, hold); err != nil {
+			return fmt.Errorf("failed to update hold: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// holdLookupError reports a hold on another wallet exactly like a missing one
+func holdLookupError(hold *entity.Hold, err error, walletID uuid.UUID) error {
+	if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+		return errHoldNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get hold: %w", err)
+	}
+	if hold.WalletID != walletID {
+		return errHoldNotFound
+	}
+
+	return nil
+}
+
+func checkHoldActive(hold *entity.Hold) error {
+	// The owner must not release money a reversal is still owed
+	if hold.ReversalOfID != nil {
+		return errHoldForReversal
+	}
+
+	switch hold.EffectiveStatus(time.Now()) {
+	case consts.HoldStatusActive:
+		return nil
+	case consts.HoldStatusExpired:
+		return errHoldExpired
+	}
+	return errHoldNotActive
+}
@@ -0,0 +1,122 @@
+package accountusecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"wallet_api/internal/common/consts"
+	"wallet_api/internal/entity"
+	"wallet_api/internal/module/account/repository"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// txHolds keeps holds in memory and sums the active ones like the repository does
+type txHolds struct {
+	repository.HoldRepository
+	holds map[uuid.UUID]*entity.Hold
+}
+
+func (r *txHolds) FindByIDForUpdate(_ context.Context, id uuid.UUID) (*entity.Hold, error) {
+	hold, ok := r.holds[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return hold, nil
+}
+
+func (r *txHolds) Update(_ context.Context, hold *entity.Hold) error {
+	r.holds[hold.ID] = hold
+	return nil
+}
+
+func (r *txHolds) Create(_ context.Context, hold *entity.Hold) error {
+	hold.ID = uuid.New()
+	r.holds[hold.ID] = hold
+	return nil
+}
+
+func (r *txHolds) FindActiveReversalHold(_ context.Context, walletID, transactionID uuid.UUID) (*entity.Hold, error) {
+	for _, hold := range r.holds {
+		if hold.WalletID == walletID && hold.ReversalOfID != nil && *hold.ReversalOfID == transactionID && hold.Status == consts.HoldStatusActive {
+			return hold, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *txHolds) HeldAmounts(_ context.Context, walletIDs []uuid.UUID, now time.Time) (map[uuid.UUID]decimal.Decimal, error) {
+	held := make(map[uuid.UUID]decimal.Decimal)
+	for _, hold := range r.holds {
+		if hold.Active(now) {
+			held[hold.WalletID] = held[hold.WalletID].Add(hold.Amount)
+		}
+	}
+	return held, nil
+}
+
+func TestCaptureHold(t *testing.T) {
+	userID := uuid.New()
+	wallet := &entity.Wallet{ID: uuid.New(), UserID: userID, Balance: decimal.NewFromInt(100), Currency: "IDR", Status: consts.WalletStatusActive}
+	hold := &entity.Hold{
+		ID: uuid.New(), WalletID: wallet.ID, Amount: decimal.NewFromInt(60), Currency: "IDR",
+		Status: consts.HoldStatusActive, ExpiresAt: time.Now().Add(time.Hour),
+	}
+	expired := &entity.Hold{
+		ID: uuid.New(), WalletID: wallet.ID, Amount: decimal.NewFromInt(10), Currency: "IDR",
+		Status: consts.HoldStatusActive, ExpiresAt: time.Now().Add(-time.Hour),
+	}
+
+	wallets := &txWallets{wallet: wallet}
+	transactions := &txTransactions{}
+	holds := &txHolds{holds: map[uuid.UUID]*entity.Hold{hold.ID: hold, expired.ID: expired}}
+	repos := &repository.Repositories{Wallets: wallets, Transactions: transactions, Ledger: &txLedger{}, Holds: holds}
+	uc := &useCase{uow: &fakeUnitOfWork{repos: repos}, currencies: currencies}
+	ctx := context.Background()
+
+	// Only 40 is available while the 60 hold is active, the expired one does not count
+	if err := requireAvailable(ctx, repos, wallet, decimal.NewFromInt(50), nil); err != errInsufficientBalance {
+		t.Errorf("requireAvailable(50) error = %v, want errInsufficientBalance", err)
+	}
+	if err := requireAvailable(ctx, repos, wallet, decimal.NewFromInt(40), nil); err != nil {
+		t.Errorf("requireAvailable(40) error = %v", err)
+	}
+
+	tooMuch := decimal.NewFromInt(61)
+	if _, err := uc.CaptureHold(ctx, userID, wallet.ID, hold.ID, HoldCapture{Amount: &tooMuch}); err != errHoldCaptureTooLarge {
+		t.Errorf("CaptureHold(61) error = %v, want errHoldCaptureTooLarge", err)
+	}
+
+	partial := decimal.NewFromInt(30)
+	captured, err := uc.CaptureHold(ctx, userID, wallet.ID, hold.ID, HoldCapture{Amount: &partial})
+	if err != nil {
+		t.Fatalf("CaptureHold(30) error = %v", err)
+	}
+	if captured.Status != consts.HoldStatusCaptured || !captured.CapturedAmount.Equal(partial) || captured.CaptureReferenceID == nil {
+		t.Errorf("captured hold = %+v, want captured 30 with a reference", captured)
+	}
+	if !wallets.wallet.Balance.Equal(decimal.NewFromInt(70)) {
+		t.Errorf("balance = %s, want 70", wallets.wallet.Balance)
+	}
+	if len(transactions.created) != 1 || transactions.created[0].Type != consts.TransactionTypeWithdrawal {
+		t.Errorf("transactions = %+v, want one withdrawal", transactions.created)
+	}
+
+	// The rest of a partial capture is released
+	if err := requireAvailable(ctx, repos, wallet, decimal.NewFromInt(70), nil); err != nil {
+		t.Errorf("requireAvailable(70) after capture error = %v", err)
+	}
+
+	if _, err := uc.CaptureHold(ctx, userID, wallet.ID, hold.ID, HoldCapture{}); err != errHoldNotActive {
+		t.Errorf("second CaptureHold() error = %v, want errHoldNotActive", err)
+	}
+	if _, err := uc.CaptureHold(ctx, userID, wallet.ID, expired.ID, HoldCapture{}); err != errHoldExpired {
+		t.Errorf("CaptureHold() on expired hold error = %v, want errHoldExpired", err)
+	}
+	if _, err := uc.CaptureHold(ctx, userID, wallet.ID, uuid.New(), HoldCapture{}); err != errHoldNotFound {
+		t.Errorf("CaptureHold() on missing hold error = %v, want errHoldNotFound", err)
+	}
+}
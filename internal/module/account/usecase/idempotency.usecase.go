@@ -0,0 +1,131 @@
+package accountusecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"wallet_api/internal/common/consts"
+	"wallet_api/internal/common/errors"
+	"wallet_api/internal/entity"
+	"wallet_api/internal/module/account/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	errIdempotencyKeyReused     = errors.New(422, "Idempotency-Key was already used for a different request", nil)
+	errIdempotencyKeyInProgress = errors.New(409, "A request with this Idempotency-Key is still being processed", nil)
+)
+
+const (
+	// idempotencyLockTimeout is how long a claim is held before another request may take it over.
+	// It is set once and never renewed, so it is also the ceiling for a request holding a key:
+	// one still running after a minute can be taken over, and only the reference constraint
+	// stops the money from moving twice.
+	idempotencyLockTimeout = time.Minute
+	// A duplicate waits this long for the first request to finish before getting a 409
+	idempotencyWaitTimeout  = 10 * time.Second
+	idempotencyPollInterval = 100 * time.Millisecond
+)
+
+// IdempotencyUseCase serializes requests sharing an Idempotency-Key and stores the first response
+type IdempotencyUseCase interface {
+	// Begin claims the key, or returns the completed record to replay. A duplicate that
+	// arrives while the first request runs waits for it.
+	Begin(ctx context.Context, userID uuid.UUID, key, requestHash string) (*entity.IdempotencyKey, error)
+	Complete(ctx context.Context, id uuid.UUID, status int, body []byte) error
+	// Release drops the claim so the request can be retried with the same key
+	Release(ctx context.Context, id uuid.UUID) error
+}
+
+type idempotencyUseCase struct {
+	repo repository.IdempotencyKeyRepository
+	ttl  time.Duration
+}
+
+func NewIdempotencyUseCase(repo repository.IdempotencyKeyRepository, ttl time.Duration) IdempotencyUseCase {
+	return &idempotencyUseCase{
+		repo: repo,
+		ttl:  ttl,
+	}
+}
+
+func (uc *idempotencyUseCase) Begin(ctx context.Context, userID uuid.UUID, key, requestHash string) (*entity.IdempotencyKey, error) {
+	deadline := time.Now().Add(idempotencyWaitTimeout)
+
+	for {
+		now := time.Now()
+		record := &entity.IdempotencyKey{
+			ID:          uuid.New(),
+			UserID:      userID,
+			Key:         key,
+			RequestHash: requestHash,
+			Status:      consts.IdempotencyStatusProcessing,
+			LockedUntil: now.Add(idempotencyLockTimeout),
+			ExpiresAt:   now.Add(uc.ttl),
+			CreatedAt:   now,
+		}
+
+		claimed, err := uc.repo.Claim(ctx, record, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to claim idempotency key: %w", err)
+		}
+		if claimed {
+			return record, nil
+		}
+
+		existing, err := uc.repo.FindByKey(ctx, userID, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find idempotency key: %w", err)
+		}
+		// Released or expired between the two statements, claim again
+		if existing == nil || !existing.ExpiresAt.After(now) {
+			continue
+		}
+
+		if existing.RequestHash != requestHash {
+			return nil, errIdempotencyKeyReused
+		}
+		if existing.Completed() {
+			return existing, nil
+		}
+
+		if existing.LockedUntil.Before(now) {
+			takenOver, err := uc.repo.TakeOver(ctx, existing.ID, now, now.Add(idempotencyLockTimeout))
+			if err != nil {
+				return nil, fmt.Errorf("failed to take over idempotency key: %w", err)
+			}
+			if takenOver {
+				existing.TakenOver = true
+				return existing, nil
+			}
+		}
+
+		if now.After(deadline) {
+			return nil, errIdempotencyKeyInProgress
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(idempotencyPollInterval):
+		}
+	}
+}
+
+func (uc *idempotencyUseCase) Complete(ctx context.Context, id uuid.UUID, status int, body []byte) error {
+	if err := uc.repo.Complete(ctx, id, status, body); err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+
+	return nil
+}
+
+func (uc *idempotencyUseCase) Release(ctx context.Context, id uuid.UUID) error {
+	if err := uc.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package accountusecase
+
+import (
+	"context"
+	"fmt"
+
+	"wallet_api/internal/common/consts"
+	"wallet_api/internal/entity"
+	"wallet_api/internal/module/account/repository"
+
+	"github.com/shopspring/decimal"
+)
+
+// ledger posts journal entries, shared by money movement and reconciliation
+type ledger struct {
+	repo repository.LedgerRepository
+}
+
+// ledgerLeg is one side of a simple two-posting journal entry
+type ledgerLeg struct {
+	wallet *entity.Wallet
+	// system account code, used when wallet is nil
+	system string
+}
+
+func walletLeg(wallet *entity.Wallet) ledgerLeg {
+	return ledgerLeg{wallet: wallet}
+}
+
+func systemLeg(code string) ledgerLeg {
+	return ledgerLeg{system: code}
+}
+
+// post records amount moving from debit to credit as one balanced journal entry
+func (l *ledger) post(
+	ctx context.Context,
+	entryType, referenceID, description, currency string,
+	amount decimal.Decimal,
+	debit, credit ledgerLeg,
+) (*entity.JournalEntry, error) {
+	debitAccount, err := l.account(ctx, debit, currency)
+	if err != nil {
+		return nil, err
+	}
+	creditAccount, err := l.account(ctx, credit, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.write(ctx, &entity.JournalEntry{
+		ReferenceID: referenceID,
+		Type:        entryType,
+		Description: description,
+		Postings: []entity.LedgerPosting{
+			{LedgerAccountID: debitAccount.ID, Direction: consts.PostingDirectionDebit, Amount: amount, Currency: currency},
+			{LedgerAccountID: creditAccount.ID, Direction: consts.PostingDirectionCredit, Amount: amount, Currency: currency},
+		},
+	})
+}
+
+// postConversion records a cross-currency transfer. The fx system account buys the source
+// amount from one wallet and pays the destination amount to the other, so the entry
+// balances in each currency on its own.
+func (l *ledger) postConversion(
+	ctx context.Context,
+	referenceID, description string,
+	from, to *entity.Wallet,
+	sourceAmount, destinationAmount decimal.Decimal,
+) (*entity.JournalEntry, error) {
+	fromAccount, err := l.account(ctx, walletLeg(from), from.Currency)
+	if err != nil {
+		return nil, err
+	}
+	fxSource, err := l.account(ctx, systemLeg(consts.LedgerAccountFX), from.Currency)
+	if err != nil {
+		return nil, err
+	}
+	fxDestination, err := l.account(ctx, systemLeg(consts.LedgerAccountFX), to.Currency)
+	if err != nil {
+		return nil, err
+	}
+	toAccount, err := l.account(ctx, walletLeg(to), to.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.write(ctx, &entity.JournalEntry{
+		ReferenceID: referenceID,
+		Type:        consts.TransactionTypeFXTransfer,
+		Description: description,
+		Postings: []entity.LedgerPosting{
+			{LedgerAccountID: fromAccount.ID, Direction: consts.PostingDirectionDebit, Amount: sourceAmount, Currency: from.Currency},
+			{LedgerAccountID: fxSource.ID, Direction: consts.PostingDirectionCredit, Amount: sourceAmount, Currency: from.Currency},
+			{LedgerAccountID: fxDestination.ID, Direction: consts.PostingDirectionDebit, Amount: destinationAmount, Currency: to.Currency},
+			{LedgerAccountID: toAccount.ID, Direction: consts.PostingDirectionCredit, Amount: destinationAmount, Currency: to.Currency},
+		},
+	})
+}
+
+func (l *ledger) write(ctx context.Context, entry *entity.JournalEntry) (*entity.JournalEntry, error) {
+	if !entry.Balanced() {
+		return nil, fmt.Errorf("journal entry %s is not balanced", entry.ReferenceID)
+	}
+
+	if err := l.repo.Post(ctx, entry); err != nil {
+		return nil, fmt.Errorf("failed to post journal entry: %w", err)
+	}
+
+	return entry, nil
+}
+
+func (l *ledger) account(ctx context.Context, leg ledgerLeg, currency string) (*entity.LedgerAccount, error) {
+	if leg.wallet != nil {
+		account, err := l.repo.WalletAccount(ctx, leg.wallet)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get wallet ledger account: %w", err)
+		}
+		return account, nil
+	}
+
+	account, err := l.repo.SystemAccount(ctx, leg.system, currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s ledger account: %w", leg.system, err)
+	}
+	return account, nil
+}
@@ -0,0 +1,288 @@
+package accountusecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"wallet_api/internal/common/consts"
+	"wallet_api/internal/common/errors"
+	"wallet_api/internal/entity"
+	"wallet_api/internal/module/account/repository"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// A transaction whose balance_before is not the balance_after of the one before it
+	DiscrepancyChainBreak = "chain_break"
+	// balance_after - balance_before does not match the amount and type
+	DiscrepancyAmountMismatch = "amount_mismatch"
+	// The history ends at a different balance than wallets.balance
+	DiscrepancyBalanceMismatch = "balance_mismatch"
+	// The ledger postings of the wallet add up to a different balance than wallets.balance
+	DiscrepancyLedgerMismatch = "ledger_mismatch"
+)
+
+const reconcileBatchSize = 100
+
+var errReconcileReasonRequired = errors.New(400, "A reason is required for reconciliation adjustments", nil)
+
+type Discrepancy struct {
+	WalletID      uuid.UUID
+	TransactionID *uuid.UUID
+	Kind          string
+	Expected      decimal.Decimal
+	Actual        decimal.Decimal
+	// Reference of the adjustment written by Fix, empty when not corrected
+	AdjustmentReference string
+}
+
+type ReconcileReport struct {
+	CheckedWallets int
+	Discrepancies  []Discrepancy
+	GeneratedAt    time.Time
+}
+
+// ReconcileUseCase finds drift between wallets.balance, the transaction history and the ledger.
+// The stored balance is what users have seen and spent against, so adjustments move the
+// history and ledger to it and never the other way around.
+type ReconcileUseCase interface {
+	// Reconcile checks one wallet, or every wallet when walletID is nil
+	Reconcile(ctx context.Context, walletID *uuid.UUID) (*ReconcileReport, error)
+	// Fix reconciles and writes adjustment entries for balance and ledger mismatches.
+	// Breaks in the middle of the history are reported but stay, history is append-only.
+	Fix(ctx context.Context, walletID *uuid.UUID, reason string) (*ReconcileReport, error)
+}
+
+type reconcileUseCase struct {
+	reconcileRepo repository.ReconcileRepository
+	uow           repository.UnitOfWork
+}
+
+func NewReconcileUseCase(reconcileRepo repository.ReconcileRepository, uow repository.UnitOfWork) ReconcileUseCase {
+	return &reconcileUseCase{
+		reconcileRepo: reconcileRepo,
+		uow:           uow,
+	}
+}
+
+func (uc *reconcileUseCase) Reconcile(ctx context.Context, walletID *uuid.UUID) (*ReconcileReport, error) {
+	return uc.run(ctx, walletID, "")
+}
+
+func (uc *reconcileUseCase) Fix(ctx context.Context, walletID *uuid.UUID, reason string) (*ReconcileReport, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errReconcileReasonRequired
+	}
+
+	return uc.run(ctx, walletID, reason)
+}
+
+// run reconciles the wallets and, when a reason is given, adjusts the ones that drifted
+func (uc *reconcileUseCase) run(ctx context.Context, walletID *uuid.UUID, reason string) (*ReconcileReport, error) {
+	report := &ReconcileReport{GeneratedAt: time.Now()}
+
+	check := func(id uuid.UUID) error {
+		snapshot, err := uc.reconcileRepo.Snapshot(ctx, id)
+		if err != nil {
+			return walletLookupError(err)
+		}
+
+		found := reconcileWallet(snapshot)
+		if reason != "" && needsAdjustment(found) {
+			reference, err := uc.adjust(ctx, id, reason)
+			if err != nil {
+				return err
+			}
+			for i := range found {
+				if found[i].Kind == DiscrepancyBalanceMismatch || found[i].Kind == DiscrepancyLedgerMismatch {
+					found[i].AdjustmentReference = reference
+				}
+			}
+		}
+
+		report.CheckedWallets++
+		report.Discrepancies = append(report.Discrepancies, found...)
+		return nil
+	}
+
+	if walletID != nil {
+		if err := check(*walletID); err != nil {
+			return nil, err
+		}
+		return report, nil
+	}
+
+	after := uuid.Nil
+	for {
+		ids, err := uc.reconcileRepo.WalletIDs(ctx, after, reconcileBatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list wallets: %w", err)
+		}
+
+		for _, id := range ids {
+			if err := check(id); err != nil {
+				return nil, err
+			}
+		}
+
+		if len(ids) < reconcileBatchSize {
+			return report, nil
+		}
+		after = ids[len(ids)-1]
+	}
+}
+
+// adjust writes the entries that make history and ledger end at the stored balance. It locks
+// the wallet and reads it again, so money that moved since the report is not adjusted twice.
+func (uc *reconcileUseCase) adjust(ctx context.Context, walletID uuid.UUID, reason string) (string, error) {
+	var reference string
+
+	err := uc.uow.Do(ctx, func(repos *repository.Repositories) error {
+		if _, err := repos.Wallets.FindByIDForUpdate(ctx, walletID); err != nil {
+			return walletLookupError(err)
+		}
+
+		snapshot, err := repos.Reconcile.Snapshot(ctx, walletID)
+		if err != nil {
+			return walletLookupError(err)
+		}
+
+		wallet := snapshot.Wallet
+		historyEnd := decimal.Zero
+		if n := len(snapshot.Transactions); n > 0 {
+			historyEnd = snapshot.Transactions[n-1].BalanceAfter
+		}
+		historyDiff := wallet.Balance.Sub(historyEnd)
+		ledgerDiff := wallet.Balance.Sub(snapshot.LedgerBalance)
+		if historyDiff.IsZero() && ledgerDiff.IsZero() {
+			return nil
+		}
+
+		reference = "reconcile-" + uuid.New().String()
+		description := "Reconciliation adjustment: " + reason
+
+		var entryID *uuid.UUID
+		if !ledgerDiff.IsZero() {
+			// Unexplained money goes to or comes from suspense
+			debit, credit := systemLeg(consts.LedgerAccountSuspense), walletLeg(wallet)
+			if ledgerDiff.IsNegative() {
+				debit, credit = credit, debit
+			}
+
+			entry, err := (&ledger{repo: repos.Ledger}).post(ctx, consts.TransactionTypeAdjustment, reference, description, wallet.Currency, ledgerDiff.Abs(), debit, credit)
+			if err != nil {
+				return err
+			}
+			entryID = &entry.ID
+		}
+
+		if !historyDiff.IsZero() {
+			transaction := &entity.Transaction{
+				WalletID:      walletID,
+				ReferenceID:   reference,
+				Type:          consts.TransactionTypeAdjustment,
+				Amount:        historyDiff.Abs(),
+				BalanceBefore: historyEnd,
+				BalanceAfter:  wallet.Balance,
+				Description:   description,
+			}
+			// Only link the ledger entry when it moved the same money
+			if historyDiff.Equal(ledgerDiff) {
+				transaction.JournalEntryID = entryID
+			}
+
+			if err := repos.Transactions.Create(ctx, transaction); err != nil {
+				return fmt.Errorf("failed to create adjustment transaction: %w", err)
+			}
+		}
+
+		return nil
+	})
+
+	return reference, err
+}
+
+// reconcileWallet replays the history of one wallet. The chain follows the recorded
+// balance_after, so one broken row is reported once instead of on every row after it.
+func reconcileWallet(snapshot *repository.WalletSnapshot) []Discrepancy {
+	wallet := snapshot.Wallet
+	var found []Discrepancy
+
+	running := decimal.Zero
+	for _, transaction := range snapshot.Transactions {
+		transactionID := transaction.ID
+
+		if !transaction.BalanceBefore.Equal(running) {
+			found = append(found, Discrepancy{
+				WalletID:      wallet.ID,
+				TransactionID: &transactionID,
+				Kind:          DiscrepancyChainBreak,
+				Expected:      running,
+				Actual:        transaction.BalanceBefore,
+			})
+		}
+
+		expectedAfter := transaction.BalanceBefore.Add(signedAmount(transaction))
+		if !transaction.BalanceAfter.Equal(expectedAfter) {
+			found = append(found, Discrepancy{
+				WalletID:      wallet.ID,
+				TransactionID: &transactionID,
+				Kind:          DiscrepancyAmountMismatch,
+				Expected:      expectedAfter,
+				Actual:        transaction.BalanceAfter,
+			})
+		}
+
+		running = transaction.BalanceAfter
+	}
+
+	if !running.Equal(wallet.Balance) {
+		found = append(found, Discrepancy{
+			WalletID: wallet.ID,
+			Kind:     DiscrepancyBalanceMismatch,
+			Expected: running,
+			Actual:   wallet.Balance,
+		})
+	}
+
+	if !snapshot.LedgerBalance.Equal(wallet.Balance) {
+		found = append(found, Discrepancy{
+			WalletID: wallet.ID,
+			Kind:     DiscrepancyLedgerMismatch,
+			Expected: snapshot.LedgerBalance,
+			Actual:   wallet.Balance,
+		})
+	}
+
+	return found
+}
+
+// signedAmount is the change a transaction should make to the balance. Transfers and
+// adjustments go either way, their direction is taken from the recorded balances.
+func signedAmount(transaction *entity.Transaction) decimal.Decimal {
+	switch transaction.Type {
+	case consts.TransactionTypeDeposit:
+		return transaction.Amount
+	case consts.TransactionTypeWithdrawal:
+		return transaction.Amount.Neg()
+	}
+
+	if transaction.BalanceAfter.LessThan(transaction.BalanceBefore) {
+		return transaction.Amount.Neg()
+	}
+	return transaction.Amount
+}
+
+func needsAdjustment(found []Discrepancy) bool {
+	for _, discrepancy := range found {
+		if discrepancy.Kind == DiscrepancyBalanceMismatch || discrepancy.Kind == DiscrepancyLedgerMismatch {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package accountusecase
+
+import (
+	"testing"
+
+	"wallet_api/internal/common/consts"
+	"wallet_api/internal/entity"
+	"wallet_api/internal/module/account/repository"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func TestReconcileWallet(t *testing.T) {
+	d := decimal.RequireFromString
+	transaction := func(txType, amount, before, after string) *entity.Transaction {
+		return &entity.Transaction{ID: uuid.New(), Type: txType, Amount: d(amount), BalanceBefore: d(before), BalanceAfter: d(after)}
+	}
+
+	tests := []struct {
+		name         string
+		transactions []*entity.Transaction
+		balance      string
+		ledger       string
+		want         []string
+	}{
+		{
+			name: "clean history",
+			transactions: []*entity.Transaction{
+				transaction(consts.TransactionTypeDeposit, "100", "0", "100"),
+				transaction(consts.TransactionTypeWithdrawal, "30", "100", "70"),
+				transaction(consts.TransactionTypeTransfer, "20", "70", "50"),
+				transaction(consts.TransactionTypeTransfer, "5.50", "50", "55.50"),
+			},
+			balance: "55.50",
+			ledger:  "55.50",
+		},
+		{
+			name:    "empty wallet",
+			balance: "0",
+			ledger:  "0",
+		},
+		{
+			name: "broken chain is reported once",
+			transactions: []*entity.Transaction{
+				transaction(consts.TransactionTypeDeposit, "100", "0", "100"),
+				transaction(consts.TransactionTypeDeposit, "10", "90", "100"),
+				transaction(consts.TransactionTypeDeposit, "10", "100", "110"),
+			},
+			balance: "110",
+			ledger:  "110",
+			want:    []string{DiscrepancyChainBreak},
+		},
+		{
+			name: "withdrawal that added money",
+			transactions: []*entity.Transaction{
+				transaction(consts.TransactionTypeDeposit, "100", "0", "100"),
+				transaction(consts.TransactionTypeWithdrawal, "30", "100", "130"),
+			},
+			balance: "130",
+			ledger:  "130",
+			want:    []string{DiscrepancyAmountMismatch},
+		},
+		{
+			name: "balance changed outside the history and ledger",
+			transactions: []*entity.Transaction{
+				transaction(consts.TransactionTypeDeposit, "100", "0", "100"),
+			},
+			balance: "150",
+			ledger:  "100",
+			want:    []string{DiscrepancyBalanceMismatch, DiscrepancyLedgerMismatch},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot := &repository.WalletSnapshot{
+				Wallet:        &entity.Wallet{ID: uuid.New(), Balance: d(tt.balance)},
+				Transactions:  tt.transactions,
+				LedgerBalance: d(tt.ledger),
+			}
+
+			found := reconcileWallet(snapshot)
+			if len(found) != len(tt.want) {
+				t.Fatalf("reconcileWallet() found %+v, want kinds %v", found, tt.want)
+			}
+			for i, kind := range tt.want {
+				if found[i].Kind != kind {
+					t.Errorf("discrepancy %d kind = %s, want %s", i, found[i].Kind, kind)
+				}
+			}
+		})
+	}
+}
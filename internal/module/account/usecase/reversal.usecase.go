@@ -0,0 +1,370 @@
+package accountusecase
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"wallet_api/internal/common/consts"
+	"wallet_api/internal/common/errors"
+	"wallet_api/internal/entity"
+	"wallet_api/internal/module/account/repository"
+	currencyusecase "wallet_api/internal/module/currency/usecase"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	errReversalNotFound            = errors.New(404, "Transaction not found", nil)
+	errNotReversible               = errors.New(400, "Only deposits, withdrawals and transfers can be reversed", nil)
+	errFXNotReversible             = errors.New(400, "Cross-currency transfers cannot be reversed, send the money back with a new quote", nil)
+	errAlreadyReversed             = errors.New(409, "Transaction has already been fully reversed", nil)
+	errReversalTooLarge            = errors.New(400, "Reversal amount exceeds the amount left to reverse", nil)
+	errReversalAmountInvalid       = errors.New(400, "Reversal amount must be greater than zero", nil)
+	errReversalReasonRequired      = errors.New(400, "A reason is required to reverse a transaction", nil)
+	errReversalPolicyInvalid       = errors.New(400, "Invalid insufficient balance policy", nil)
+	errReversalInsufficientBalance = errors.New(409, "Wallet balance does not cover the reversal", nil)
+)
+
+// A shortfall hold stays until a later reversal of the row collects the money, it must not
+// lapse on its own
+const reversalHoldTTL = 10 * 365 * 24 * time.Hour
+
+type ReversalRequest struct {
+	// Reference of the deposit, withdrawal or transfer to reverse
+	ReferenceID string
+	// Nil reverses whatever is left of the original amount
+	Amount *decimal.Decimal
+	Reason string
+	// One of the consts.ReversalPolicy values, empty uses the configured one
+	Policy string
+	// Reference of the reversal itself, empty generates one
+	ReversalReferenceID string
+}
+
+type Reversal struct {
+	ReferenceID         string
+	OriginalReferenceID string
+	Amount              decimal.Decimal
+	// Left to reverse after this reversal
+	Remaining    decimal.Decimal
+	Transactions []*entity.Transaction
+	// Set when the hold policy held what the wallet could not cover, Amount is then what it did cover
+	Hold *entity.Hold
+}
+
+// ReversalUseCase undoes a deposit, withdrawal or transfer by posting compensating entries.
+// History is append-only, so the original rows stay and the reversal rows point at them.
+type ReversalUseCase interface {
+	Reverse(ctx context.Context, req ReversalRequest) (*Reversal, error)
+}
+
+type reversalUseCase struct {
+	transactionRepo repository.TransactionRepository
+	uow             repository.UnitOfWork
+	currencies      Currencies
+	// Used when the request does not pick a policy
+	policy string
+}
+
+func NewReversalUseCase(transactionRepo repository.TransactionRepository, uow repository.UnitOfWork, currencies Currencies, policy string) ReversalUseCase {
+	return &reversalUseCase{
+		transactionRepo: transactionRepo,
+		uow:             uow,
+		currencies:      currencies,
+		policy:          policy,
+	}
+}
+
+func (uc *reversalUseCase) Reverse(ctx context.Context, req ReversalRequest) (*Reversal, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, errReversalReasonRequired
+	}
+
+	policy := req.Policy
+	if policy == "" {
+		policy = uc.policy
+	}
+	if !validReversalPolicy(policy) {
+		return nil, errReversalPolicyInvalid
+	}
+
+	if req.Amount != nil && !req.Amount.IsPositive() {
+		return nil, errReversalAmountInvalid
+	}
+
+	originals, err := uc.transactionRepo.FindByReferenceID(ctx, req.ReferenceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find transactions: %w", err)
+	}
+	if len(originals) == 0 {
+		return nil, errReversalNotFound
+	}
+
+	plan, err := planReversal(originals)
+	if err != nil {
+		return nil, err
+	}
+
+	reference := req.ReversalReferenceID
+	if reference == "" {
+		reference = "reversal-" + uuid.New().String()
+	}
+	description := fmt.Sprintf("Reversal of %s: %s", req.ReferenceID, reason)
+
+	var result *Reversal
+	err = uc.uow.Do(ctx, func(repos *repository.Repositories) error {
+		result = &Reversal{ReferenceID: reference, OriginalReferenceID: req.ReferenceID}
+
+		locked, err := lockWallets(ctx, repos.Wallets, plan.walletIDs()...)
+		if err != nil {
+			return err
+		}
+
+		// Counted under the wallet locks, so two reversals of the same operation cannot both pass
+		reversed, err := repos.Transactions.ReversedAmount(ctx, originals[0].ID)
+		if err != nil {
+			return fmt.Errorf("failed to sum reversals: %w", err)
+		}
+		remaining := originals[0].Amount.Sub(reversed)
+		if !remaining.IsPositive() {
+			return errAlreadyReversed
+		}
+
+		amount := remaining
+		if req.Amount != nil {
+			if req.Amount.GreaterThan(remaining) {
+				return errReversalTooLarge
+			}
+			amount = *req.Amount
+		}
+
+		// A reversed deposit gives the money back to cash_in, a reversed withdrawal takes it from cash_out
+		debit, credit := systemLeg(consts.LedgerAccountCashOut), systemLeg(consts.LedgerAccountCashIn)
+		var from, to *entity.Wallet
+		if plan.from != nil {
+			if from = locked[plan.from.WalletID]; from == nil {
+				return errWalletNotFound
+			}
+			debit = walletLeg(from)
+		}
+		if plan.to != nil {
+			if to = locked[plan.to.WalletID]; to == nil {
+				return errWalletNotFound
+			}
+			credit = walletLeg(to)
+		}
+
+		// Both sides of a transfer share the currency
+		var currency string
+		if from != nil {
+			currency = from.Currency
+		} else {
+			currency = to.Currency
+		}
+
+		// A partial amount comes from the request and must fit the currency, the remainder always does
+		if req.Amount != nil {
+			registered, err := uc.currencies.Lookup(ctx, currency)
+			if err != nil {
+				return err
+			}
+			if err := currencyusecase.CheckAmount(registered, amount); err != nil {
+				return err
+			}
+		}
+
+		// Held money is promised elsewhere, so it does not cover a reversal either. A shortfall
+		// hold an earlier reversal of this row left is what this one collects, it does not count.
+		var pending *entity.Hold
+		shortfall := decimal.Zero
+		if from != nil {
+			pending, err = repos.Holds.FindActiveReversalHold(ctx, from.ID, plan.from.ID)
+			if err != nil {
+				return fmt.Errorf("failed to find reversal hold: %w", err)
+			}
+
+			err := requireAvailable(ctx, repos, from, amount, pending)
+			if err != nil && !stdErrors.Is(err, errInsufficientBalance) {
+				return err
+			}
+			if err != nil {
+				switch policy {
+				case consts.ReversalPolicyFail:
+					return errReversalInsufficientBalance
+				case consts.ReversalPolicyHold:
+					// Take what is available now and reserve the rest of the wallet's incoming money
+					covered := decimal.Max(from.AvailableBalance(), decimal.Zero)
+					shortfall = amount.Sub(covered)
+					amount = covered
+				}
+			}
+		}
+
+		// With the hold policy an empty wallet gives nothing now, only the hold is placed
+		if amount.IsPositive() {
+			entry, err := (&ledger{repo: repos.Ledger}).post(ctx, consts.TransactionTypeReversal, reference, description, currency, amount, debit, credit)
+			if err != nil {
+				return err
+			}
+
+			write := func(wallet *entity.Wallet, original *entity.Transaction, delta decimal.Decimal) error {
+				balanceBefore := wallet.Balance
+				wallet.Balance = wallet.Balance.Add(delta)
+				if err := repos.Wallets.Update(ctx, wallet); err != nil {
+					return fmt.Errorf("failed to update wallet: %w", err)
+				}
+
+				transaction := &entity.Transaction{
+					WalletID:       wallet.ID,
+					ReferenceID:    reference,
+					JournalEntryID: &entry.ID,
+					ReversalOfID:   &original.ID,
+					Type:           consts.TransactionTypeReversal,
+					Amount:         amount,
+					BalanceBefore:  balanceBefore,
+					BalanceAfter:   wallet.Balance,
+					Description:    description,
+				}
+				if err := repos.Transactions.Create(ctx, transaction); err != nil {
+					return fmt.Errorf("failed to create reversal transaction: %w", err)
+				}
+
+				result.Transactions = append(result.Transactions, transaction)
+				return nil
+			}
+
+			if from != nil {
+				if err := write(from, plan.from, amount.Neg()); err != nil {
+					return err
+				}
+			}
+			if to != nil {
+				if err := write(to, plan.to, amount); err != nil {
+					return err
+				}
+			}
+		}
+
+		if from != nil {
+			result.Hold, err = replaceShortfallHold(ctx, repos, from, plan.from, pending, amount, shortfall, reference, description)
+			if err != nil {
+				return err
+			}
+		}
+
+		result.Amount = amount
+		result.Remaining = remaining.Sub(amount)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// replaceShortfallHold releases the shortfall hold of an earlier reversal of original, captured
+// when this reversal collected money, and places a new one when this reversal fell short too.
+func replaceShortfallHold(ctx context.Context, repos *repository.Repositories, wallet *entity.Wallet, original *entity.Transaction, pending *entity.Hold, collected, shortfall decimal.Decimal, reference, description string) (*entity.Hold, error) {
+	if pending != nil {
+		if collected.IsPositive() {
+			pending.Status = consts.HoldStatusCaptured
+			pending.CapturedAmount = decimal.Min(pending.Amount, collected)
+			pending.CaptureReferenceID = &reference
+		} else {
+			pending.Status = consts.HoldStatusVoided
+		}
+		if err := repos.Holds.Update(ctx, pending); err != nil {
+			return nil, fmt.Errorf("failed to update reversal hold: %w", err)
+		}
+	}
+
+	if !shortfall.IsPositive() {
+		return nil, nil
+	}
+
+	hold := &entity.Hold{
+		WalletID:       wallet.ID,
+		ReferenceID:    reference + "-shortfall",
+		Amount:         shortfall,
+		CapturedAmount: decimal.Zero,
+		Currency:       wallet.Currency,
+		Status:         consts.HoldStatusActive,
+		Description:    description,
+		ReversalOfID:   &original.ID,
+		ExpiresAt:      time.Now().Add(reversalHoldTTL),
+	}
+	if err := repos.Holds.Create(ctx, hold); err != nil {
+		return nil, fmt.Errorf("failed to create reversal hold: %w", err)
+	}
+
+	return hold, nil
+}
+
+// reversalPlan names the original rows a reversal takes money back from and returns it to.
+// A deposit has nothing to return to and a withdrawal nothing to take from, the system
+// accounts stand in for them.
+type reversalPlan struct {
+	from *entity.Transaction
+	to   *entity.Transaction
+}
+
+func planReversal(originals []*entity.Transaction) (*reversalPlan, error) {
+	first := originals[0]
+
+	switch first.Type {
+	case consts.TransactionTypeDeposit:
+		if len(originals) == 1 {
+			return &reversalPlan{from: first}, nil
+		}
+	case consts.TransactionTypeWithdrawal:
+		if len(originals) == 1 {
+			return &reversalPlan{to: first}, nil
+		}
+	case consts.TransactionTypeTransfer:
+		if len(originals) != 2 {
+			return nil, errNotReversible
+		}
+
+		// The wallet that received the transfer pays it back
+		plan := &reversalPlan{}
+		for _, transaction := range originals {
+			if signedAmount(transaction).IsPositive() {
+				plan.from = transaction
+			} else {
+				plan.to = transaction
+			}
+		}
+		if plan.from != nil && plan.to != nil {
+			return plan, nil
+		}
+	case consts.TransactionTypeFXTransfer:
+		// The legs are in different currencies and undoing them would need a rate of its own
+		return nil, errFXNotReversible
+	}
+
+	return nil, errNotReversible
+}
+
+func (p *reversalPlan) walletIDs() []uuid.UUID {
+	var ids []uuid.UUID
+	for _, transaction := range []*entity.Transaction{p.from, p.to} {
+		if transaction != nil {
+			ids = append(ids, transaction.WalletID)
+		}
+	}
+	return ids
+}
+
+func validReversalPolicy(policy string) bool {
+	switch policy {
+	case consts.ReversalPolicyFail, consts.ReversalPolicyNegative, consts.ReversalPolicyHold:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,237 @@
+package accountusecase
+
+import (
+	"context"
+	stdErrors "errors"
+	"testing"
+	"time"
+
+	"wallet_api/internal/common/consts"
+	"wallet_api/internal/entity"
+	"wallet_api/internal/module/account/repository"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+func TestPlanReversal(t *testing.T) {
+	d := decimal.RequireFromString
+	row := func(txType, before, after string) *entity.Transaction {
+		return &entity.Transaction{ID: uuid.New(), WalletID: uuid.New(), Type: txType, Amount: d(after).Sub(d(before)).Abs(), BalanceBefore: d(before), BalanceAfter: d(after)}
+	}
+
+	deposit := row(consts.TransactionTypeDeposit, "0", "10")
+	plan, err := planReversal([]*entity.Transaction{deposit})
+	if err != nil || plan.from != deposit || plan.to != nil {
+		t.Errorf("deposit plan = %+v, %v, want money taken back from the deposit wallet", plan, err)
+	}
+
+	withdrawal := row(consts.TransactionTypeWithdrawal, "10", "0")
+	plan, err = planReversal([]*entity.Transaction{withdrawal})
+	if err != nil || plan.from != nil || plan.to != withdrawal {
+		t.Errorf("withdrawal plan = %+v, %v, want money returned to the withdrawal wallet", plan, err)
+	}
+
+	// Legs come back in any order, direction is taken from the balances
+	outgoing := row(consts.TransactionTypeTransfer, "50", "40")
+	incoming := row(consts.TransactionTypeTransfer, "0", "10")
+	plan, err = planReversal([]*entity.Transaction{incoming, outgoing})
+	if err != nil || plan.from != incoming || plan.to != outgoing {
+		t.Errorf("transfer plan = %+v, %v, want money moved from the receiver back to the sender", plan, err)
+	}
+
+	for _, originals := range [][]*entity.Transaction{
+		{row(consts.TransactionTypeReversal, "10", "0")},
+		{row(consts.TransactionTypeAdjustment, "0", "10")},
+		{outgoing},
+		{incoming, row(consts.TransactionTypeTransfer, "0", "10")},
+	} {
+		if _, err := planReversal(originals); err != errNotReversible {
+			t.Errorf("planReversal(%s x%d) error = %v, want errNotReversible", originals[0].Type, len(originals), err)
+		}
+	}
+	fxLegs := []*entity.Transaction{row(consts.TransactionTypeFXTransfer, "50", "40"), row(consts.TransactionTypeFXTransfer, "0", "155000")}
+	if _, err := planReversal(fxLegs); err != errFXNotReversible {
+		t.Errorf("planReversal(fx_transfer) error = %v, want errFXNotReversible", err)
+	}
+}
+
+// reversalTransactions serves the original rows and sums the reversals written so far
+type reversalTransactions struct {
+	repository.TransactionRepository
+	originals []*entity.Transaction
+	created   []*entity.Transaction
+}
+
+func (r *reversalTransactions) FindByReferenceID(_ context.Context, referenceID string) ([]*entity.Transaction, error) {
+	var found []*entity.Transaction
+	for _, transaction := range r.originals {
+		if transaction.ReferenceID == referenceID {
+			found = append(found, transaction)
+		}
+	}
+	return found, nil
+}
+
+func (r *reversalTransactions) ReversedAmount(_ context.Context, transactionID uuid.UUID) (decimal.Decimal, error) {
+	sum := decimal.Zero
+	for _, transaction := range r.created {
+		if transaction.ReversalOfID != nil && *transaction.ReversalOfID == transactionID {
+			sum = sum.Add(transaction.Amount)
+		}
+	}
+	return sum, nil
+}
+
+func (r *reversalTransactions) Create(_ context.Context, transaction *entity.Transaction) error {
+	r.created = append(r.created, transaction)
+	return nil
+}
+
+// savedWallets hands out copies and keeps a copy of every update, so only written changes stick
+type savedWallets struct {
+	repository.WalletRepository
+	saved map[uuid.UUID]entity.Wallet
+}
+
+func (r *savedWallets) FindByID(_ context.Context, id uuid.UUID) (*entity.Wallet, error) {
+	wallet, ok := r.saved[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &wallet, nil
+}
+
+func (r *savedWallets) FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*entity.Wallet, error) {
+	return r.FindByID(ctx, id)
+}
+
+func (r *savedWallets) Update(_ context.Context, wallet *entity.Wallet) error {
+	r.saved[wallet.ID] = *wallet
+	return nil
+}
+
+type verifiedUsers struct{}
+
+func (verifiedUsers) IsEmailVerified(context.Context, uuid.UUID) (bool, error) { return true, nil }
+
+func TestReverseDeposit(t *testing.T) {
+	userID := uuid.New()
+	// held is the amount of an active hold on the wallet
+	newCase := func(balance, held string) (*reversalUseCase, *useCase, *savedWallets, uuid.UUID) {
+		wallet := entity.Wallet{ID: uuid.New(), UserID: userID, Balance: decimal.RequireFromString(balance), Currency: "IDR", Status: consts.WalletStatusActive}
+		deposit := &entity.Transaction{
+			ID: uuid.New(), WalletID: wallet.ID, ReferenceID: "dep-1", Type: consts.TransactionTypeDeposit,
+			Amount: decimal.NewFromInt(10), BalanceBefore: decimal.Zero, BalanceAfter: decimal.NewFromInt(10),
+		}
+		hold := &entity.Hold{
+			ID: uuid.New(), WalletID: wallet.ID, Amount: decimal.RequireFromString(held),
+			Status: consts.HoldStatusActive, ExpiresAt: time.Now().Add(time.Hour),
+		}
+
+		wallets := &savedWallets{saved: map[uuid.UUID]entity.Wallet{wallet.ID: wallet}}
+		transactions := &reversalTransactions{originals: []*entity.Transaction{deposit}}
+		holds := &txHolds{holds: map[uuid.UUID]*entity.Hold{hold.ID: hold}}
+		uow := &fakeUnitOfWork{repos: &repository.Repositories{Wallets: wallets, Transactions: transactions, Ledger: &txLedger{}, Holds: holds}}
+
+		reversals := &reversalUseCase{transactionRepo: transactions, uow: uow, currencies: currencies, policy: consts.ReversalPolicyFail}
+		accounts := &useCase{walletRepo: wallets, uow: uow, users: verifiedUsers{}, currencies: currencies}
+		return reversals, accounts, wallets, wallet.ID
+	}
+	amount := func(value int64) *decimal.Decimal {
+		d := decimal.NewFromInt(value)
+		return &d
+	}
+
+	t.Run("partial then rest then refuse", func(t *testing.T) {
+		uc, _, wallets, walletID := newCase("10", "0")
+
+		reversal, err := uc.Reverse(context.Background(), ReversalRequest{ReferenceID: "dep-1", Amount: amount(4), Reason: "test"})
+		if err != nil {
+			t.Fatalf("Reverse() error = %v", err)
+		}
+		if balance := wallets.saved[walletID].Balance; !reversal.Remaining.Equal(decimal.NewFromInt(6)) || !balance.Equal(decimal.NewFromInt(6)) {
+			t.Errorf("remaining = %s, balance = %s, want 6 and 6", reversal.Remaining, balance)
+		}
+
+		if _, err := uc.Reverse(context.Background(), ReversalRequest{ReferenceID: "dep-1", Amount: amount(7), Reason: "test"}); err != errReversalTooLarge {
+			t.Errorf("Reverse() over the remaining amount error = %v, want errReversalTooLarge", err)
+		}
+
+		fraction := decimal.RequireFromString("0.001")
+		if _, err := uc.Reverse(context.Background(), ReversalRequest{ReferenceID: "dep-1", Amount: &fraction, Reason: "test"}); err == nil {
+			t.Errorf("Reverse(0.001 IDR) error = nil, want a precision error")
+		}
+
+		reversal, err = uc.Reverse(context.Background(), ReversalRequest{ReferenceID: "dep-1", Reason: "test"})
+		if balance := wallets.saved[walletID].Balance; err != nil || !reversal.Amount.Equal(decimal.NewFromInt(6)) || !balance.IsZero() {
+			t.Errorf("Reverse() rest = %+v, %v, balance %s, want 6 reversed to zero", reversal, err, balance)
+		}
+
+		if _, err := uc.Reverse(context.Background(), ReversalRequest{ReferenceID: "dep-1", Reason: "test"}); err != errAlreadyReversed {
+			t.Errorf("Reverse() again error = %v, want errAlreadyReversed", err)
+		}
+	})
+
+	t.Run("insufficient balance policies", func(t *testing.T) {
+		uc, _, _, _ := newCase("3", "0")
+		if _, err := uc.Reverse(context.Background(), ReversalRequest{ReferenceID: "dep-1", Reason: "test"}); !stdErrors.Is(err, errReversalInsufficientBalance) {
+			t.Errorf("fail policy error = %v, want errReversalInsufficientBalance", err)
+		}
+
+		uc, _, wallets, walletID := newCase("3", "0")
+		if _, err := uc.Reverse(context.Background(), ReversalRequest{ReferenceID: "dep-1", Reason: "test", Policy: consts.ReversalPolicyNegative}); err != nil {
+			t.Fatalf("negative policy error = %v", err)
+		}
+		if wallet := wallets.saved[walletID]; !wallet.Balance.Equal(decimal.NewFromInt(-7)) || wallet.Status != consts.WalletStatusActive {
+			t.Errorf("negative policy left balance %s status %s, want -7 active", wallet.Balance, wallet.Status)
+		}
+	})
+
+	t.Run("held funds do not cover a reversal", func(t *testing.T) {
+		uc, _, _, _ := newCase("10", "4")
+		if _, err := uc.Reverse(context.Background(), ReversalRequest{ReferenceID: "dep-1", Reason: "test"}); !stdErrors.Is(err, errReversalInsufficientBalance) {
+			t.Errorf("Reverse() with 6 available error = %v, want errReversalInsufficientBalance", err)
+		}
+	})
+
+	t.Run("hold policy holds the shortfall until it is collected", func(t *testing.T) {
+		uc, accounts, wallets, walletID := newCase("3", "0")
+		reversal, err := uc.Reverse(context.Background(), ReversalRequest{ReferenceID: "dep-1", Reason: "test", Policy: consts.ReversalPolicyHold})
+		if err != nil {
+			t.Fatalf("hold policy error = %v", err)
+		}
+		if !reversal.Amount.Equal(decimal.NewFromInt(3)) || !reversal.Remaining.Equal(decimal.NewFromInt(7)) || !wallets.saved[walletID].Balance.IsZero() {
+			t.Errorf("hold policy reversed %s, remaining %s, balance %s, want 3, 7 and 0", reversal.Amount, reversal.Remaining, wallets.saved[walletID].Balance)
+		}
+		hold := reversal.Hold
+		if hold == nil || !hold.Amount.Equal(decimal.NewFromInt(7)) || hold.ReversalOfID == nil || hold.Status != consts.HoldStatusActive {
+			t.Fatalf("hold policy hold = %+v, want an active 7 hold for the deposit", hold)
+		}
+
+		// The owner cannot release it, and money coming in is reserved for it
+		if _, err := accounts.VoidHold(context.Background(), userID, walletID, hold.ID); err != errHoldForReversal {
+			t.Errorf("VoidHold() error = %v, want errHoldForReversal", err)
+		}
+		wallet := wallets.saved[walletID]
+		wallet.Balance = decimal.NewFromInt(10)
+		wallets.saved[walletID] = wallet
+		err = accounts.Withdraw(context.Background(), userID, walletID, decimal.NewFromInt(5), "", StepUpProof{Preauthorized: true}, "")
+		if err != errInsufficientBalance {
+			t.Errorf("Withdraw(5) with 3 available error = %v, want errInsufficientBalance", err)
+		}
+
+		// Reversing the rest collects the held shortfall even under the fail policy
+		reversal, err = uc.Reverse(context.Background(), ReversalRequest{ReferenceID: "dep-1", Reason: "test"})
+		if err != nil {
+			t.Fatalf("Reverse() rest error = %v", err)
+		}
+		if !reversal.Amount.Equal(decimal.NewFromInt(7)) || reversal.Hold != nil || !wallets.saved[walletID].Balance.Equal(decimal.NewFromInt(3)) {
+			t.Errorf("Reverse() rest = %s, hold %v, balance %s, want 7, none and 3", reversal.Amount, reversal.Hold, wallets.saved[walletID].Balance)
+		}
+		if hold.Status != consts.HoldStatusCaptured || !hold.CapturedAmount.Equal(decimal.NewFromInt(7)) {
+			t.Errorf("shortfall hold status %s captured %s, want captured 7", hold.Status, hold.CapturedAmount)
+		}
+	})
+}
@@ -0,0 +1,26 @@
+package apikey
+
+import (
+	"wallet_api/internal/module/apikey/handler"
+	"wallet_api/internal/module/apikey/repository"
+	apikeyusecase "wallet_api/internal/module/apikey/usecase"
+	"wallet_api/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+type Module struct {
+	UseCase apikeyusecase.UseCase
+	Handler *handler.Handler
+}
+
+func NewModule(db *gorm.DB, log logger.Interface, wallets apikeyusecase.WalletAccess) *Module {
+	repo := repository.New(db)
+	uc := apikeyusecase.New(repo, wallets)
+	h := handler.New(uc, log)
+
+	return &Module{
+		UseCase: uc,
+		Handler: h,
+	}
+}
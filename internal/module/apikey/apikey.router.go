@@ -0,0 +1,24 @@
+package apikey
+
+import (
+	"wallet_api/internal/common/consts"
+	"wallet_api/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Key management needs a real login; an API key cannot mint or revoke keys
+func (m *Module) RegisterRoutes(app *fiber.App, auth fiber.Handler) {
+	keys := app.Group("/v1/api-keys", auth)
+	{
+		keys.Post("/", m.Handler.CreateAPIKey)
+		keys.Get("/", m.Handler.ListAPIKeys)
+		keys.Delete("/:id", m.Handler.RevokeAPIKey)
+	}
+
+	admin := app.Group("/v1/admin/api-keys", auth, middleware.RequireRole(consts.RoleAdmin))
+	{
+		admin.Get("/", m.Handler.AdminListAPIKeys)
+		admin.Delete("/:id", m.Handler.AdminRevokeAPIKey)
+	}
+}
@@ -0,0 +1,56 @@
+package response
+
+import (
+	"time"
+	"wallet_api/internal/entity"
+)
+
+type APIKeyResponse struct {
+	ID         string   `json:"id"`
+	UserID     string   `json:"user_id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	WalletIDs  []string `json:"wallet_ids"`
+	ExpiresAt  string   `json:"expires_at"`
+	LastUsedAt *string  `json:"last_used_at"`
+	RevokedAt  *string  `json:"revoked_at"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+// CreatedAPIKeyResponse includes the plaintext key; it cannot be retrieved again
+type CreatedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+func ToAPIKeyDto(key *entity.APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:         key.ID.String(),
+		UserID:     key.UserID.String(),
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		WalletIDs:  key.WalletIDs,
+		ExpiresAt:  key.ExpiresAt.Format(time.RFC3339),
+		LastUsedAt: formatOptionalTime(key.LastUsedAt),
+		RevokedAt:  formatOptionalTime(key.RevokedAt),
+		CreatedAt:  key.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func ToAPIKeyDtos(keys []*entity.APIKey) []APIKeyResponse {
+	responses := make([]APIKeyResponse, len(keys))
+	for i, key := range keys {
+		responses[i] = ToAPIKeyDto(key)
+	}
+	return responses
+}
+
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format(time.RFC3339)
+	return &formatted
+}
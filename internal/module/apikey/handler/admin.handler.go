@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"wallet_api/internal/common/response"
+	resp "wallet_api/internal/module/apikey/dto/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) AdminListAPIKeys(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Query("user_id"))
+	if err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid user ID"))
+	}
+
+	keys, err := h.uc.List(c.Context(), userID)
+	if err != nil {
+		h.log.Error("failed to list api keys: %v", err)
+		return c.Status(500).JSON(response.Error(500, "Failed to get API keys"))
+	}
+
+	return c.JSON(response.Success(resp.ToAPIKeyDtos(keys), "API keys retrieved"))
+}
+
+func (h *Handler) AdminRevokeAPIKey(c *fiber.Ctx) error {
+	keyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid API key ID"))
+	}
+
+	if err := h.uc.AdminRevoke(c.Context(), keyID); err != nil {
+		h.log.Error("failed to revoke api key: %v", err)
+		return writeError(c, err, "Failed to revoke API key")
+	}
+
+	return c.JSON(response.Success(nil, "API key revoked"))
+}
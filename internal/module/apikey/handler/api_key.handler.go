@@ -0,0 +1,99 @@
+package handler
+
+import (
+	stdErrors "errors"
+	"time"
+
+	"wallet_api/internal/common/errors"
+	"wallet_api/internal/common/response"
+	"wallet_api/internal/module/apikey/dto/request"
+	resp "wallet_api/internal/module/apikey/dto/response"
+	apikeyusecase "wallet_api/internal/module/apikey/usecase"
+	"wallet_api/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	uc  apikeyusecase.UseCase
+	log logger.Interface
+}
+
+func New(uc apikeyusecase.UseCase, log logger.Interface) *Handler {
+	return &Handler{
+		uc:  uc,
+		log: log,
+	}
+}
+
+func (h *Handler) CreateAPIKey(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	req := new(request.CreateAPIKeyRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid request body"))
+	}
+
+	walletIDs := make([]uuid.UUID, 0, len(req.WalletIDs))
+	for _, id := range req.WalletIDs {
+		walletID, err := uuid.Parse(id)
+		if err != nil {
+			return c.Status(400).JSON(response.Error(400, "Invalid wallet ID"))
+		}
+		walletIDs = append(walletIDs, walletID)
+	}
+
+	created, err := h.uc.Create(c.Context(), userID, apikeyusecase.CreateInput{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		WalletIDs: walletIDs,
+		ExpiresIn: time.Duration(req.ExpiresInDays) * 24 * time.Hour,
+	})
+	if err != nil {
+		h.log.Error("failed to create api key: %v", err)
+		return writeError(c, err, "Failed to create API key")
+	}
+
+	return c.JSON(response.Success(resp.CreatedAPIKeyResponse{
+		APIKeyResponse: resp.ToAPIKeyDto(created.Key),
+		Key:            created.Secret,
+	}, "API key created, store it now because it will not be shown again"))
+}
+
+func (h *Handler) ListAPIKeys(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	keys, err := h.uc.List(c.Context(), userID)
+	if err != nil {
+		h.log.Error("failed to list api keys: %v", err)
+		return c.Status(500).JSON(response.Error(500, "Failed to get API keys"))
+	}
+
+	return c.JSON(response.Success(resp.ToAPIKeyDtos(keys), "API keys retrieved"))
+}
+
+func (h *Handler) RevokeAPIKey(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	keyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid API key ID"))
+	}
+
+	if err := h.uc.Revoke(c.Context(), userID, keyID); err != nil {
+		h.log.Error("failed to revoke api key: %v", err)
+		return writeError(c, err, "Failed to revoke API key")
+	}
+
+	return c.JSON(response.Success(nil, "API key revoked"))
+}
+
+// writeError maps AppErrors to their status and everything else to a 500
+func writeError(c *fiber.Ctx, err error, message string) error {
+	var appErr *errors.AppError
+	if stdErrors.As(err, &appErr) {
+		return c.Status(appErr.Code).JSON(response.Error(appErr.Code, appErr.Message))
+	}
+	return c.Status(500).JSON(response.Error(500, message))
+}
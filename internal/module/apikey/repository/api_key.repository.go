@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"wallet_api/internal/common/base"
+	"wallet_api/internal/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *entity.APIKey) error
+	FindByID(ctx context.Context, id uuid.UUID) (*entity.APIKey, error)
+	FindByHash(ctx context.Context, keyHash string) (*entity.APIKey, error)
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.APIKey, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+	TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+type apiKeyRepository struct {
+	*base.BaseRepository[entity.APIKey]
+	db *gorm.DB
+}
+
+func New(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{
+		BaseRepository: base.NewBaseRepository[entity.APIKey](db),
+		db:             db,
+	}
+}
+
+// FindByHash returns nil when no key matches
+func (r *apiKeyRepository) FindByHash(ctx context.Context, keyHash string) (*entity.APIKey, error) {
+	key, err := r.NewQueryBuilder().
+		Where("key_hash", keyHash).
+		Preload("User").
+		FindOne(ctx)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+func (r *apiKeyRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.APIKey, error) {
+	return r.NewQueryBuilder().
+		Where("user_id", userID).
+		OrderBy("created_at DESC").
+		Find(ctx)
+}
+
+func (r *apiKeyRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Model(&entity.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).
+		Error
+}
+
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return r.UpdateFields(ctx, id, map[string]interface{}{"last_used_at": at})
+}
@@ -0,0 +1,225 @@
+package apikeyusecase
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"wallet_api/internal/common/consts"
+	"wallet_api/internal/common/errors"
+	"wallet_api/internal/entity"
+	"wallet_api/internal/module/apikey/repository"
+	"wallet_api/internal/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	keyPrefix        = "wk_"
+	keyRandomBytes   = 32
+	displayPrefixLen = 11 // "wk_" + 8 hex chars
+	defaultKeyTTL    = 90 * 24 * time.Hour
+	maxKeyTTL        = 365 * 24 * time.Hour
+	// Don't write last_used_at on every single request
+	lastUsedResolution = time.Minute
+)
+
+var (
+	ErrInvalidAPIKey  = errors.New(401, "Invalid API key", nil)
+	ErrAPIKeyNotFound = errors.New(404, "API key not found", nil)
+	errNoScopes       = errors.New(400, "At least one scope is required", nil)
+	errInvalidExpiry  = errors.New(400, "Expiry must be between 1 and 365 days", nil)
+	errNameRequired   = errors.New(400, "Name is required", nil)
+)
+
+// validScopes lists every scope a key can be granted
+var validScopes = []string{
+	consts.ScopeWalletsRead,
+	consts.ScopeWalletsWrite,
+	consts.ScopeTransfersCreate,
+}
+
+// WalletAccess is the part of the account module used to check wallet ownership
+type WalletAccess interface {
+	GetWallet(ctx context.Context, userID, walletID uuid.UUID) (*entity.Wallet, error)
+}
+
+type CreateInput struct {
+	Name      string
+	Scopes    []string
+	WalletIDs []uuid.UUID
+	ExpiresIn time.Duration // 0 = default
+}
+
+// CreatedKey carries the plaintext key, which is only ever shown once
+type CreatedKey struct {
+	Key    *entity.APIKey
+	Secret string
+}
+
+type UseCase interface {
+	Create(ctx context.Context, userID uuid.UUID, input CreateInput) (*CreatedKey, error)
+	List(ctx context.Context, userID uuid.UUID) ([]*entity.APIKey, error)
+	Revoke(ctx context.Context, userID, keyID uuid.UUID) error
+
+	// Admin
+	AdminRevoke(ctx context.Context, keyID uuid.UUID) error
+
+	// Dipakai oleh middleware.APIKeyAuth
+	ValidateAPIKey(ctx context.Context, rawKey string) (*entity.APIKey, error)
+}
+
+type useCase struct {
+	repo    repository.APIKeyRepository
+	wallets WalletAccess
+}
+
+func New(repo repository.APIKeyRepository, wallets WalletAccess) UseCase {
+	return &useCase{
+		repo:    repo,
+		wallets: wallets,
+	}
+}
+
+func (uc *useCase) Create(ctx context.Context, userID uuid.UUID, input CreateInput) (*CreatedKey, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, errNameRequired
+	}
+
+	scopes, err := normalizeScopes(input.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := input.ExpiresIn
+	if ttl == 0 {
+		ttl = defaultKeyTTL
+	}
+	if ttl < 0 || ttl > maxKeyTTL {
+		return nil, errInvalidExpiry
+	}
+
+	// A key can only be restricted to wallets its owner can already access
+	walletIDs := entity.StringArray{}
+	for _, walletID := range input.WalletIDs {
+		if _, err := uc.wallets.GetWallet(ctx, userID, walletID); err != nil {
+			return nil, err
+		}
+		if !walletIDs.Contains(walletID.String()) {
+			walletIDs = append(walletIDs, walletID.String())
+		}
+	}
+
+	random, err := utils.GenerateRandomToken(keyRandomBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	secret := keyPrefix + random
+
+	key := &entity.APIKey{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    secret[:displayPrefixLen],
+		KeyHash:   utils.HashToken(secret),
+		Scopes:    scopes,
+		WalletIDs: walletIDs,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+
+	if err := uc.repo.Create(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return &CreatedKey{Key: key, Secret: secret}, nil
+}
+
+func (uc *useCase) List(ctx context.Context, userID uuid.UUID) ([]*entity.APIKey, error) {
+	return uc.repo.FindByUserID(ctx, userID)
+}
+
+func (uc *useCase) Revoke(ctx context.Context, userID, keyID uuid.UUID) error {
+	key, err := uc.findKey(ctx, keyID)
+	if err != nil {
+		return err
+	}
+	// Same answer as a missing key so ids of other users' keys are not leaked
+	if key.UserID != userID {
+		return ErrAPIKeyNotFound
+	}
+
+	return uc.repo.Revoke(ctx, keyID)
+}
+
+func (uc *useCase) AdminRevoke(ctx context.Context, keyID uuid.UUID) error {
+	if _, err := uc.findKey(ctx, keyID); err != nil {
+		return err
+	}
+
+	return uc.repo.Revoke(ctx, keyID)
+}
+
+func (uc *useCase) ValidateAPIKey(ctx context.Context, rawKey string) (*entity.APIKey, error) {
+	if !strings.HasPrefix(rawKey, keyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := uc.repo.FindByHash(ctx, utils.HashToken(rawKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find api key: %w", err)
+	}
+
+	now := time.Now()
+	if key == nil || !key.IsActive(now) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > lastUsedResolution {
+		// Best effort, a failed bookkeeping write must not reject the request
+		_ = uc.repo.TouchLastUsed(ctx, key.ID, now)
+	}
+
+	return key, nil
+}
+
+func (uc *useCase) findKey(ctx context.Context, keyID uuid.UUID) (*entity.APIKey, error) {
+	key, err := uc.repo.FindByID(ctx, keyID)
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to find api key: %w", err)
+	}
+	return key, nil
+}
+
+// normalizeScopes validates the requested scopes and drops duplicates
+func normalizeScopes(requested []string) (entity.StringArray, error) {
+	scopes := entity.StringArray{}
+	for _, scope := range requested {
+		scope = strings.TrimSpace(scope)
+
+		valid := false
+		for _, s := range validScopes {
+			if s == scope {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, errors.New(400, fmt.Sprintf("Invalid scope: %s", scope), nil)
+		}
+
+		if !scopes.Contains(scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	if len(scopes) == 0 {
+		return nil, errNoScopes
+	}
+	return scopes, nil
+}
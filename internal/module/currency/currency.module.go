@@ -0,0 +1,25 @@
+package currency
+
+import (
+	"wallet_api/internal/module/currency/handler"
+	"wallet_api/internal/module/currency/repository"
+	currencyusecase "wallet_api/internal/module/currency/usecase"
+	"wallet_api/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+type Module struct {
+	UseCase currencyusecase.UseCase
+	Handler *handler.Handler
+}
+
+func NewModule(db *gorm.DB, log logger.Interface) *Module {
+	uc := currencyusecase.New(repository.New(db))
+	h := handler.New(uc, log)
+
+	return &Module{
+		UseCase: uc,
+		Handler: h,
+	}
+}
@@ -0,0 +1,19 @@
+package currency
+
+import (
+	"wallet_api/internal/common/consts"
+	"wallet_api/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// The list of enabled currencies is public, clients need it before the user has a wallet
+func (m *Module) RegisterRoutes(app *fiber.App, auth fiber.Handler) {
+	app.Get("/v1/currencies", m.Handler.ListCurrencies)
+
+	admin := app.Group("/v1/admin/currencies", auth, middleware.RequireRole(consts.RoleAdmin))
+	{
+		admin.Get("/", m.Handler.AdminListCurrencies)
+		admin.Patch("/:code", m.Handler.UpdateCurrency)
+	}
+}
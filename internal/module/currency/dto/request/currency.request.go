@@ -0,0 +1,5 @@
+package request
+
+type UpdateCurrencyRequest struct {
+	Enabled *bool `json:"enabled" validate:"required"`
+}
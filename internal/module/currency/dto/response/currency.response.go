@@ -0,0 +1,27 @@
+package response
+
+import "wallet_api/internal/entity"
+
+type CurrencyResponse struct {
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	Exponent int32  `json:"exponent"`
+	Enabled  bool   `json:"enabled"`
+}
+
+func ToCurrencyDto(currency *entity.Currency) CurrencyResponse {
+	return CurrencyResponse{
+		Code:     currency.Code,
+		Name:     currency.Name,
+		Exponent: currency.Exponent,
+		Enabled:  currency.Enabled,
+	}
+}
+
+func ToCurrencyDtos(currencies []*entity.Currency) []CurrencyResponse {
+	dtos := make([]CurrencyResponse, len(currencies))
+	for i, currency := range currencies {
+		dtos[i] = ToCurrencyDto(currency)
+	}
+	return dtos
+}
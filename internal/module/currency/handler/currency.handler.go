@@ -0,0 +1,71 @@
+package handler
+
+import (
+	stdErrors "errors"
+
+	"wallet_api/internal/common/errors"
+	"wallet_api/internal/common/response"
+	"wallet_api/internal/module/currency/dto/request"
+	resp "wallet_api/internal/module/currency/dto/response"
+	currencyusecase "wallet_api/internal/module/currency/usecase"
+	"wallet_api/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Handler struct {
+	uc  currencyusecase.UseCase
+	log logger.Interface
+}
+
+func New(uc currencyusecase.UseCase, log logger.Interface) *Handler {
+	return &Handler{
+		uc:  uc,
+		log: log,
+	}
+}
+
+// ListCurrencies returns the currencies new wallets can be opened in
+func (h *Handler) ListCurrencies(c *fiber.Ctx) error {
+	currencies, err := h.uc.List(c.Context(), false)
+	if err != nil {
+		h.log.Error("failed to list currencies: %v", err)
+		return c.Status(500).JSON(response.Error(500, "Failed to get currencies"))
+	}
+
+	return c.JSON(response.Success(resp.ToCurrencyDtos(currencies), "Currencies retrieved"))
+}
+
+func (h *Handler) AdminListCurrencies(c *fiber.Ctx) error {
+	currencies, err := h.uc.List(c.Context(), true)
+	if err != nil {
+		h.log.Error("failed to list currencies: %v", err)
+		return c.Status(500).JSON(response.Error(500, "Failed to get currencies"))
+	}
+
+	return c.JSON(response.Success(resp.ToCurrencyDtos(currencies), "Currencies retrieved"))
+}
+
+func (h *Handler) UpdateCurrency(c *fiber.Ctx) error {
+	req := new(request.UpdateCurrencyRequest)
+	if err := c.BodyParser(req); err != nil || req.Enabled == nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid request body"))
+	}
+
+	currency, err := h.uc.SetEnabled(c.Context(), c.Params("code"), *req.Enabled)
+	if err != nil {
+		h.log.Error("failed to update currency: %v", err)
+		return writeError(c, err, "Failed to update currency")
+	}
+
+	return c.JSON(response.Success(resp.ToCurrencyDto(currency), "Currency updated"))
+}
+
+// writeError maps AppErrors to their status and everything else to a 500
+func writeError(c *fiber.Ctx, err error, message string) error {
+	var appErr *errors.AppError
+	if stdErrors.As(err, &appErr) {
+		return c.Status(appErr.Code).JSON(response.Error(appErr.Code, appErr.Message))
+	}
+	return c.Status(500).JSON(response.Error(500, message))
+}
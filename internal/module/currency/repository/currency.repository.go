@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+
+	"wallet_api/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+type CurrencyRepository interface {
+	// FindByCode returns gorm.ErrRecordNotFound for currencies outside the registry
+	FindByCode(ctx context.Context, code string) (*entity.Currency, error)
+	FindAll(ctx context.Context, enabledOnly bool) ([]*entity.Currency, error)
+	// SetEnabled reports whether the currency exists
+	SetEnabled(ctx context.Context, code string, enabled bool) (bool, error)
+}
+
+type currencyRepository struct {
+	db *gorm.DB
+}
+
+func New(db *gorm.DB) CurrencyRepository {
+	return &currencyRepository{db: db}
+}
+
+func (r *currencyRepository) FindByCode(ctx context.Context, code string) (*entity.Currency, error) {
+	var currency entity.Currency
+	if err := r.db.WithContext(ctx).Where("code = ?", code).First(&currency).Error; err != nil {
+		return nil, err
+	}
+	return &currency, nil
+}
+
+func (r *currencyRepository) FindAll(ctx context.Context, enabledOnly bool) ([]*entity.Currency, error) {
+	query := r.db.WithContext(ctx).Order("code")
+	if enabledOnly {
+		query = query.Where("enabled = ?", true)
+	}
+
+	var currencies []*entity.Currency
+	if err := query.Find(&currencies).Error; err != nil {
+		return nil, err
+	}
+	return currencies, nil
+}
+
+func (r *currencyRepository) SetEnabled(ctx context.Context, code string, enabled bool) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&entity.Currency{}).
+		Where("code = ?", code).
+		Updates(map[string]interface{}{"enabled": enabled, "updated_at": gorm.Expr("CURRENT_TIMESTAMP")})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
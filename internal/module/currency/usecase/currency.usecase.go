@@ -0,0 +1,90 @@
+package currencyusecase
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"strings"
+
+	"wallet_api/internal/common/errors"
+	"wallet_api/internal/entity"
+	"wallet_api/internal/module/currency/repository"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUnsupportedCurrency = errors.New(400, "Currency is not supported", nil)
+	ErrCurrencyDisabled    = errors.New(400, "Currency is not enabled", nil)
+
+	errCurrencyNotFound = errors.New(404, "Currency not found", nil)
+)
+
+type UseCase interface {
+	// Lookup returns a currency of the registry, disabled ones included
+	Lookup(ctx context.Context, code string) (*entity.Currency, error)
+	List(ctx context.Context, includeDisabled bool) ([]*entity.Currency, error)
+	SetEnabled(ctx context.Context, code string, enabled bool) (*entity.Currency, error)
+}
+
+type useCase struct {
+	repo repository.CurrencyRepository
+}
+
+func New(repo repository.CurrencyRepository) UseCase {
+	return &useCase{repo: repo}
+}
+
+func (uc *useCase) Lookup(ctx context.Context, code string) (*entity.Currency, error) {
+	currency, err := uc.repo.FindByCode(ctx, Normalize(code))
+	if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUnsupportedCurrency
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find currency: %w", err)
+	}
+
+	return currency, nil
+}
+
+func (uc *useCase) List(ctx context.Context, includeDisabled bool) ([]*entity.Currency, error) {
+	currencies, err := uc.repo.FindAll(ctx, !includeDisabled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list currencies: %w", err)
+	}
+
+	return currencies, nil
+}
+
+func (uc *useCase) SetEnabled(ctx context.Context, code string, enabled bool) (*entity.Currency, error) {
+	code = Normalize(code)
+
+	found, err := uc.repo.SetEnabled(ctx, code, enabled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update currency: %w", err)
+	}
+	if !found {
+		return nil, errCurrencyNotFound
+	}
+
+	return uc.Lookup(ctx, code)
+}
+
+// Normalize upper-cases a currency code, the registry stores ISO 4217 codes as they are written
+func Normalize(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// CheckAmount rejects amounts finer than the smallest unit of the currency, instead of
+// letting Postgres round them when they are stored
+func CheckAmount(currency *entity.Currency, amount decimal.Decimal) error {
+	if currency.Fits(amount) {
+		return nil
+	}
+
+	if currency.Exponent == 0 {
+		return errors.New(400, fmt.Sprintf("%s amounts cannot have decimal places", currency.Code), nil)
+	}
+	return errors.New(400, fmt.Sprintf("%s amounts allow at most %d decimal places", currency.Code, currency.Exponent), nil)
+}
@@ -0,0 +1,31 @@
+package request
+
+type CreateRateRequest struct {
+	BaseCurrency  string `json:"base_currency" validate:"required"`
+	QuoteCurrency string `json:"quote_currency" validate:"required"`
+	// 1 base_currency = rate quote_currency
+	Rate string `json:"rate" validate:"required"`
+	// Pecahan, 0.005 = 0.5%. Kosong = 0
+	Spread string `json:"spread"`
+	// RFC3339, kosong = sekarang
+	ValidFrom string `json:"valid_from"`
+	// RFC3339, kosong = berlaku sampai ada rate yang lebih baru
+	ValidTo string `json:"valid_to"`
+}
+
+type CreateQuoteRequest struct {
+	SourceCurrency      string `json:"source_currency" validate:"required"`
+	DestinationCurrency string `json:"destination_currency" validate:"required"`
+	// Dalam source currency
+	Amount string `json:"amount" validate:"required,gt=0"`
+}
+
+type FXTransferRequest struct {
+	QuoteID      string `json:"quote_id" validate:"required"`
+	FromWalletID string `json:"from_wallet_id" validate:"required"`
+	ToWalletID   string `json:"to_wallet_id" validate:"required"`
+	Description  string `json:"description"`
+	// Wajib untuk source amount di atas batas step-up, isi salah satu
+	PIN         string `json:"pin"`
+	StepUpToken string `json:"step_up_token"`
+}
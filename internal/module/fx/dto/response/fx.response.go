@@ -0,0 +1,79 @@
+package response
+
+import (
+	"time"
+	"wallet_api/internal/entity"
+)
+
+type RateResponse struct {
+	ID            string  `json:"id"`
+	BaseCurrency  string  `json:"base_currency"`
+	QuoteCurrency string  `json:"quote_currency"`
+	Rate          string  `json:"rate"`
+	Spread        string  `json:"spread"`
+	ValidFrom     string  `json:"valid_from"`
+	ValidTo       *string `json:"valid_to"`
+	CreatedAt     string  `json:"created_at"`
+}
+
+type QuoteResponse struct {
+	ID                  string `json:"id"`
+	SourceCurrency      string `json:"source_currency"`
+	DestinationCurrency string `json:"destination_currency"`
+	Rate                string `json:"rate"`
+	Spread              string `json:"spread"`
+	// Rate setelah spread, yang dipakai untuk menghitung destination_amount
+	CustomerRate      string  `json:"customer_rate"`
+	SourceAmount      string  `json:"source_amount"`
+	DestinationAmount string  `json:"destination_amount"`
+	ExpiresAt         string  `json:"expires_at"`
+	UsedAt            *string `json:"used_at"`
+	ReferenceID       *string `json:"reference_id"`
+	CreatedAt         string  `json:"created_at"`
+}
+
+func ToRateDto(rate *entity.FXRate) RateResponse {
+	return RateResponse{
+		ID:            rate.ID.String(),
+		BaseCurrency:  rate.BaseCurrency,
+		QuoteCurrency: rate.QuoteCurrency,
+		Rate:          rate.Rate.String(),
+		Spread:        rate.Spread.String(),
+		ValidFrom:     rate.ValidFrom.Format(time.RFC3339),
+		ValidTo:       formatOptionalTime(rate.ValidTo),
+		CreatedAt:     rate.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func ToRateDtos(rates []*entity.FXRate) []RateResponse {
+	responses := make([]RateResponse, len(rates))
+	for i, rate := range rates {
+		responses[i] = ToRateDto(rate)
+	}
+	return responses
+}
+
+func ToQuoteDto(quote *entity.FXQuote) QuoteResponse {
+	return QuoteResponse{
+		ID:                  quote.ID.String(),
+		SourceCurrency:      quote.SourceCurrency,
+		DestinationCurrency: quote.DestinationCurrency,
+		Rate:                quote.Rate.String(),
+		Spread:              quote.Spread.String(),
+		CustomerRate:        quote.CustomerRate().String(),
+		SourceAmount:        quote.SourceAmount.String(),
+		DestinationAmount:   quote.DestinationAmount.String(),
+		ExpiresAt:           quote.ExpiresAt.Format(time.RFC3339),
+		UsedAt:              formatOptionalTime(quote.UsedAt),
+		ReferenceID:         quote.ReferenceID,
+		CreatedAt:           quote.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format(time.RFC3339)
+	return &formatted
+}
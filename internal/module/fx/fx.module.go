@@ -0,0 +1,30 @@
+package fx
+
+import (
+	"wallet_api/config"
+	"wallet_api/internal/module/fx/handler"
+	"wallet_api/internal/module/fx/repository"
+	fxusecase "wallet_api/internal/module/fx/usecase"
+	"wallet_api/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+type Module struct {
+	RateUseCase  fxusecase.RateUseCase
+	QuoteUseCase fxusecase.QuoteUseCase
+	Handler      *handler.Handler
+}
+
+func NewModule(db *gorm.DB, log logger.Interface, cfg *config.Config, transfers fxusecase.Transfers, currencies fxusecase.Currencies) *Module {
+	repo := repository.New(db)
+	rateUC := fxusecase.NewRateUseCase(repo)
+	quoteUC := fxusecase.NewQuoteUseCase(repo, transfers, currencies, cfg.FX.QuoteTTL)
+	h := handler.New(rateUC, quoteUC, log)
+
+	return &Module{
+		RateUseCase:  rateUC,
+		QuoteUseCase: quoteUC,
+		Handler:      h,
+	}
+}
@@ -0,0 +1,24 @@
+package fx
+
+import (
+	"wallet_api/internal/common/consts"
+	"wallet_api/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Quotes and conversions are transfers, so API keys need the transfer scope for them
+func (m *Module) RegisterRoutes(app *fiber.App, auth, walletAuth, idempotent fiber.Handler) {
+	fx := app.Group("/v1/fx", walletAuth, middleware.RequireScope(consts.ScopeTransfersCreate))
+	{
+		fx.Post("/quotes", m.Handler.CreateQuote)
+		fx.Get("/quotes/:quote_id", m.Handler.GetQuote)
+		fx.Post("/transfers", idempotent, m.Handler.Transfer)
+	}
+
+	admin := app.Group("/v1/admin/fx/rates", auth, middleware.RequireRole(consts.RoleAdmin))
+	{
+		admin.Post("/", m.Handler.CreateRate)
+		admin.Get("/", m.Handler.ListRates)
+	}
+}
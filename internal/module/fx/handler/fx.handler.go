@@ -0,0 +1,180 @@
+package handler
+
+import (
+	stdErrors "errors"
+	"time"
+
+	"wallet_api/internal/common/errors"
+	"wallet_api/internal/common/response"
+	"wallet_api/internal/middleware"
+	accountusecase "wallet_api/internal/module/account/usecase"
+	"wallet_api/internal/module/fx/dto/request"
+	resp "wallet_api/internal/module/fx/dto/response"
+	fxusecase "wallet_api/internal/module/fx/usecase"
+	"wallet_api/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type Handler struct {
+	rates  fxusecase.RateUseCase
+	quotes fxusecase.QuoteUseCase
+	log    logger.Interface
+}
+
+func New(rates fxusecase.RateUseCase, quotes fxusecase.QuoteUseCase, log logger.Interface) *Handler {
+	return &Handler{
+		rates:  rates,
+		quotes: quotes,
+		log:    log,
+	}
+}
+
+func (h *Handler) CreateQuote(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	req := new(request.CreateQuoteRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid request body"))
+	}
+
+	amount, err := decimal.NewFromString(req.Amount)
+	if err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid amount format"))
+	}
+
+	quote, err := h.quotes.CreateQuote(c.Context(), userID, req.SourceCurrency, req.DestinationCurrency, amount)
+	if err != nil {
+		h.log.Error("failed to create fx quote: %v", err)
+		return writeError(c, err, "Failed to create quote")
+	}
+
+	return c.JSON(response.Success(resp.ToQuoteDto(quote), "Quote created"))
+}
+
+func (h *Handler) GetQuote(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	quoteID, err := uuid.Parse(c.Params("quote_id"))
+	if err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid quote ID"))
+	}
+
+	quote, err := h.quotes.GetQuote(c.Context(), userID, quoteID)
+	if err != nil {
+		h.log.Error("failed to get fx quote: %v", err)
+		return writeError(c, err, "Failed to get quote")
+	}
+
+	return c.JSON(response.Success(resp.ToQuoteDto(quote), "Quote retrieved"))
+}
+
+func (h *Handler) Transfer(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	req := new(request.FXTransferRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid request body"))
+	}
+
+	quoteID, err := uuid.Parse(req.QuoteID)
+	if err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid quote ID"))
+	}
+	fromWalletID, err := uuid.Parse(req.FromWalletID)
+	if err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid from wallet ID"))
+	}
+	toWalletID, err := uuid.Parse(req.ToWalletID)
+	if err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid to wallet ID"))
+	}
+
+	// The wallet is in the body rather than the path, so RequireScope cannot check it
+	if !middleware.AllowsWallet(c, fromWalletID) {
+		return c.Status(403).JSON(response.Error(403, "API key is not allowed to access this wallet"))
+	}
+
+	proof := accountusecase.StepUpProof{PIN: req.PIN, StepUpToken: req.StepUpToken}
+	quote, err := h.quotes.Transfer(c.Context(), userID, quoteID, fromWalletID, toWalletID, req.Description, proof)
+	if err != nil {
+		h.log.Error("failed to transfer with fx quote: %v", err)
+		return writeError(c, err, "Failed to transfer")
+	}
+
+	return c.JSON(response.Success(resp.ToQuoteDto(quote), "Transfer successful"))
+}
+
+func (h *Handler) CreateRate(c *fiber.Ctx) error {
+	req := new(request.CreateRateRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid request body"))
+	}
+
+	input := fxusecase.RateInput{
+		BaseCurrency:  req.BaseCurrency,
+		QuoteCurrency: req.QuoteCurrency,
+		Spread:        decimal.Zero,
+	}
+
+	var err error
+	if input.Rate, err = decimal.NewFromString(req.Rate); err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid rate format"))
+	}
+	if req.Spread != "" {
+		if input.Spread, err = decimal.NewFromString(req.Spread); err != nil {
+			return c.Status(400).JSON(response.Error(400, "Invalid spread format"))
+		}
+	}
+	if req.ValidFrom != "" {
+		if input.ValidFrom, err = time.Parse(time.RFC3339, req.ValidFrom); err != nil {
+			return c.Status(400).JSON(response.Error(400, "Invalid valid_from, use RFC3339"))
+		}
+	}
+	if req.ValidTo != "" {
+		validTo, err := time.Parse(time.RFC3339, req.ValidTo)
+		if err != nil {
+			return c.Status(400).JSON(response.Error(400, "Invalid valid_to, use RFC3339"))
+		}
+		input.ValidTo = &validTo
+	}
+
+	rate, err := h.rates.CreateRate(c.Context(), input)
+	if err != nil {
+		h.log.Error("failed to create fx rate: %v", err)
+		return writeError(c, err, "Failed to create rate")
+	}
+
+	return c.JSON(response.Success(resp.ToRateDto(rate), "Rate created"))
+}
+
+func (h *Handler) ListRates(c *fiber.Ctx) error {
+	limit := 50
+	offset := 0
+
+	if l := c.QueryInt("limit", 50); l > 0 {
+		limit = l
+	}
+	if o := c.QueryInt("offset", 0); o >= 0 {
+		offset = o
+	}
+
+	rates, err := h.rates.ListRates(c.Context(), limit, offset)
+	if err != nil {
+		h.log.Error("failed to list fx rates: %v", err)
+		return writeError(c, err, "Failed to get rates")
+	}
+
+	return c.JSON(response.Success(resp.ToRateDtos(rates), "Rates retrieved"))
+}
+
+// writeError maps AppErrors to their status and everything else to a 500
+func writeError(c *fiber.Ctx, err error, message string) error {
+	var appErr *errors.AppError
+	if stdErrors.As(err, &appErr) {
+		return c.Status(appErr.Code).JSON(response.Error(appErr.Code, appErr.Message))
+	}
+	return c.Status(500).JSON(response.Error(500, message))
+}
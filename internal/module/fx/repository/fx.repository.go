@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"wallet_api/internal/common/base"
+	"wallet_api/internal/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type FXRepository interface {
+	CreateRates(ctx context.Context, rates []*entity.FXRate) error
+	// FindCurrentRate returns the newest rate of the pair valid at at, nil when there is none
+	FindCurrentRate(ctx context.Context, baseCurrency, quoteCurrency string, at time.Time) (*entity.FXRate, error)
+	FindRates(ctx context.Context, limit, offset int) ([]*entity.FXRate, error)
+
+	CreateQuote(ctx context.Context, quote *entity.FXQuote) error
+	FindQuoteByID(ctx context.Context, id uuid.UUID) (*entity.FXQuote, error)
+	FindQuoteByIDForUpdate(ctx context.Context, id uuid.UUID) (*entity.FXQuote, error)
+	UpdateQuote(ctx context.Context, quote *entity.FXQuote) error
+
+	// Transaction runs fn with a repository bound to one transaction, see base.RunInTransaction
+	Transaction(ctx context.Context, fn func(repo FXRepository) error) error
+}
+
+type fxRepository struct {
+	rates  *base.BaseRepository[entity.FXRate]
+	quotes *base.BaseRepository[entity.FXQuote]
+	db     *gorm.DB
+}
+
+func New(db *gorm.DB) FXRepository {
+	return &fxRepository{
+		rates:  base.NewBaseRepository[entity.FXRate](db),
+		quotes: base.NewBaseRepository[entity.FXQuote](db),
+		db:     db,
+	}
+}
+
+func (r *fxRepository) CreateRates(ctx context.Context, rates []*entity.FXRate) error {
+	return r.rates.CreateBatch(ctx, rates)
+}
+
+func (r *fxRepository) FindCurrentRate(ctx context.Context, baseCurrency, quoteCurrency string, at time.Time) (*entity.FXRate, error) {
+	var rate entity.FXRate
+	err := r.db.WithContext(ctx).
+		Where("base_currency = ? AND quote_currency = ?", baseCurrency, quoteCurrency).
+		Where("valid_from <= ? AND (valid_to IS NULL OR valid_to > ?)", at, at).
+		Order("valid_from DESC, created_at DESC").
+		First(&rate).
+		Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rate, nil
+}
+
+func (r *fxRepository) FindRates(ctx context.Context, limit, offset int) ([]*entity.FXRate, error) {
+	return r.rates.NewQueryBuilder().
+		OrderBy("valid_from DESC, base_currency, quote_currency").
+		Limit(limit).
+		Offset(offset).
+		Find(ctx)
+}
+
+func (r *fxRepository) CreateQuote(ctx context.Context, quote *entity.FXQuote) error {
+	return r.quotes.Create(ctx, quote)
+}
+
+func (r *fxRepository) FindQuoteByID(ctx context.Context, id uuid.UUID) (*entity.FXQuote, error) {
+	return r.quotes.FindByID(ctx, id)
+}
+
+func (r *fxRepository) FindQuoteByIDForUpdate(ctx context.Context, id uuid.UUID) (*entity.FXQuote, error) {
+	return r.quotes.FindByIDForUpdate(ctx, id)
+}
+
+func (r *fxRepository) UpdateQuote(ctx context.Context, quote *entity.FXQuote) error {
+	return r.quotes.Update(ctx, quote)
+}
+
+func (r *fxRepository) Transaction(ctx context.Context, fn func(repo FXRepository) error) error {
+	return base.RunInTransaction(ctx, r.db, func(tx *gorm.DB) error {
+		return fn(New(tx))
+	})
+}
@@ -0,0 +1,197 @@
+package fxusecase
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"time"
+
+	"wallet_api/internal/common/errors"
+	"wallet_api/internal/entity"
+	accountusecase "wallet_api/internal/module/account/usecase"
+	currencyusecase "wallet_api/internal/module/currency/usecase"
+	"wallet_api/internal/module/fx/repository"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrQuoteNotFound  = errors.New(404, "Quote not found", nil)
+	errQuoteExpired   = errors.New(409, "Quote has expired, request a new one", nil)
+	errQuoteUsed      = errors.New(409, "Quote has already been used", nil)
+	errNoRate         = errors.New(404, "No exchange rate available for this currency pair", nil)
+	errAmountTooSmall = errors.New(400, "Amount is too small to convert", nil)
+)
+
+// Transfers is the part of the account module that moves money at a quoted rate
+type Transfers interface {
+	ConvertTransfer(ctx context.Context, userID, fromWalletID, toWalletID uuid.UUID, conversion accountusecase.Conversion, description string, proof accountusecase.StepUpProof, referenceID string) error
+}
+
+// Currencies is the registry that gives each side of a quote its precision
+type Currencies interface {
+	Lookup(ctx context.Context, code string) (*entity.Currency, error)
+}
+
+type QuoteUseCase interface {
+	// CreateQuote prices amount of the source currency in the destination currency and locks it for the quote TTL
+	CreateQuote(ctx context.Context, userID uuid.UUID, sourceCurrency, destinationCurrency string, amount decimal.Decimal) (*entity.FXQuote, error)
+	GetQuote(ctx context.Context, userID, quoteID uuid.UUID) (*entity.FXQuote, error)
+	// Transfer uses a quote to move money between wallets of its two currencies
+	Transfer(ctx context.Context, userID, quoteID, fromWalletID, toWalletID uuid.UUID, description string, proof accountusecase.StepUpProof) (*entity.FXQuote, error)
+}
+
+type quoteUseCase struct {
+	repo       repository.FXRepository
+	transfers  Transfers
+	currencies Currencies
+	ttl        time.Duration
+}
+
+func NewQuoteUseCase(repo repository.FXRepository, transfers Transfers, currencies Currencies, ttl time.Duration) QuoteUseCase {
+	return &quoteUseCase{
+		repo:       repo,
+		transfers:  transfers,
+		currencies: currencies,
+		ttl:        ttl,
+	}
+}
+
+func (uc *quoteUseCase) CreateQuote(ctx context.Context, userID uuid.UUID, sourceCurrency, destinationCurrency string, amount decimal.Decimal) (*entity.FXQuote, error) {
+	if !amount.IsPositive() {
+		return nil, errors.ErrBadRequest
+	}
+
+	source, err := normalizeCurrency(sourceCurrency)
+	if err != nil {
+		return nil, err
+	}
+	destination, err := normalizeCurrency(destinationCurrency)
+	if err != nil {
+		return nil, err
+	}
+	if source == destination {
+		return nil, errSameCurrency
+	}
+
+	sourceUnit, err := uc.currencies.Lookup(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	if err := currencyusecase.CheckAmount(sourceUnit, amount); err != nil {
+		return nil, err
+	}
+	// Money can be converted out of a disabled currency but not into one
+	destinationUnit, err := uc.currencies.Lookup(ctx, destination)
+	if err != nil {
+		return nil, err
+	}
+	if !destinationUnit.Enabled {
+		return nil, currencyusecase.ErrCurrencyDisabled
+	}
+
+	now := time.Now()
+	rate, err := uc.repo.FindCurrentRate(ctx, source, destination, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find fx rate: %w", err)
+	}
+	if rate == nil {
+		return nil, errNoRate
+	}
+
+	// Rounded down, the customer never gets more than the rate allows
+	destinationAmount := destinationUnit.Truncate(amount.Mul(rate.CustomerRate()))
+	if !destinationAmount.IsPositive() {
+		return nil, errAmountTooSmall
+	}
+
+	quote := &entity.FXQuote{
+		UserID:              userID,
+		RateID:              rate.ID,
+		SourceCurrency:      source,
+		DestinationCurrency: destination,
+		Rate:                rate.Rate,
+		Spread:              rate.Spread,
+		SourceAmount:        amount,
+		DestinationAmount:   destinationAmount,
+		ExpiresAt:           now.Add(uc.ttl),
+	}
+	if err := uc.repo.CreateQuote(ctx, quote); err != nil {
+		return nil, fmt.Errorf("failed to create fx quote: %w", err)
+	}
+
+	return quote, nil
+}
+
+func (uc *quoteUseCase) GetQuote(ctx context.Context, userID, quoteID uuid.UUID) (*entity.FXQuote, error) {
+	quote, err := uc.repo.FindQuoteByID(ctx, quoteID)
+	if err := quoteLookupError(quote, err, userID); err != nil {
+		return nil, err
+	}
+	return quote, nil
+}
+
+// Transfer keeps the quote locked while the transfer runs, so it is used at most once. The
+// transfer reference comes from the quote, so a transfer whose quote could not be marked
+// used is found again instead of paid twice.
+func (uc *quoteUseCase) Transfer(ctx context.Context, userID, quoteID, fromWalletID, toWalletID uuid.UUID, description string, proof accountusecase.StepUpProof) (*entity.FXQuote, error) {
+	var quote *entity.FXQuote
+	err := uc.repo.Transaction(ctx, func(repo repository.FXRepository) error {
+		var err error
+		quote, err = repo.FindQuoteByIDForUpdate(ctx, quoteID)
+		if err := quoteLookupError(quote, err, userID); err != nil {
+			return err
+		}
+
+		if quote.UsedAt != nil {
+			return errQuoteUsed
+		}
+		now := time.Now()
+		if !now.Before(quote.ExpiresAt) {
+			return errQuoteExpired
+		}
+
+		referenceID := "fx-" + quote.ID.String()
+		conversion := accountusecase.Conversion{
+			QuoteID:             quote.ID,
+			Rate:                quote.Rate,
+			Spread:              quote.Spread,
+			SourceAmount:        quote.SourceAmount,
+			SourceCurrency:      quote.SourceCurrency,
+			DestinationAmount:   quote.DestinationAmount,
+			DestinationCurrency: quote.DestinationCurrency,
+		}
+		// A replay of a transfer that already went through comes back as nil, any error means no money moved
+		if err := uc.transfers.ConvertTransfer(ctx, userID, fromWalletID, toWalletID, conversion, description, proof, referenceID); err != nil {
+			return err
+		}
+
+		quote.UsedAt = &now
+		quote.ReferenceID = &referenceID
+		if err := repo.UpdateQuote(ctx, quote); err != nil {
+			return fmt.Errorf("failed to update fx quote: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return quote, nil
+}
+
+// quoteLookupError hides quotes of other users behind the not found answer
+func quoteLookupError(quote *entity.FXQuote, err error, userID uuid.UUID) error {
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrQuoteNotFound
+		}
+		return fmt.Errorf("failed to get fx quote: %w", err)
+	}
+	if quote.UserID != userID {
+		return ErrQuoteNotFound
+	}
+	return nil
+}
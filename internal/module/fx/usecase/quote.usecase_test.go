@@ -0,0 +1,219 @@
+package fxusecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"wallet_api/internal/common/errors"
+	"wallet_api/internal/entity"
+	accountrepository "wallet_api/internal/module/account/repository"
+	accountusecase "wallet_api/internal/module/account/usecase"
+	currencyusecase "wallet_api/internal/module/currency/usecase"
+	"wallet_api/internal/module/fx/repository"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// memFX keeps one rate and the quotes in memory, Transaction just calls fn
+type memFX struct {
+	repository.FXRepository
+	rate   *entity.FXRate
+	quotes map[uuid.UUID]*entity.FXQuote
+}
+
+func (r *memFX) FindCurrentRate(_ context.Context, base, quote string, _ time.Time) (*entity.FXRate, error) {
+	if r.rate == nil || r.rate.BaseCurrency != base || r.rate.QuoteCurrency != quote {
+		return nil, nil
+	}
+	return r.rate, nil
+}
+
+func (r *memFX) CreateQuote(_ context.Context, quote *entity.FXQuote) error {
+	quote.ID = uuid.New()
+	r.quotes[quote.ID] = quote
+	return nil
+}
+
+func (r *memFX) FindQuoteByIDForUpdate(_ context.Context, id uuid.UUID) (*entity.FXQuote, error) {
+	quote, ok := r.quotes[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return quote, nil
+}
+
+func (r *memFX) UpdateQuote(_ context.Context, quote *entity.FXQuote) error {
+	r.quotes[quote.ID] = quote
+	return nil
+}
+
+func (r *memFX) Transaction(_ context.Context, fn func(repo repository.FXRepository) error) error {
+	return fn(r)
+}
+
+// fakeConversions answers every transfer with err and records the conversions
+type fakeConversions struct {
+	err         error
+	conversions []accountusecase.Conversion
+	references  []string
+}
+
+func (f *fakeConversions) ConvertTransfer(_ context.Context, _, _, _ uuid.UUID, conversion accountusecase.Conversion, _ string, _ accountusecase.StepUpProof, referenceID string) error {
+	f.conversions = append(f.conversions, conversion)
+	f.references = append(f.references, referenceID)
+	return f.err
+}
+
+// registry has the currencies of the tests, XAU is registered but disabled
+type registry map[string]*entity.Currency
+
+func (r registry) Lookup(_ context.Context, code string) (*entity.Currency, error) {
+	currency, ok := r[currencyusecase.Normalize(code)]
+	if !ok {
+		return nil, currencyusecase.ErrUnsupportedCurrency
+	}
+	return currency, nil
+}
+
+var currencies = registry{
+	"USD": {Code: "USD", Exponent: 2, Enabled: true},
+	"IDR": {Code: "IDR", Exponent: 2, Enabled: true},
+	"JPY": {Code: "JPY", Exponent: 0, Enabled: true},
+	"XAU": {Code: "XAU", Exponent: 2, Enabled: false},
+}
+
+// uniqueViolation names the constraint in the message like the Postgres driver does
+type uniqueViolation string
+
+func (e uniqueViolation) Error() string {
+	return `ERROR: duplicate key value violates unique constraint "` + string(e) + `" (SQLSTATE 23505)`
+}
+func (e uniqueViolation) SQLState() string { return "23505" }
+
+func newQuoteFixture(err error) (*memFX, *fakeConversions, QuoteUseCase) {
+	repo := &memFX{
+		rate: &entity.FXRate{
+			ID: uuid.New(), BaseCurrency: "USD", QuoteCurrency: "IDR",
+			Rate: decimal.RequireFromString("15500.123"), Spread: decimal.RequireFromString("0.01"),
+		},
+		quotes: map[uuid.UUID]*entity.FXQuote{},
+	}
+	transfers := &fakeConversions{err: err}
+	return repo, transfers, NewQuoteUseCase(repo, transfers, currencies, 30*time.Second)
+}
+
+func TestCreateQuote(t *testing.T) {
+	_, _, uc := newQuoteFixture(nil)
+	ctx := context.Background()
+
+	quote, err := uc.CreateQuote(ctx, uuid.New(), "usd", "IDR", decimal.RequireFromString("10.01"))
+	if err != nil {
+		t.Fatalf("CreateQuote() error = %v", err)
+	}
+	// 10.01 * 15500.123 * 0.99 = 153604.668918..., rounded down
+	if quote.DestinationAmount.String() != "153604.66" || quote.SourceCurrency != "USD" {
+		t.Errorf("quote = %s %s -> %s, want USD 153604.66", quote.SourceCurrency, quote.SourceAmount, quote.DestinationAmount)
+	}
+	if ttl := time.Until(quote.ExpiresAt); ttl <= 0 || ttl > 30*time.Second {
+		t.Errorf("quote expires in %s, want within the 30s TTL", ttl)
+	}
+
+	if _, err := uc.CreateQuote(ctx, uuid.New(), "IDR", "USD", decimal.NewFromInt(1)); err != errNoRate {
+		t.Errorf("CreateQuote() without a rate error = %v, want errNoRate", err)
+	}
+	if _, err := uc.CreateQuote(ctx, uuid.New(), "USD", "IDR", decimal.RequireFromString("1.005")); err == nil {
+		t.Errorf("CreateQuote(1.005) error = nil, want USD precision error")
+	}
+	if _, err := uc.CreateQuote(ctx, uuid.New(), "USD", "XAU", decimal.NewFromInt(1)); err != currencyusecase.ErrCurrencyDisabled {
+		t.Errorf("CreateQuote() into a disabled currency error = %v, want ErrCurrencyDisabled", err)
+	}
+	if _, err := uc.CreateQuote(ctx, uuid.New(), "USD", "XTS", decimal.NewFromInt(1)); err != currencyusecase.ErrUnsupportedCurrency {
+		t.Errorf("CreateQuote() into an unknown currency error = %v, want ErrUnsupportedCurrency", err)
+	}
+}
+
+func TestCreateQuoteUsesDestinationExponent(t *testing.T) {
+	repo, _, uc := newQuoteFixture(nil)
+	repo.rate = &entity.FXRate{ID: uuid.New(), BaseCurrency: "USD", QuoteCurrency: "JPY", Rate: decimal.RequireFromString("149.87")}
+
+	quote, err := uc.CreateQuote(context.Background(), uuid.New(), "USD", "JPY", decimal.RequireFromString("10.55"))
+	if err != nil {
+		t.Fatalf("CreateQuote() error = %v", err)
+	}
+	// 10.55 * 149.87 = 1581.1285, JPY has no minor unit
+	if quote.DestinationAmount.String() != "1581" {
+		t.Errorf("destination amount = %s, want 1581", quote.DestinationAmount)
+	}
+}
+
+func TestTransferUsesQuoteOnce(t *testing.T) {
+	repo, transfers, uc := newQuoteFixture(nil)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	quote, err := uc.CreateQuote(ctx, userID, "USD", "IDR", decimal.NewFromInt(10))
+	if err != nil {
+		t.Fatalf("CreateQuote() error = %v", err)
+	}
+
+	if _, err := uc.Transfer(ctx, uuid.New(), quote.ID, uuid.New(), uuid.New(), "", accountusecase.StepUpProof{}); err != ErrQuoteNotFound {
+		t.Errorf("Transfer() by another user error = %v, want ErrQuoteNotFound", err)
+	}
+
+	used, err := uc.Transfer(ctx, userID, quote.ID, uuid.New(), uuid.New(), "", accountusecase.StepUpProof{})
+	if err != nil {
+		t.Fatalf("Transfer() error = %v", err)
+	}
+	if used.UsedAt == nil || *used.ReferenceID != "fx-"+quote.ID.String() || transfers.references[0] != *used.ReferenceID {
+		t.Errorf("quote after transfer = %+v, want it used under its own reference", used)
+	}
+	if got := transfers.conversions[0]; !got.DestinationAmount.Equal(quote.DestinationAmount) || got.SourceCurrency != "USD" {
+		t.Errorf("conversion = %+v, want the quoted amounts", got)
+	}
+
+	if _, err := uc.Transfer(ctx, userID, quote.ID, uuid.New(), uuid.New(), "", accountusecase.StepUpProof{}); err != errQuoteUsed {
+		t.Errorf("second Transfer() error = %v, want errQuoteUsed", err)
+	}
+
+	repo.quotes[quote.ID].UsedAt = nil
+	repo.quotes[quote.ID].ExpiresAt = time.Now().Add(-time.Second)
+	if _, err := uc.Transfer(ctx, userID, quote.ID, uuid.New(), uuid.New(), "", accountusecase.StepUpProof{}); err != errQuoteExpired {
+		t.Errorf("Transfer() with an expired quote error = %v, want errQuoteExpired", err)
+	}
+}
+
+func TestTransferQuoteOutcome(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantUsed bool
+	}{
+		// The quote's reference is taken but not by this source wallet, e.g. a retry from another wallet
+		{name: "reference used elsewhere", err: fmt.Errorf("failed to create fx transfer transaction: %w", uniqueViolation(accountrepository.TransactionReferenceConstraint)), wantUsed: false},
+		// The quote stays usable until it expires
+		{name: "transfer refused", err: errors.New(400, "Insufficient balance", nil), wantUsed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, _, uc := newQuoteFixture(tt.err)
+			userID := uuid.New()
+			quote, err := uc.CreateQuote(context.Background(), userID, "USD", "IDR", decimal.NewFromInt(10))
+			if err != nil {
+				t.Fatalf("CreateQuote() error = %v", err)
+			}
+
+			_, err = uc.Transfer(context.Background(), userID, quote.ID, uuid.New(), uuid.New(), "", accountusecase.StepUpProof{})
+			if (err == nil) != tt.wantUsed {
+				t.Errorf("Transfer() error = %v", err)
+			}
+			if used := repo.quotes[quote.ID].UsedAt != nil; used != tt.wantUsed {
+				t.Errorf("quote used = %v, want %v", used, tt.wantUsed)
+			}
+		})
+	}
+}
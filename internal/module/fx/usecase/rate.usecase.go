@@ -0,0 +1,149 @@
+package fxusecase
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"wallet_api/internal/common/errors"
+	"wallet_api/internal/entity"
+	"wallet_api/internal/module/fx/repository"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	errInvalidCurrency = errors.New(400, "Currency must be a 3 letter ISO 4217 code", nil)
+	errSameCurrency    = errors.New(400, "Base and quote currency must differ", nil)
+	errInvalidRate     = errors.New(400, "Rate must be greater than zero", nil)
+	errInvalidSpread   = errors.New(400, "Spread must be at least 0 and below 1", nil)
+	errInvalidValidity = errors.New(400, "valid_to must be after valid_from", nil)
+	errNoRates         = errors.New(400, "No rates to import", nil)
+)
+
+// RateInput is one rate to load, from the admin endpoint or the CSV import
+type RateInput struct {
+	BaseCurrency  string
+	QuoteCurrency string
+	Rate          decimal.Decimal
+	Spread        decimal.Decimal
+	ValidFrom     time.Time // zero = now
+	ValidTo       *time.Time
+}
+
+type RateUseCase interface {
+	CreateRate(ctx context.Context, input RateInput) (*entity.FXRate, error)
+	// ImportRates stores every rate or none of them
+	ImportRates(ctx context.Context, inputs []RateInput) ([]*entity.FXRate, error)
+	ListRates(ctx context.Context, limit, offset int) ([]*entity.FXRate, error)
+}
+
+type rateUseCase struct {
+	repo repository.FXRepository
+}
+
+func NewRateUseCase(repo repository.FXRepository) RateUseCase {
+	return &rateUseCase{repo: repo}
+}
+
+func (uc *rateUseCase) CreateRate(ctx context.Context, input RateInput) (*entity.FXRate, error) {
+	rates, err := uc.ImportRates(ctx, []RateInput{input})
+	if err != nil {
+		return nil, err
+	}
+	return rates[0], nil
+}
+
+func (uc *rateUseCase) ImportRates(ctx context.Context, inputs []RateInput) ([]*entity.FXRate, error) {
+	if len(inputs) == 0 {
+		return nil, errNoRates
+	}
+
+	now := time.Now()
+	rates := make([]*entity.FXRate, 0, len(inputs))
+	for i, input := range inputs {
+		rate, err := newRate(input, now)
+		if err != nil {
+			// Say which row of an import is wrong
+			var appErr *errors.AppError
+			if len(inputs) > 1 && stdErrors.As(err, &appErr) {
+				return nil, errors.New(400, fmt.Sprintf("Rate %d: %s", i+1, appErr.Message), nil)
+			}
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+
+	if err := uc.repo.CreateRates(ctx, rates); err != nil {
+		return nil, fmt.Errorf("failed to create fx rates: %w", err)
+	}
+
+	return rates, nil
+}
+
+func (uc *rateUseCase) ListRates(ctx context.Context, limit, offset int) ([]*entity.FXRate, error) {
+	rates, err := uc.repo.FindRates(ctx, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fx rates: %w", err)
+	}
+	return rates, nil
+}
+
+// newRate validates one input and builds the rate to store
+func newRate(input RateInput, now time.Time) (*entity.FXRate, error) {
+	base, err := normalizeCurrency(input.BaseCurrency)
+	if err != nil {
+		return nil, err
+	}
+	quote, err := normalizeCurrency(input.QuoteCurrency)
+	if err != nil {
+		return nil, err
+	}
+	if base == quote {
+		return nil, errSameCurrency
+	}
+
+	if !input.Rate.IsPositive() {
+		return nil, errInvalidRate
+	}
+	if input.Spread.IsNegative() || input.Spread.GreaterThanOrEqual(decimal.NewFromInt(1)) {
+		return nil, errInvalidSpread
+	}
+
+	validFrom := input.ValidFrom
+	if validFrom.IsZero() {
+		validFrom = now
+	}
+	if input.ValidTo != nil && !input.ValidTo.After(validFrom) {
+		return nil, errInvalidValidity
+	}
+
+	rate := &entity.FXRate{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		Rate:          input.Rate,
+		Spread:        input.Spread,
+		ValidFrom:     validFrom.UTC(),
+	}
+	if input.ValidTo != nil {
+		validTo := input.ValidTo.UTC()
+		rate.ValidTo = &validTo
+	}
+	return rate, nil
+}
+
+// normalizeCurrency upper-cases a currency code and checks it looks like ISO 4217
+func normalizeCurrency(currency string) (string, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if len(currency) != 3 {
+		return "", errInvalidCurrency
+	}
+	for _, r := range currency {
+		if r < 'A' || r > 'Z' {
+			return "", errInvalidCurrency
+		}
+	}
+	return currency, nil
+}
@@ -0,0 +1,87 @@
+package fxusecase
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// rateCSVColumns lists the columns of a rate import, the first row names them in any order.
+// spread, valid_from and valid_to may be left out or empty.
+var rateCSVColumns = []string{"base_currency", "quote_currency", "rate", "spread", "valid_from", "valid_to"}
+
+// ReadRatesCSV parses a rate import. Times are RFC3339. Errors name the line they were found on.
+func ReadRatesCSV(r io.Reader) ([]RateInput, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range rateCSVColumns[:3] {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing column %s", required)
+		}
+	}
+
+	var inputs []RateInput
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		input := RateInput{
+			BaseCurrency:  field("base_currency"),
+			QuoteCurrency: field("quote_currency"),
+			Spread:        decimal.Zero,
+		}
+
+		if input.Rate, err = decimal.NewFromString(field("rate")); err != nil {
+			return nil, fmt.Errorf("line %d: invalid rate %q", line, field("rate"))
+		}
+		if spread := field("spread"); spread != "" {
+			if input.Spread, err = decimal.NewFromString(spread); err != nil {
+				return nil, fmt.Errorf("line %d: invalid spread %q", line, spread)
+			}
+		}
+		if validFrom := field("valid_from"); validFrom != "" {
+			if input.ValidFrom, err = time.Parse(time.RFC3339, validFrom); err != nil {
+				return nil, fmt.Errorf("line %d: invalid valid_from %q", line, validFrom)
+			}
+		}
+		if validTo := field("valid_to"); validTo != "" {
+			parsed, err := time.Parse(time.RFC3339, validTo)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid valid_to %q", line, validTo)
+			}
+			input.ValidTo = &parsed
+		}
+
+		inputs = append(inputs, input)
+	}
+
+	return inputs, nil
+}
@@ -0,0 +1,56 @@
+package fxusecase
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadRatesCSV(t *testing.T) {
+	file := `quote_currency,base_currency,rate,spread,valid_from,valid_to
+IDR,USD,15500.5,0.005,2026-10-01T00:00:00Z,2026-11-01T00:00:00Z
+USD,IDR,0.0000645,,,
+`
+
+	inputs, err := ReadRatesCSV(strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("ReadRatesCSV() error = %v", err)
+	}
+	if len(inputs) != 2 {
+		t.Fatalf("ReadRatesCSV() read %d rates, want 2", len(inputs))
+	}
+
+	first := inputs[0]
+	if first.BaseCurrency != "USD" || first.QuoteCurrency != "IDR" || first.Rate.String() != "15500.5" || first.Spread.String() != "0.005" {
+		t.Errorf("first rate = %+v, columns were not matched by name", first)
+	}
+	if want := time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC); first.ValidTo == nil || !first.ValidTo.Equal(want) {
+		t.Errorf("first valid_to = %v, want %s", first.ValidTo, want)
+	}
+
+	second := inputs[1]
+	if !second.Spread.IsZero() || !second.ValidFrom.IsZero() || second.ValidTo != nil {
+		t.Errorf("second rate = %+v, want empty optional columns left at their zero value", second)
+	}
+}
+
+func TestReadRatesCSVErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want string
+	}{
+		{name: "missing column", file: "base_currency,rate\nUSD,1\n", want: "missing column quote_currency"},
+		{name: "bad rate", file: "base_currency,quote_currency,rate\nUSD,IDR,1\nUSD,EUR,abc\n", want: "line 3: invalid rate"},
+		{name: "bad time", file: "base_currency,quote_currency,rate,valid_from\nUSD,IDR,1,yesterday\n", want: "line 2: invalid valid_from"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadRatesCSV(strings.NewReader(tt.file))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ReadRatesCSV() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package request
+
+type CreateScheduleRequest struct {
+	FromWalletID string `json:"from_wallet_id" validate:"required"`
+	ToWalletID   string `json:"to_wallet_id" validate:"required"`
+	Amount       string `json:"amount" validate:"required,gt=0"`
+	Description  string `json:"description"`
+	// once, daily, weekly atau monthly
+	Frequency string `json:"frequency" validate:"required"`
+	// Setiap berapa hari/minggu/bulan, kosong = 1
+	Interval int `json:"interval"`
+	// RFC3339, harus di masa depan
+	StartAt string `json:"start_at" validate:"required"`
+	// RFC3339, kosong = tanpa batas
+	EndAt string `json:"end_at"`
+	// Wajib untuk amount di atas batas step-up, isi salah satu. Dicek sekali saat dibuat.
+	PIN         string `json:"pin"`
+	StepUpToken string `json:"step_up_token"`
+}
+
+// UpdateScheduleRequest only changes the fields that are sent
+type UpdateScheduleRequest struct {
+	Description *string `json:"description"`
+	EndAt       *string `json:"end_at"`
+	// active atau paused
+	Status *string `json:"status"`
+}
@@ -0,0 +1,84 @@
+package response
+
+import (
+	"time"
+	"wallet_api/internal/entity"
+)
+
+type ScheduleResponse struct {
+	ID           string  `json:"id"`
+	FromWalletID string  `json:"from_wallet_id"`
+	ToWalletID   string  `json:"to_wallet_id"`
+	Amount       string  `json:"amount"`
+	Description  string  `json:"description"`
+	Frequency    string  `json:"frequency"`
+	Interval     int     `json:"interval"`
+	StartAt      string  `json:"start_at"`
+	EndAt        *string `json:"end_at"`
+	RunCount     int     `json:"run_count"`
+	NextRunAt    *string `json:"next_run_at"`
+	Status       string  `json:"status"`
+	CreatedAt    string  `json:"created_at"`
+	UpdatedAt    string  `json:"updated_at"`
+}
+
+type ScheduleRunResponse struct {
+	ID           string `json:"id"`
+	ScheduleID   string `json:"schedule_id"`
+	OccurrenceAt string `json:"occurrence_at"`
+	ReferenceID  string `json:"reference_id"`
+	Status       string `json:"status"`
+	Error        string `json:"error,omitempty"`
+	CreatedAt    string `json:"created_at"`
+}
+
+func ToScheduleDto(schedule *entity.TransferSchedule) ScheduleResponse {
+	return ScheduleResponse{
+		ID:           schedule.ID.String(),
+		FromWalletID: schedule.FromWalletID.String(),
+		ToWalletID:   schedule.ToWalletID.String(),
+		Amount:       schedule.Amount.String(),
+		Description:  schedule.Description,
+		Frequency:    schedule.Frequency,
+		Interval:     schedule.Interval,
+		StartAt:      schedule.StartAt.Format(time.RFC3339),
+		EndAt:        formatOptionalTime(schedule.EndAt),
+		RunCount:     schedule.RunCount,
+		NextRunAt:    formatOptionalTime(schedule.NextRunAt),
+		Status:       schedule.Status,
+		CreatedAt:    schedule.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    schedule.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func ToScheduleDtos(schedules []*entity.TransferSchedule) []ScheduleResponse {
+	responses := make([]ScheduleResponse, len(schedules))
+	for i, schedule := range schedules {
+		responses[i] = ToScheduleDto(schedule)
+	}
+	return responses
+}
+
+func ToScheduleRunDtos(runs []*entity.TransferScheduleRun) []ScheduleRunResponse {
+	responses := make([]ScheduleRunResponse, len(runs))
+	for i, run := range runs {
+		responses[i] = ScheduleRunResponse{
+			ID:           run.ID.String(),
+			ScheduleID:   run.ScheduleID.String(),
+			OccurrenceAt: run.OccurrenceAt.Format(time.RFC3339),
+			ReferenceID:  run.ReferenceID,
+			Status:       run.Status,
+			Error:        run.Error,
+			CreatedAt:    run.CreatedAt.Format(time.RFC3339),
+		}
+	}
+	return responses
+}
+
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format(time.RFC3339)
+	return &formatted
+}
@@ -0,0 +1,209 @@
+package handler
+
+import (
+	stdErrors "errors"
+	"time"
+
+	"wallet_api/internal/common/errors"
+	"wallet_api/internal/common/response"
+	accountusecase "wallet_api/internal/module/account/usecase"
+	"wallet_api/internal/module/schedule/dto/request"
+	resp "wallet_api/internal/module/schedule/dto/response"
+	scheduleusecase "wallet_api/internal/module/schedule/usecase"
+	"wallet_api/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type Handler struct {
+	uc  scheduleusecase.UseCase
+	log logger.Interface
+}
+
+func New(uc scheduleusecase.UseCase, log logger.Interface) *Handler {
+	return &Handler{
+		uc:  uc,
+		log: log,
+	}
+}
+
+func (h *Handler) CreateSchedule(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	req := new(request.CreateScheduleRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid request body"))
+	}
+
+	fromWalletID, err := uuid.Parse(req.FromWalletID)
+	if err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid from wallet ID"))
+	}
+	toWalletID, err := uuid.Parse(req.ToWalletID)
+	if err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid to wallet ID"))
+	}
+
+	amount, err := decimal.NewFromString(req.Amount)
+	if err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid amount format"))
+	}
+
+	startAt, err := time.Parse(time.RFC3339, req.StartAt)
+	if err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid start_at, use RFC3339"))
+	}
+	var endAt *time.Time
+	if req.EndAt != "" {
+		parsed, err := time.Parse(time.RFC3339, req.EndAt)
+		if err != nil {
+			return c.Status(400).JSON(response.Error(400, "Invalid end_at, use RFC3339"))
+		}
+		endAt = &parsed
+	}
+
+	schedule, err := h.uc.Create(c.Context(), userID, scheduleusecase.CreateInput{
+		FromWalletID: fromWalletID,
+		ToWalletID:   toWalletID,
+		Amount:       amount,
+		Description:  req.Description,
+		Frequency:    req.Frequency,
+		Interval:     req.Interval,
+		StartAt:      startAt,
+		EndAt:        endAt,
+		Proof:        accountusecase.StepUpProof{PIN: req.PIN, StepUpToken: req.StepUpToken},
+	})
+	if err != nil {
+		h.log.Error("failed to create transfer schedule: %v", err)
+		return writeError(c, err, "Failed to create transfer schedule")
+	}
+
+	return c.JSON(response.Success(resp.ToScheduleDto(schedule), "Transfer schedule created"))
+}
+
+func (h *Handler) ListSchedules(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	limit, offset := pagination(c)
+
+	schedules, err := h.uc.List(c.Context(), userID, limit, offset)
+	if err != nil {
+		h.log.Error("failed to list transfer schedules: %v", err)
+		return writeError(c, err, "Failed to get transfer schedules")
+	}
+
+	return c.JSON(response.Success(resp.ToScheduleDtos(schedules), "Transfer schedules retrieved"))
+}
+
+func (h *Handler) GetSchedule(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	scheduleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid transfer schedule ID"))
+	}
+
+	schedule, err := h.uc.Get(c.Context(), userID, scheduleID)
+	if err != nil {
+		h.log.Error("failed to get transfer schedule: %v", err)
+		return writeError(c, err, "Failed to get transfer schedule")
+	}
+
+	return c.JSON(response.Success(resp.ToScheduleDto(schedule), "Transfer schedule retrieved"))
+}
+
+func (h *Handler) UpdateSchedule(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	scheduleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid transfer schedule ID"))
+	}
+
+	req := new(request.UpdateScheduleRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid request body"))
+	}
+
+	input := scheduleusecase.UpdateInput{
+		Description: req.Description,
+		Status:      req.Status,
+	}
+	if req.EndAt != nil {
+		endAt, err := time.Parse(time.RFC3339, *req.EndAt)
+		if err != nil {
+			return c.Status(400).JSON(response.Error(400, "Invalid end_at, use RFC3339"))
+		}
+		input.EndAt = &endAt
+	}
+
+	schedule, err := h.uc.Update(c.Context(), userID, scheduleID, input)
+	if err != nil {
+		h.log.Error("failed to update transfer schedule: %v", err)
+		return writeError(c, err, "Failed to update transfer schedule")
+	}
+
+	return c.JSON(response.Success(resp.ToScheduleDto(schedule), "Transfer schedule updated"))
+}
+
+func (h *Handler) CancelSchedule(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	scheduleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid transfer schedule ID"))
+	}
+
+	schedule, err := h.uc.Cancel(c.Context(), userID, scheduleID)
+	if err != nil {
+		h.log.Error("failed to cancel transfer schedule: %v", err)
+		return writeError(c, err, "Failed to cancel transfer schedule")
+	}
+
+	return c.JSON(response.Success(resp.ToScheduleDto(schedule), "Transfer schedule cancelled"))
+}
+
+func (h *Handler) GetScheduleRuns(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	scheduleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid transfer schedule ID"))
+	}
+
+	limit, offset := pagination(c)
+
+	runs, err := h.uc.GetRuns(c.Context(), userID, scheduleID, limit, offset)
+	if err != nil {
+		h.log.Error("failed to get transfer schedule runs: %v", err)
+		return writeError(c, err, "Failed to get transfer schedule runs")
+	}
+
+	return c.JSON(response.Success(resp.ToScheduleRunDtos(runs), "Transfer schedule runs retrieved"))
+}
+
+// pagination reads limit and offset, falling back to 10 and 0
+func pagination(c *fiber.Ctx) (int, int) {
+	limit := 10
+	offset := 0
+
+	if l := c.QueryInt("limit", 10); l > 0 {
+		limit = l
+	}
+	if o := c.QueryInt("offset", 0); o >= 0 {
+		offset = o
+	}
+
+	return limit, offset
+}
+
+// writeError maps AppErrors to their status and everything else to a 500
+func writeError(c *fiber.Ctx, err error, message string) error {
+	var appErr *errors.AppError
+	if stdErrors.As(err, &appErr) {
+		return c.Status(appErr.Code).JSON(response.Error(appErr.Code, appErr.Message))
+	}
+	return c.Status(500).JSON(response.Error(500, message))
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"wallet_api/internal/common/base"
+	"wallet_api/internal/common/consts"
+	"wallet_api/internal/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ScheduleRepository interface {
+	Create(ctx context.Context, schedule *entity.TransferSchedule) error
+	FindByID(ctx context.Context, id uuid.UUID) (*entity.TransferSchedule, error)
+	FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*entity.TransferSchedule, error)
+	FindByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entity.TransferSchedule, error)
+	Update(ctx context.Context, schedule *entity.TransferSchedule) error
+
+	// ClaimDue locks up to limit active schedules due at now. Rows locked by another
+	// worker are skipped, so replicas never pick the same schedule at the same time.
+	ClaimDue(ctx context.Context, now time.Time, limit int) ([]*entity.TransferSchedule, error)
+	// CreateRun returns false when the occurrence was already recorded
+	CreateRun(ctx context.Context, run *entity.TransferScheduleRun) (bool, error)
+	FindRuns(ctx context.Context, scheduleID uuid.UUID, limit, offset int) ([]*entity.TransferScheduleRun, error)
+
+	// Transaction runs fn with a repository bound to one transaction, see base.RunInTransaction
+	Transaction(ctx context.Context, fn func(repo ScheduleRepository) error) error
+}
+
+type scheduleRepository struct {
+	*base.BaseRepository[entity.TransferSchedule]
+	db *gorm.DB
+}
+
+func New(db *gorm.DB) ScheduleRepository {
+	return &scheduleRepository{
+		BaseRepository: base.NewBaseRepository[entity.TransferSchedule](db),
+		db:             db,
+	}
+}
+
+func (r *scheduleRepository) FindByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entity.TransferSchedule, error) {
+	return r.NewQueryBuilder().
+		Where("user_id", userID).
+		OrderBy("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(ctx)
+}
+
+func (r *scheduleRepository) ClaimDue(ctx context.Context, now time.Time, limit int) ([]*entity.TransferSchedule, error) {
+	var schedules []*entity.TransferSchedule
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status = ? AND next_run_at <= ?", consts.ScheduleStatusActive, now).
+		Order("next_run_at").
+		Limit(limit).
+		Find(&schedules).
+		Error
+	return schedules, err
+}
+
+func (r *scheduleRepository) CreateRun(ctx context.Context, run *entity.TransferScheduleRun) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(run)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *scheduleRepository) FindRuns(ctx context.Context, scheduleID uuid.UUID, limit, offset int) ([]*entity.TransferScheduleRun, error) {
+	var runs []*entity.TransferScheduleRun
+	err := r.db.WithContext(ctx).
+		Where("schedule_id = ?", scheduleID).
+		Order("occurrence_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&runs).
+		Error
+	return runs, err
+}
+
+func (r *scheduleRepository) Transaction(ctx context.Context, fn func(repo ScheduleRepository) error) error {
+	return base.RunInTransaction(ctx, r.db, func(tx *gorm.DB) error {
+		return fn(New(tx))
+	})
+}
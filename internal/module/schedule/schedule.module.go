@@ -0,0 +1,29 @@
+package schedule
+
+import (
+	"wallet_api/config"
+	"wallet_api/internal/module/schedule/handler"
+	"wallet_api/internal/module/schedule/repository"
+	scheduleusecase "wallet_api/internal/module/schedule/usecase"
+	"wallet_api/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+type Module struct {
+	UseCase scheduleusecase.UseCase
+	Handler *handler.Handler
+	Worker  *Worker
+}
+
+func NewModule(db *gorm.DB, log logger.Interface, cfg *config.Config, transfers scheduleusecase.Transfers) *Module {
+	repo := repository.New(db)
+	uc := scheduleusecase.New(repo, transfers, log)
+	h := handler.New(uc, log)
+
+	return &Module{
+		UseCase: uc,
+		Handler: h,
+		Worker:  NewWorker(uc, log, cfg.Schedule.WorkerInterval, cfg.Schedule.BatchSize),
+	}
+}
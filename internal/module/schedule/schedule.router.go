@@ -0,0 +1,19 @@
+package schedule
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// Schedules only take a user login, API keys are deliberately not accepted. Runs move money later
+// without a PIN check, so a leaked key must not be able to set up recurring transfers.
+func (m *Module) RegisterRoutes(app *fiber.App, auth fiber.Handler) {
+	schedules := app.Group("/v1/transfer-schedules", auth)
+	{
+		schedules.Post("/", m.Handler.CreateSchedule)
+		schedules.Get("/", m.Handler.ListSchedules)
+		schedules.Get("/:id", m.Handler.GetSchedule)
+		schedules.Patch("/:id", m.Handler.UpdateSchedule)
+		schedules.Delete("/:id", m.Handler.CancelSchedule)
+		schedules.Get("/:id/runs", m.Handler.GetScheduleRuns)
+	}
+}
@@ -0,0 +1,73 @@
+package schedule
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	scheduleusecase "wallet_api/internal/module/schedule/usecase"
+	"wallet_api/pkg/logger"
+)
+
+// Worker runs due transfer schedules every interval until it is stopped
+type Worker struct {
+	uc        scheduleusecase.UseCase
+	log       logger.Interface
+	interval  time.Duration
+	batchSize int
+
+	cancel context.CancelFunc
+	done   sync.WaitGroup
+}
+
+func NewWorker(uc scheduleusecase.UseCase, log logger.Interface, interval time.Duration, batchSize int) *Worker {
+	return &Worker{
+		uc:        uc,
+		log:       log,
+		interval:  interval,
+		batchSize: batchSize,
+	}
+}
+
+func (w *Worker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+
+	w.done.Add(1)
+	go func() {
+		defer w.done.Done()
+
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			w.tick(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop cancels the current tick and waits for it. A transfer cut off halfway is rolled back
+// and runs again on the next tick.
+func (w *Worker) Stop() {
+	if w.cancel == nil {
+		return
+	}
+	w.cancel()
+	w.done.Wait()
+}
+
+func (w *Worker) tick(ctx context.Context) {
+	ran, err := w.uc.RunDue(ctx, time.Now(), w.batchSize)
+	if err != nil && ctx.Err() == nil {
+		w.log.Error("schedule worker - RunDue: %v", err)
+	}
+	if ran > 0 {
+		w.log.Info("schedule worker - ran %d transfer schedule occurrences", ran)
+	}
+}
@@ -0,0 +1,362 @@
+package scheduleusecase
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"wallet_api/internal/common/consts"
+	"wallet_api/internal/common/errors"
+	"wallet_api/internal/entity"
+	accountusecase "wallet_api/internal/module/account/usecase"
+	"wallet_api/internal/module/schedule/repository"
+	"wallet_api/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrScheduleNotFound = errors.New(404, "Transfer schedule not found", nil)
+	errScheduleClosed   = errors.New(409, "Transfer schedule has already completed or been cancelled", nil)
+	errInvalidFrequency = errors.New(400, "Frequency must be once, daily, weekly or monthly", nil)
+	errInvalidInterval  = errors.New(400, "Interval must be at least 1", nil)
+	errStartInPast      = errors.New(400, "Start time must be in the future", nil)
+	errEndBeforeStart   = errors.New(400, "End time must not be before the start time", nil)
+	errInvalidStatus    = errors.New(400, "Status must be active or paused", nil)
+	errSameWallet       = errors.New(400, "Cannot transfer to the same wallet", nil)
+	errCurrencyMismatch = errors.New(400, "Cannot transfer between different currencies", nil)
+)
+
+// Transfers is the part of the account module that schedules check against and execute through
+type Transfers interface {
+	AuthorizeOutgoing(ctx context.Context, userID, walletID uuid.UUID, amount decimal.Decimal, proof accountusecase.StepUpProof) error
+	FindWallet(ctx context.Context, walletID uuid.UUID) (*entity.Wallet, error)
+	Transfer(ctx context.Context, userID, fromWalletID, toWalletID uuid.UUID, amount decimal.Decimal, description string, proof accountusecase.StepUpProof, referenceID string) error
+}
+
+type CreateInput struct {
+	FromWalletID uuid.UUID
+	ToWalletID   uuid.UUID
+	Amount       decimal.Decimal
+	Description  string
+	Frequency    string
+	Interval     int // 0 = 1
+	StartAt      time.Time
+	EndAt        *time.Time
+	// Checked once here, every run reuses it
+	Proof accountusecase.StepUpProof
+}
+
+// UpdateInput changes only the fields that are set
+type UpdateInput struct {
+	Description *string
+	EndAt       *time.Time
+	// active or paused
+	Status *string
+}
+
+type UseCase interface {
+	Create(ctx context.Context, userID uuid.UUID, input CreateInput) (*entity.TransferSchedule, error)
+	List(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entity.TransferSchedule, error)
+	Get(ctx context.Context, userID, scheduleID uuid.UUID) (*entity.TransferSchedule, error)
+	Update(ctx context.Context, userID, scheduleID uuid.UUID, input UpdateInput) (*entity.TransferSchedule, error)
+	Cancel(ctx context.Context, userID, scheduleID uuid.UUID) (*entity.TransferSchedule, error)
+	GetRuns(ctx context.Context, userID, scheduleID uuid.UUID, limit, offset int) ([]*entity.TransferScheduleRun, error)
+
+	// Dipakai oleh Worker, returns how many occurrences were run
+	RunDue(ctx context.Context, now time.Time, limit int) (int, error)
+}
+
+type useCase struct {
+	repo      repository.ScheduleRepository
+	transfers Transfers
+	log       logger.Interface
+}
+
+func New(repo repository.ScheduleRepository, transfers Transfers, log logger.Interface) UseCase {
+	return &useCase{
+		repo:      repo,
+		transfers: transfers,
+		log:       log,
+	}
+}
+
+// Create checks the transfer like a real one, including the PIN above the step-up
+// threshold. Runs later skip the PIN, the user approved them here.
+func (uc *useCase) Create(ctx context.Context, userID uuid.UUID, input CreateInput) (*entity.TransferSchedule, error) {
+	if input.Amount.LessThanOrEqual(decimal.Zero) {
+		return nil, errors.ErrBadRequest
+	}
+	if input.FromWalletID == input.ToWalletID {
+		return nil, errSameWallet
+	}
+
+	frequency := strings.ToLower(strings.TrimSpace(input.Frequency))
+	if !validFrequency(frequency) {
+		return nil, errInvalidFrequency
+	}
+
+	interval := input.Interval
+	if interval == 0 {
+		interval = 1
+	}
+	if interval < 0 {
+		return nil, errInvalidInterval
+	}
+
+	if !input.StartAt.After(time.Now()) {
+		return nil, errStartInPast
+	}
+	if input.EndAt != nil && input.EndAt.Before(input.StartAt) {
+		return nil, errEndBeforeStart
+	}
+
+	if err := uc.transfers.AuthorizeOutgoing(ctx, userID, input.FromWalletID, input.Amount, input.Proof); err != nil {
+		return nil, err
+	}
+
+	fromWallet, err := uc.transfers.FindWallet(ctx, input.FromWalletID)
+	if err != nil {
+		return nil, err
+	}
+	toWallet, err := uc.transfers.FindWallet(ctx, input.ToWalletID)
+	if err != nil {
+		return nil, err
+	}
+	if fromWallet.Currency != toWallet.Currency {
+		return nil, errCurrencyMismatch
+	}
+
+	startAt := input.StartAt.UTC()
+	schedule := &entity.TransferSchedule{
+		UserID:       userID,
+		FromWalletID: input.FromWalletID,
+		ToWalletID:   input.ToWalletID,
+		Amount:       input.Amount,
+		Description:  input.Description,
+		Frequency:    frequency,
+		Interval:     interval,
+		StartAt:      startAt,
+		EndAt:        utcTime(input.EndAt),
+		NextRunAt:    &startAt,
+		Status:       consts.ScheduleStatusActive,
+	}
+	if err := uc.repo.Create(ctx, schedule); err != nil {
+		return nil, fmt.Errorf("failed to create transfer schedule: %w", err)
+	}
+
+	return schedule, nil
+}
+
+func (uc *useCase) List(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entity.TransferSchedule, error) {
+	schedules, err := uc.repo.FindByUserID(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transfer schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+func (uc *useCase) Get(ctx context.Context, userID, scheduleID uuid.UUID) (*entity.TransferSchedule, error) {
+	schedule, err := uc.repo.FindByID(ctx, scheduleID)
+	if err := scheduleLookupError(schedule, err, userID); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (uc *useCase) Update(ctx context.Context, userID, scheduleID uuid.UUID, input UpdateInput) (*entity.TransferSchedule, error) {
+	if input.Status != nil && *input.Status != consts.ScheduleStatusActive && *input.Status != consts.ScheduleStatusPaused {
+		return nil, errInvalidStatus
+	}
+
+	return uc.modify(ctx, userID, scheduleID, func(schedule *entity.TransferSchedule) error {
+		if input.Description != nil {
+			schedule.Description = *input.Description
+		}
+
+		if input.EndAt != nil {
+			if input.EndAt.Before(schedule.StartAt) {
+				return errEndBeforeStart
+			}
+			schedule.EndAt = utcTime(input.EndAt)
+		}
+
+		resumed := false
+		if input.Status != nil {
+			resumed = schedule.Status == consts.ScheduleStatusPaused && *input.Status == consts.ScheduleStatusActive
+			schedule.Status = *input.Status
+		}
+
+		// A new end date can finish the schedule early
+		schedule.NextRunAt = schedule.NextOccurrence()
+		if schedule.NextRunAt == nil {
+			schedule.Status = consts.ScheduleStatusCompleted
+		}
+
+		// Occurrences missed while paused are skipped, not all run at once
+		if resumed {
+			schedule.SkipUntil(time.Now())
+		}
+
+		return nil
+	})
+}
+
+func (uc *useCase) Cancel(ctx context.Context, userID, scheduleID uuid.UUID) (*entity.TransferSchedule, error) {
+	return uc.modify(ctx, userID, scheduleID, func(schedule *entity.TransferSchedule) error {
+		schedule.Status = consts.ScheduleStatusCancelled
+		schedule.NextRunAt = nil
+		return nil
+	})
+}
+
+func (uc *useCase) GetRuns(ctx context.Context, userID, scheduleID uuid.UUID, limit, offset int) ([]*entity.TransferScheduleRun, error) {
+	if _, err := uc.Get(ctx, userID, scheduleID); err != nil {
+		return nil, err
+	}
+
+	runs, err := uc.repo.FindRuns(ctx, scheduleID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transfer schedule runs: %w", err)
+	}
+	return runs, nil
+}
+
+// RunDue runs up to limit due occurrences, one schedule per transaction. The schedule row
+// stays locked while its transfer runs, so other replicas skip it, and the transfer
+// reference is derived from the occurrence, so a retry after a crash cannot pay twice.
+func (uc *useCase) RunDue(ctx context.Context, now time.Time, limit int) (int, error) {
+	ran := 0
+	for ran < limit {
+		claimed := false
+		err := uc.repo.Transaction(ctx, func(repo repository.ScheduleRepository) error {
+			due, err := repo.ClaimDue(ctx, now, 1)
+			if err != nil {
+				return fmt.Errorf("failed to claim transfer schedules: %w", err)
+			}
+			if len(due) == 0 {
+				claimed = false
+				return nil
+			}
+
+			claimed = true
+			return uc.runOccurrence(ctx, repo, due[0])
+		})
+		if err != nil {
+			return ran, err
+		}
+		if !claimed {
+			break
+		}
+		ran++
+	}
+
+	return ran, nil
+}
+
+// runOccurrence transfers the next occurrence and records how it went. The schedule moves on
+// either way, a failed run must not block the ones after it.
+func (uc *useCase) runOccurrence(ctx context.Context, repo repository.ScheduleRepository, schedule *entity.TransferSchedule) error {
+	occurrence := *schedule.NextRunAt
+	run := &entity.TransferScheduleRun{
+		ScheduleID:   schedule.ID,
+		OccurrenceAt: occurrence,
+		ReferenceID:  fmt.Sprintf("schedule-%s-%d", schedule.ID, occurrence.Unix()),
+		Status:       consts.ScheduleRunSucceeded,
+	}
+
+	// The PIN was checked when the schedule was created, ownership and wallet status are checked again
+	proof := accountusecase.StepUpProof{Preauthorized: true}
+	// An occurrence an earlier attempt already transferred comes back as nil, the reference is per run
+	err := uc.transfers.Transfer(ctx, schedule.UserID, schedule.FromWalletID, schedule.ToWalletID, schedule.Amount, schedule.Description, proof, run.ReferenceID)
+	if err != nil {
+		run.Status = consts.ScheduleRunFailed
+		run.Error = runError(err)
+		uc.log.Error("transfer schedule %s run at %s failed: %v", schedule.ID, occurrence.Format(time.RFC3339), err)
+	}
+
+	if _, err := repo.CreateRun(ctx, run); err != nil {
+		return fmt.Errorf("failed to record transfer schedule run: %w", err)
+	}
+
+	schedule.Advance()
+	if err := repo.Update(ctx, schedule); err != nil {
+		return fmt.Errorf("failed to update transfer schedule: %w", err)
+	}
+
+	return nil
+}
+
+// modify applies fn to a locked, still open schedule of the user and saves it
+func (uc *useCase) modify(ctx context.Context, userID, scheduleID uuid.UUID, fn func(schedule *entity.TransferSchedule) error) (*entity.TransferSchedule, error) {
+	var schedule *entity.TransferSchedule
+	err := uc.repo.Transaction(ctx, func(repo repository.ScheduleRepository) error {
+		var err error
+		schedule, err = repo.FindByIDForUpdate(ctx, scheduleID)
+		if err := scheduleLookupError(schedule, err, userID); err != nil {
+			return err
+		}
+
+		if schedule.Status == consts.ScheduleStatusCompleted || schedule.Status == consts.ScheduleStatusCancelled {
+			return errScheduleClosed
+		}
+
+		if err := fn(schedule); err != nil {
+			return err
+		}
+
+		if err := repo.Update(ctx, schedule); err != nil {
+			return fmt.Errorf("failed to update transfer schedule: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+// scheduleLookupError hides schedules of other users behind the not found answer
+func scheduleLookupError(schedule *entity.TransferSchedule, err error, userID uuid.UUID) error {
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrScheduleNotFound
+		}
+		return fmt.Errorf("failed to get transfer schedule: %w", err)
+	}
+	if schedule.UserID != userID {
+		return ErrScheduleNotFound
+	}
+	return nil
+}
+
+// runError is what a run records about a failure. AppErrors are meant for the user,
+// anything else is only logged.
+func runError(err error) string {
+	var appErr *errors.AppError
+	if stdErrors.As(err, &appErr) {
+		return appErr.Message
+	}
+	return "Transfer failed"
+}
+
+func validFrequency(frequency string) bool {
+	switch frequency {
+	case consts.ScheduleFrequencyOnce, consts.ScheduleFrequencyDaily, consts.ScheduleFrequencyWeekly, consts.ScheduleFrequencyMonthly:
+		return true
+	}
+	return false
+}
+
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
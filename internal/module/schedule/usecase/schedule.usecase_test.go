@@ -0,0 +1,165 @@
+package scheduleusecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"wallet_api/internal/common/consts"
+	"wallet_api/internal/common/errors"
+	"wallet_api/internal/entity"
+	accountrepository "wallet_api/internal/module/account/repository"
+	accountusecase "wallet_api/internal/module/account/usecase"
+	"wallet_api/internal/module/schedule/repository"
+	"wallet_api/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// memSchedules keeps schedules and runs in memory, Transaction just calls fn
+type memSchedules struct {
+	repository.ScheduleRepository
+	schedules map[uuid.UUID]*entity.TransferSchedule
+	runs      []*entity.TransferScheduleRun
+}
+
+func (r *memSchedules) FindByIDForUpdate(_ context.Context, id uuid.UUID) (*entity.TransferSchedule, error) {
+	schedule, ok := r.schedules[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return schedule, nil
+}
+
+func (r *memSchedules) Update(_ context.Context, schedule *entity.TransferSchedule) error {
+	r.schedules[schedule.ID] = schedule
+	return nil
+}
+
+func (r *memSchedules) ClaimDue(_ context.Context, now time.Time, limit int) ([]*entity.TransferSchedule, error) {
+	var due []*entity.TransferSchedule
+	for _, schedule := range r.schedules {
+		if schedule.Status == consts.ScheduleStatusActive && schedule.NextRunAt != nil && !schedule.NextRunAt.After(now) {
+			due = append(due, schedule)
+		}
+		if len(due) == limit {
+			break
+		}
+	}
+	return due, nil
+}
+
+func (r *memSchedules) CreateRun(_ context.Context, run *entity.TransferScheduleRun) (bool, error) {
+	r.runs = append(r.runs, run)
+	return true, nil
+}
+
+func (r *memSchedules) Transaction(_ context.Context, fn func(repo repository.ScheduleRepository) error) error {
+	return fn(r)
+}
+
+// fakeTransfers answers every transfer with the next error in errs
+type fakeTransfers struct {
+	Transfers
+	errs       []error
+	references []string
+	proofs     []accountusecase.StepUpProof
+}
+
+func (f *fakeTransfers) Transfer(_ context.Context, _, _, _ uuid.UUID, _ decimal.Decimal, _ string, proof accountusecase.StepUpProof, referenceID string) error {
+	f.references = append(f.references, referenceID)
+	f.proofs = append(f.proofs, proof)
+
+	err := f.errs[0]
+	f.errs = f.errs[1:]
+	return err
+}
+
+// uniqueViolation names the constraint in the message like the Postgres driver does
+type uniqueViolation string
+
+func (e uniqueViolation) Error() string {
+	return `ERROR: duplicate key value violates unique constraint "` + string(e) + `" (SQLSTATE 23505)`
+}
+func (e uniqueViolation) SQLState() string { return "23505" }
+
+type quietLogger struct{ logger.Interface }
+
+func (quietLogger) Error(interface{}, ...interface{}) {}
+
+func TestRunDue(t *testing.T) {
+	start := time.Date(2026, time.March, 1, 9, 0, 0, 0, time.UTC)
+	schedule := &entity.TransferSchedule{
+		ID: uuid.New(), UserID: uuid.New(), FromWalletID: uuid.New(), ToWalletID: uuid.New(),
+		Amount: decimal.NewFromInt(50), Frequency: consts.ScheduleFrequencyDaily, Interval: 1,
+		StartAt: start, NextRunAt: &start, Status: consts.ScheduleStatusActive,
+	}
+	repo := &memSchedules{schedules: map[uuid.UUID]*entity.TransferSchedule{schedule.ID: schedule}}
+	transfers := &fakeTransfers{errs: []error{
+		errors.New(422, "Insufficient balance", nil),
+		// Transfer answers a replay of the run's own debit with nil, a collision reaching here moved nothing
+		fmt.Errorf("failed to create withdrawal transaction: %w", uniqueViolation(accountrepository.TransactionReferenceConstraint)),
+		nil,
+	}}
+	uc := New(repo, transfers, quietLogger{})
+
+	// Three occurrences are due, the worker was down for two days
+	ran, err := uc.RunDue(context.Background(), start.AddDate(0, 0, 2), 10)
+	if err != nil {
+		t.Fatalf("RunDue() error = %v", err)
+	}
+	if ran != 3 {
+		t.Fatalf("RunDue() ran %d, want 3", ran)
+	}
+
+	wantStatus := []string{consts.ScheduleRunFailed, consts.ScheduleRunFailed, consts.ScheduleRunSucceeded}
+	for i, run := range repo.runs {
+		if run.Status != wantStatus[i] {
+			t.Errorf("run %d status = %s, want %s", i, run.Status, wantStatus[i])
+		}
+		if want := fmt.Sprintf("schedule-%s-%d", schedule.ID, start.AddDate(0, 0, i).Unix()); run.ReferenceID != want || transfers.references[i] != want {
+			t.Errorf("run %d reference = %s, want %s", i, run.ReferenceID, want)
+		}
+		if !transfers.proofs[i].Preauthorized {
+			t.Errorf("run %d was not preauthorized", i)
+		}
+	}
+	if repo.runs[0].Error != "Insufficient balance" {
+		t.Errorf("failed run error = %q, want the AppError message", repo.runs[0].Error)
+	}
+
+	if want := start.AddDate(0, 0, 3); schedule.RunCount != 3 || !schedule.NextRunAt.Equal(want) {
+		t.Errorf("schedule run count = %d, next = %s, want 3 and %s", schedule.RunCount, schedule.NextRunAt, want)
+	}
+}
+
+func TestResumeSkipsMissedOccurrences(t *testing.T) {
+	start := time.Now().Add(-72 * time.Hour).Truncate(time.Second)
+	userID := uuid.New()
+	schedule := &entity.TransferSchedule{
+		ID: uuid.New(), UserID: userID, Frequency: consts.ScheduleFrequencyDaily, Interval: 1,
+		StartAt: start, NextRunAt: &start, Status: consts.ScheduleStatusPaused,
+	}
+	repo := &memSchedules{schedules: map[uuid.UUID]*entity.TransferSchedule{schedule.ID: schedule}}
+	uc := New(repo, &fakeTransfers{}, quietLogger{})
+
+	active := consts.ScheduleStatusActive
+	updated, err := uc.Update(context.Background(), userID, schedule.ID, UpdateInput{Status: &active})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	if updated.Status != consts.ScheduleStatusActive {
+		t.Errorf("status = %s, want active", updated.Status)
+	}
+	if updated.NextRunAt == nil || updated.NextRunAt.Before(time.Now()) {
+		t.Errorf("NextRunAt = %v, want the first occurrence after now", updated.NextRunAt)
+	}
+
+	if _, err := uc.Update(context.Background(), uuid.New(), schedule.ID, UpdateInput{Status: &active}); err != ErrScheduleNotFound {
+		t.Errorf("Update() by another user error = %v, want ErrScheduleNotFound", err)
+	}
+}
@@ -3,20 +3,125 @@ package response
 import (
 	"time"
 	"wallet_api/internal/entity"
+	"wallet_api/internal/utils"
+
+	"github.com/google/uuid"
 )
 
 type UserResponse struct {
-	ID        string `json:"id"`
-	Username  string `json:"username"`
-	Email     string `json:"email"`
-	CreatedAt string `json:"created_at"`
+	ID            string `json:"id"`
+	Username      string `json:"username"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	// Email baru yang menunggu verifikasi
+	PendingEmail string `json:"pending_email,omitempty"`
+	Role         string `json:"role"`
+	TwoFactor    bool   `json:"two_factor_enabled"`
+	PINSet       bool   `json:"pin_set"`
+	CreatedAt    string `json:"created_at"`
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// AuthResponse dipakai kalau token dikirim lewat body, bukan cookie
+type AuthResponse struct {
+	User   UserResponse  `json:"user"`
+	Tokens TokenResponse `json:"tokens"`
+}
+
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	TwoFactorToken    string `json:"two_factor_token"`
+}
+
+type TwoFactorEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type StepUpResponse struct {
+	StepUpToken string `json:"step_up_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type SessionResponse struct {
+	ID         string `json:"id"`
+	UserAgent  string `json:"user_agent"`
+	IPAddress  string `json:"ip_address"`
+	CreatedAt  string `json:"created_at"`
+	LastSeenAt string `json:"last_seen_at"`
+	ExpiresAt  string `json:"expires_at"`
+	// Sesi yang dipakai request ini
+	Current bool `json:"current"`
+}
+
+type UserListResponse struct {
+	Users  []UserResponse `json:"users"`
+	Total  int64          `json:"total"`
+	Limit  int            `json:"limit"`
+	Offset int            `json:"offset"`
 }
 
 func ToUserDto(user *entity.User) UserResponse {
-	return UserResponse{
-		ID:        user.ID.String(),
-		Username:  user.Username,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt.Format(time.RFC3339),
+	dto := UserResponse{
+		ID:            user.ID.String(),
+		Username:      user.Username,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified(),
+		Role:          user.Role,
+		TwoFactor:     user.TwoFactorEnabled(),
+		PINSet:        user.HasPIN(),
+		CreatedAt:     user.CreatedAt.Format(time.RFC3339),
+	}
+	if user.PendingEmail != nil {
+		dto.PendingEmail = *user.PendingEmail
+	}
+	return dto
+}
+
+func ToTokenDto(pair *utils.TokenPair) TokenResponse {
+	return TokenResponse{
+		AccessToken:  pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    pair.ExpiresIn,
+	}
+}
+
+func ToUserDtos(users []*entity.User) []UserResponse {
+	responses := make([]UserResponse, len(users))
+	for i, user := range users {
+		responses[i] = ToUserDto(user)
+	}
+	return responses
+}
+
+func ToSessionDtos(sessions []*entity.Session, currentSessionID uuid.UUID) []SessionResponse {
+	responses := make([]SessionResponse, len(sessions))
+	for i, session := range sessions {
+		lastSeen := session.CreatedAt
+		if session.LastSeenAt != nil {
+			lastSeen = *session.LastSeenAt
+		}
+
+		responses[i] = SessionResponse{
+			ID:         session.ID.String(),
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt.Format(time.RFC3339),
+			LastSeenAt: lastSeen.Format(time.RFC3339),
+			ExpiresAt:  session.ExpiredAt.Format(time.RFC3339),
+			Current:    session.ID == currentSessionID,
+		}
 	}
+	return responses
 }
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"wallet_api/internal/common/response"
+	resp "wallet_api/internal/module/user/dto/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) ListUsers(c *fiber.Ctx) error {
+	limit := 20
+	offset := 0
+
+	if l := c.QueryInt("limit", 20); l > 0 && l <= 100 {
+		limit = l
+	}
+	if o := c.QueryInt("offset", 0); o >= 0 {
+		offset = o
+	}
+
+	users, total, err := h.uc.SearchUsers(c.Context(), c.Query("q"), limit, offset)
+	if err != nil {
+		h.log.Error("failed to list users: %v", err)
+		return c.Status(500).JSON(response.Error(500, "Failed to list users"))
+	}
+
+	return c.JSON(response.Success(resp.UserListResponse{
+		Users:  resp.ToUserDtos(users),
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}, "Users retrieved"))
+}
+
+func (h *Handler) GetUser(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid user ID"))
+	}
+
+	user, err := h.uc.GetProfile(c.Context(), userID)
+	if err != nil {
+		h.log.Error("failed to get user: %v", err)
+		return c.Status(404).JSON(response.Error(404, "User not found"))
+	}
+
+	return c.JSON(response.Success(resp.ToUserDto(user), "User retrieved"))
+}
+
+// UnlockUser lifts a login lockout caused by too many failed attempts
+func (h *Handler) UnlockUser(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid user ID"))
+	}
+
+	if err := h.uc.UnlockUser(c.Context(), userID); err != nil {
+		h.log.Error("failed to unlock user: %v", err)
+		return writeAppError(c, err, "Failed to unlock user")
+	}
+
+	return c.JSON(response.Success(nil, "User unlocked"))
+}
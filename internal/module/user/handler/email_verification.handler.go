@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"wallet_api/internal/common/response"
+	"wallet_api/internal/entity"
+	"wallet_api/internal/module/user/dto/request"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) VerifyEmail(c *fiber.Ctx) error {
+	req := new(request.VerifyEmailRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid request body"))
+	}
+
+	if err := h.verification.VerifyEmail(c.Context(), req.Token, sessionMeta(c)); err != nil {
+		h.log.Error("failed to verify email: %v", err)
+		return writeAppError(c, err, "Failed to verify email")
+	}
+
+	return c.JSON(response.Success(nil, "Email verified"))
+}
+
+func (h *Handler) ResendVerification(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	if err := h.verification.ResendVerification(c.Context(), userID); err != nil {
+		h.log.Error("failed to resend verification email: %v", err)
+		return writeAppError(c, err, "Failed to send verification email")
+	}
+
+	return c.JSON(response.Success(nil, "Verification email sent"))
+}
+
+// sendVerification mails a verification link after register or an email change.
+// A failure only gets logged, the user can ask for a new link later.
+func (h *Handler) sendVerification(c *fiber.Ctx, user *entity.User) {
+	if err := h.verification.SendVerification(c.Context(), user); err != nil {
+		h.log.Error("failed to send verification email: %v", err)
+	}
+}
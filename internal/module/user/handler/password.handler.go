@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"wallet_api/internal/common/response"
+	"wallet_api/internal/module/user/dto/request"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) ForgotPassword(c *fiber.Ctx) error {
+	req := new(request.ForgotPasswordRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid request body"))
+	}
+
+	if err := h.passwords.ForgotPassword(c.Context(), req.Email, sessionMeta(c)); err != nil {
+		h.log.Error("failed to start password reset: %v", err)
+		return c.Status(500).JSON(response.Error(500, "Failed to start password reset"))
+	}
+
+	// Same answer whether or not the email is registered
+	return c.JSON(response.Success(nil, "If the email is registered, a reset link has been sent"))
+}
+
+func (h *Handler) ResetPassword(c *fiber.Ctx) error {
+	req := new(request.ResetPasswordRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid request body"))
+	}
+
+	if err := h.passwords.ResetPassword(c.Context(), req.Token, req.NewPassword, sessionMeta(c)); err != nil {
+		h.log.Error("failed to reset password: %v", err)
+		return writeAppError(c, err, "Failed to reset password")
+	}
+
+	return c.JSON(response.Success(nil, "Password has been reset, please login again"))
+}
+
+func (h *Handler) ChangePassword(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+	sessionID := c.Locals("session_id").(uuid.UUID)
+
+	req := new(request.ChangePasswordRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid request body"))
+	}
+
+	if err := h.passwords.ChangePassword(c.Context(), userID, sessionID, req.CurrentPassword, req.NewPassword, sessionMeta(c)); err != nil {
+		h.log.Error("failed to change password: %v", err)
+		return writeAppError(c, err, "Failed to change password")
+	}
+
+	return c.JSON(response.Success(nil, "Password changed, other sessions have been signed out"))
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"wallet_api/internal/common/response"
+	"wallet_api/internal/module/user/dto/request"
+	resp "wallet_api/internal/module/user/dto/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) SetPIN(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	req := new(request.SetPINRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid request body"))
+	}
+
+	if err := h.pins.SetPIN(c.Context(), userID, req.Password, req.PIN, sessionMeta(c)); err != nil {
+		h.log.Error("failed to set PIN: %v", err)
+		return writeAppError(c, err, "Failed to set PIN")
+	}
+
+	return c.JSON(response.Success(nil, "Transaction PIN set"))
+}
+
+// StepUp trades a PIN for a short-lived token, so a client can ask for the PIN once
+// and then send several large withdrawals or transfers
+func (h *Handler) StepUp(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	req := new(request.StepUpRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid request body"))
+	}
+
+	token, err := h.pins.StepUp(c.Context(), userID, req.PIN)
+	if err != nil {
+		h.log.Error("failed to step up: %v", err)
+		return writeAppError(c, err, "Failed to verify PIN")
+	}
+
+	return c.JSON(response.Success(resp.StepUpResponse{
+		StepUpToken: token.Token,
+		ExpiresIn:   token.ExpiresIn,
+	}, "PIN verified"))
+}
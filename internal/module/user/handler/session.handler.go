@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"wallet_api/internal/common/response"
+	resp "wallet_api/internal/module/user/dto/response"
+	"wallet_api/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) ListSessions(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+	sessionID := c.Locals("session_id").(uuid.UUID)
+
+	sessions, err := h.sessions.ListSessions(c.Context(), userID)
+	if err != nil {
+		h.log.Error("failed to list sessions: %v", err)
+		return c.Status(500).JSON(response.Error(500, "Failed to list sessions"))
+	}
+
+	return c.JSON(response.Success(resp.ToSessionDtos(sessions, sessionID), "Sessions retrieved"))
+}
+
+// RevokeSession signs out one session. Revoking the current one works like logout.
+func (h *Handler) RevokeSession(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+	currentSessionID := c.Locals("session_id").(uuid.UUID)
+
+	sessionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid session ID"))
+	}
+
+	if err := h.sessions.RevokeUserSession(c.Context(), userID, sessionID); err != nil {
+		h.log.Error("failed to revoke session: %v", err)
+		return writeAppError(c, err, "Failed to revoke session")
+	}
+
+	if sessionID == currentSessionID {
+		utils.ClearAuthCookies(c)
+	}
+
+	return c.JSON(response.Success(nil, "Session revoked"))
+}
+
+func (h *Handler) RevokeOtherSessions(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+	sessionID := c.Locals("session_id").(uuid.UUID)
+
+	if err := h.sessions.RevokeOtherSessions(c.Context(), userID, sessionID); err != nil {
+		h.log.Error("failed to revoke other sessions: %v", err)
+		return c.Status(500).JSON(response.Error(500, "Failed to revoke sessions"))
+	}
+
+	return c.JSON(response.Success(nil, "All other sessions revoked"))
+}
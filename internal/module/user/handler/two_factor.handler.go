@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"wallet_api/internal/common/errors"
+	"wallet_api/internal/common/response"
+	"wallet_api/internal/module/user/dto/request"
+	resp "wallet_api/internal/module/user/dto/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) EnrollTwoFactor(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	enrollment, err := h.twoFactor.Enroll(c.Context(), userID)
+	if err != nil {
+		h.log.Error("failed to enroll two-factor: %v", err)
+		return writeAppError(c, err, "Failed to enroll two-factor authentication")
+	}
+
+	return c.JSON(response.Success(resp.TwoFactorEnrollmentResponse{
+		Secret:     enrollment.Secret,
+		OTPAuthURI: enrollment.URI,
+	}, "Scan the secret with your authenticator app and confirm with a code"))
+}
+
+func (h *Handler) ConfirmTwoFactor(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	req := new(request.TwoFactorCodeRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid request body"))
+	}
+
+	codes, err := h.twoFactor.Confirm(c.Context(), userID, req.Code)
+	if err != nil {
+		h.log.Error("failed to confirm two-factor: %v", err)
+		return writeAppError(c, err, "Failed to enable two-factor authentication")
+	}
+
+	return c.JSON(response.Success(resp.RecoveryCodesResponse{RecoveryCodes: codes}, "Two-factor authentication enabled"))
+}
+
+func (h *Handler) DisableTwoFactor(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	req := new(request.DisableTwoFactorRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid request body"))
+	}
+
+	if err := h.twoFactor.Disable(c.Context(), userID, req.Password, req.Code); err != nil {
+		h.log.Error("failed to disable two-factor: %v", err)
+		return writeAppError(c, err, "Failed to disable two-factor authentication")
+	}
+
+	return c.JSON(response.Success(nil, "Two-factor authentication disabled"))
+}
+
+// writeAppError responds with the status of an AppError, or 500 with the given message otherwise
+func writeAppError(c *fiber.Ctx, err error, message string) error {
+	if appErr, ok := err.(*errors.AppError); ok {
+		return c.Status(appErr.Code).JSON(response.Error(appErr.Code, appErr.Message))
+	}
+
+	return c.Status(500).JSON(response.Error(500, message))
+}
@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"strings"
 	"time"
+	"wallet_api/internal/common/consts"
 	"wallet_api/internal/common/errors"
 	"wallet_api/internal/common/response"
 	"wallet_api/internal/entity"
@@ -16,16 +18,32 @@ import (
 )
 
 type Handler struct {
-	uc         userusecase.UseCase
-	log        logger.Interface
-	jwtManager *utils.JWTManager
+	uc           userusecase.UseCase
+	sessions     userusecase.SessionUseCase
+	twoFactor    userusecase.TwoFactorUseCase
+	passwords    userusecase.PasswordUseCase
+	verification userusecase.EmailVerificationUseCase
+	pins         userusecase.PINUseCase
+	log          logger.Interface
 }
 
-func New(uc userusecase.UseCase, log logger.Interface) *Handler {
+func New(
+	uc userusecase.UseCase,
+	sessions userusecase.SessionUseCase,
+	twoFactor userusecase.TwoFactorUseCase,
+	passwords userusecase.PasswordUseCase,
+	verification userusecase.EmailVerificationUseCase,
+	pins userusecase.PINUseCase,
+	log logger.Interface,
+) *Handler {
 	return &Handler{
-		uc:         uc,
-		log:        log,
-		jwtManager: utils.NewJWTManager(utils.GetSecretKey()),
+		uc:           uc,
+		sessions:     sessions,
+		twoFactor:    twoFactor,
+		passwords:    passwords,
+		verification: verification,
+		pins:         pins,
+		log:          log,
 	}
 }
 
@@ -51,16 +69,9 @@ func (h *Handler) Register(c *fiber.Ctx) error {
 		return c.Status(500).JSON(response.Error(500, "Failed to register user"))
 	}
 
-	tokenPair, err := h.jwtManager.GenerateToken(user.ID, user.Username)
-	if err != nil {
-		h.log.Error("failed to generate tokens: %v", err)
-		return c.Status(500).JSON(response.Error(500, "Failed to generate tokens"))
-	}
-
-	isProduction := c.Protocol() == "https"
-	utils.SetAuthCookiesSmart(c, tokenPair.AccessToken, tokenPair.RefreshToken, time.Duration(tokenPair.ExpiresIn)*time.Second, isProduction)
+	h.sendVerification(c, user)
 
-	return c.JSON(response.Success(resp.ToUserDto(user), "User registered successfully"))
+	return h.startSession(c, user, tokenDelivery(c, req.TokenDelivery), "User registered successfully")
 }
 
 func (h *Handler) Login(c *fiber.Ctx) error {
@@ -69,24 +80,69 @@ func (h *Handler) Login(c *fiber.Ctx) error {
 		return c.Status(400).JSON(response.Error(400, "Invalid request body"))
 	}
 
-	user, err := h.uc.Login(c.Context(), req.Username, req.Password)
+	result, err := h.uc.Login(c.Context(), req.Username, req.Password, c.IP())
 	if err != nil {
 		h.log.Error("failed to login: %v", err)
 		return c.Status(401).JSON(response.Error(401, "Invalid credentials"))
 	}
 
-	// Generate JWT tokens
-	tokenPair, err := h.jwtManager.GenerateToken(user.ID, user.Username)
+	// Password was correct but the user still has to pass the second factor
+	if result.TwoFactorToken != "" {
+		return c.JSON(response.Success(resp.TwoFactorChallengeResponse{
+			TwoFactorRequired: true,
+			TwoFactorToken:    result.TwoFactorToken,
+		}, "Two-factor authentication required"))
+	}
+
+	return h.startSession(c, result.User, tokenDelivery(c, req.TokenDelivery), "Login successful")
+}
+
+func (h *Handler) LoginTwoFactor(c *fiber.Ctx) error {
+	req := new(request.LoginTwoFactorRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid request body"))
+	}
+
+	user, err := h.uc.LoginTwoFactor(c.Context(), req.TwoFactorToken, req.Code)
+	if err != nil {
+		h.log.Error("failed to verify second factor: %v", err)
+
+		if appErr, ok := err.(*errors.AppError); ok {
+			return c.Status(appErr.Code).JSON(response.Error(appErr.Code, appErr.Message))
+		}
+
+		return c.Status(500).JSON(response.Error(500, "Failed to login"))
+	}
+
+	return h.startSession(c, user, tokenDelivery(c, req.TokenDelivery), "Login successful")
+}
+
+// startSession opens a server-side session for a fully authenticated user and hands out its tokens
+func (h *Handler) startSession(c *fiber.Ctx, user *entity.User, delivery, message string) error {
+	// Open a server-side session and generate JWT tokens for it
+	tokenPair, err := h.sessions.CreateSession(c.Context(), user, sessionMeta(c))
 	if err != nil {
-		h.log.Error("failed to generate tokens: %v", err)
+		h.log.Error("failed to create session: %v", err)
 		return c.Status(500).JSON(response.Error(500, "Failed to generate tokens"))
 	}
 
+	// Mobile apps and services get the token pair in the body and no cookies
+	if delivery == consts.TokenDeliveryBody {
+		return c.JSON(response.Success(resp.AuthResponse{
+			User:   resp.ToUserDto(user),
+			Tokens: resp.ToTokenDto(tokenPair),
+		}, message))
+	}
+
 	// Set auth cookies (use development mode for HTTP testing)
 	isProduction := c.Protocol() == "https"
 	utils.SetAuthCookiesSmart(c, tokenPair.AccessToken, tokenPair.RefreshToken, time.Duration(tokenPair.ExpiresIn)*time.Second, isProduction)
+	if err := utils.IssueCSRFToken(c, isProduction); err != nil {
+		h.log.Error("failed to issue CSRF token: %v", err)
+		return c.Status(500).JSON(response.Error(500, "Failed to generate tokens"))
+	}
 
-	return c.JSON(response.Success(resp.ToUserDto(user), "Login successful"))
+	return c.JSON(response.Success(resp.ToUserDto(user), message))
 }
 
 func (h *Handler) GetProfile(c *fiber.Ctx) error {
@@ -114,6 +170,7 @@ func (h *Handler) UpdateProfile(c *fiber.Ctx) error {
 		return c.Status(404).JSON(response.Error(404, "User not found"))
 	}
 
+	previousPending := user.PendingEmail
 	user.Username = req.Username
 	user.Email = req.Email
 
@@ -128,10 +185,22 @@ func (h *Handler) UpdateProfile(c *fiber.Ctx) error {
 		return c.Status(500).JSON(response.Error(500, "Failed to update profile"))
 	}
 
+	// The new email is pending until the link sent to it is followed
+	if user.PendingEmail != nil && (previousPending == nil || *previousPending != *user.PendingEmail) {
+		h.sendVerification(c, user)
+	}
+
 	return c.JSON(response.Success(resp.ToUserDto(user), "Profile updated"))
 }
 
 func (h *Handler) Logout(c *fiber.Ctx) error {
+	// Revoke the session so its tokens stop working immediately
+	sessionID := c.Locals("session_id").(uuid.UUID)
+	if err := h.sessions.RevokeSession(c.Context(), sessionID); err != nil {
+		h.log.Error("failed to revoke session: %v", err)
+		return c.Status(500).JSON(response.Error(500, "Failed to logout"))
+	}
+
 	// Clear auth cookies
 	utils.ClearAuthCookies(c)
 
@@ -145,23 +214,73 @@ func (h *Handler) RefreshToken(c *fiber.Ctx) error {
 		return c.Status(401).JSON(response.Error(401, "Refresh token not found"))
 	}
 
-	// Validate refresh token
-	claims, err := h.jwtManager.ValidateToken(refreshToken)
+	// Validate refresh token against its session and generate a new token pair
+	tokenPair, err := h.sessions.RefreshSession(c.Context(), refreshToken, sessionMeta(c))
 	if err != nil {
-		h.log.Error("failed to validate refresh token: %v", err)
-		return c.Status(401).JSON(response.Error(401, "Invalid refresh token"))
-	}
-
-	// Generate new token pair
-	tokenPair, err := h.jwtManager.GenerateToken(claims.UserID, claims.Username)
-	if err != nil {
-		h.log.Error("failed to generate new tokens: %v", err)
-		return c.Status(500).JSON(response.Error(500, "Failed to generate tokens"))
+		return h.writeRefreshError(c, err)
 	}
 
 	// Set new auth cookies (use development mode for HTTP testing)
 	isProduction := c.Protocol() == "https"
 	utils.SetAuthCookiesSmart(c, tokenPair.AccessToken, tokenPair.RefreshToken, time.Duration(tokenPair.ExpiresIn)*time.Second, isProduction)
+	if err := utils.IssueCSRFToken(c, isProduction); err != nil {
+		h.log.Error("failed to issue CSRF token: %v", err)
+		return c.Status(500).JSON(response.Error(500, "Failed to generate tokens"))
+	}
 
 	return c.JSON(response.Success(nil, "Token refreshed successfully"))
 }
+
+// RefreshTokenBody is the refresh variant for clients that keep tokens themselves:
+// the refresh token comes in the body and the new pair goes back in the body
+func (h *Handler) RefreshTokenBody(c *fiber.Ctx) error {
+	req := new(request.RefreshTokenRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(response.Error(400, "Invalid request body"))
+	}
+
+	if req.RefreshToken == "" {
+		return c.Status(401).JSON(response.Error(401, "Refresh token not found"))
+	}
+
+	tokenPair, err := h.sessions.RefreshSession(c.Context(), req.RefreshToken, sessionMeta(c))
+	if err != nil {
+		return h.writeRefreshError(c, err)
+	}
+
+	return c.JSON(response.Success(resp.ToTokenDto(tokenPair), "Token refreshed successfully"))
+}
+
+func (h *Handler) writeRefreshError(c *fiber.Ctx, err error) error {
+	h.log.Error("failed to refresh session: %v", err)
+
+	if appErr, ok := err.(*errors.AppError); ok && appErr.Code == 401 {
+		return c.Status(401).JSON(response.Error(401, appErr.Message))
+	}
+
+	return c.Status(500).JSON(response.Error(500, "Failed to generate tokens"))
+}
+
+// sessionMeta captures the client details stored on a new session
+func sessionMeta(c *fiber.Ctx) userusecase.SessionMeta {
+	return userusecase.SessionMeta{
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		IPAddress: c.IP(),
+	}
+}
+
+// tokenDelivery picks where to return tokens: an explicit token_delivery wins,
+// otherwise mobile and service clients (X-Client-Type) get them in the body
+func tokenDelivery(c *fiber.Ctx, requested string) string {
+	switch requested {
+	case consts.TokenDeliveryBody, consts.TokenDeliveryCookie:
+		return requested
+	}
+
+	switch strings.ToLower(c.Get("X-Client-Type")) {
+	case consts.ClientTypeMobile, consts.ClientTypeService:
+		return consts.TokenDeliveryBody
+	}
+
+	return consts.TokenDeliveryCookie
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"wallet_api/internal/common/base"
+	"wallet_api/internal/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AccessTokenRepository interface {
+	CreateBatch(ctx context.Context, tokens []*entity.AccessToken) error
+	FindByHash(ctx context.Context, tokenHash string) (*entity.AccessToken, error)
+	MarkUsed(ctx context.Context, id uuid.UUID) (bool, error)
+}
+
+type accessTokenRepository struct {
+	*base.BaseRepository[entity.AccessToken]
+	db *gorm.DB
+}
+
+func NewAccessTokenRepository(db *gorm.DB) AccessTokenRepository {
+	return &accessTokenRepository{
+		BaseRepository: base.NewBaseRepository[entity.AccessToken](db),
+		db:             db,
+	}
+}
+
+// FindByHash returns the recorded token together with its session, or nil when the hash is unknown
+func (r *accessTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*entity.AccessToken, error) {
+	var token entity.AccessToken
+	err := r.db.WithContext(ctx).
+		Preload("Session").
+		Where("token_hash = ?", tokenHash).
+		First(&token).
+		Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed flags a refresh token as rotated. It reports false when the token had already been used,
+// which makes concurrent rotations of the same token detectable as reuse.
+func (r *accessTokenRepository) MarkUsed(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&entity.AccessToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
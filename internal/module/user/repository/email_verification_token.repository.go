@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"wallet_api/internal/common/base"
+	"wallet_api/internal/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type EmailVerificationTokenRepository interface {
+	Create(ctx context.Context, token *entity.EmailVerificationToken) error
+	Consume(ctx context.Context, tokenHash string, now time.Time) (*entity.EmailVerificationToken, error)
+	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
+}
+
+type emailVerificationTokenRepository struct {
+	*base.BaseRepository[entity.EmailVerificationToken]
+	db *gorm.DB
+}
+
+func NewEmailVerificationTokenRepository(db *gorm.DB) EmailVerificationTokenRepository {
+	return &emailVerificationTokenRepository{
+		BaseRepository: base.NewBaseRepository[entity.EmailVerificationToken](db),
+		db:             db,
+	}
+}
+
+// Consume marks an unused, unexpired token as used in one statement and returns it.
+// It returns nil when there is no such token, so a link can only be followed once.
+func (r *emailVerificationTokenRepository) Consume(ctx context.Context, tokenHash string, now time.Time) (*entity.EmailVerificationToken, error) {
+	var tokens []entity.EmailVerificationToken
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE email_verification_tokens SET used_at = ?
+		WHERE token_hash = ? AND used_at IS NULL AND expires_at > ?
+		RETURNING *`,
+		now, tokenHash, now,
+	).Scan(&tokens).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	return &tokens[0], nil
+}
+
+func (r *emailVerificationTokenRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&entity.EmailVerificationToken{}).Error
+}
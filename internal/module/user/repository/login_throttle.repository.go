@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"wallet_api/internal/common/base"
+	"wallet_api/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+type LoginThrottleRepository interface {
+	IsLocked(ctx context.Context, keys []string, now time.Time) (bool, error)
+	RecordFailure(ctx context.Context, key string, now time.Time, window time.Duration) (int, error)
+	LockUntil(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
+}
+
+type loginThrottleRepository struct {
+	*base.BaseRepository[entity.LoginThrottle]
+	db *gorm.DB
+}
+
+func NewLoginThrottleRepository(db *gorm.DB) LoginThrottleRepository {
+	return &loginThrottleRepository{
+		BaseRepository: base.NewBaseRepository[entity.LoginThrottle](db),
+		db:             db,
+	}
+}
+
+func (r *loginThrottleRepository) IsLocked(ctx context.Context, keys []string, now time.Time) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&entity.LoginThrottle{}).
+		Where("key IN ? AND locked_until > ?", keys, now).
+		Count(&count).
+		Error
+	return count > 0, err
+}
+
+// RecordFailure increments the failure counter atomically and returns the count in the current window.
+// A counter whose window has passed starts again at 1.
+func (r *loginThrottleRepository) RecordFailure(ctx context.Context, key string, now time.Time, window time.Duration) (int, error) {
+	windowStart := now.Add(-window)
+
+	var failures int
+	err := r.db.WithContext(ctx).Raw(`
+		INSERT INTO login_throttles (key, failures, window_started_at, updated_at)
+		VALUES (?, 1, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN login_throttles.window_started_at < ? THEN 1 ELSE login_throttles.failures + 1 END,
+			window_started_at = CASE WHEN login_throttles.window_started_at < ? THEN EXCLUDED.window_started_at ELSE login_throttles.window_started_at END,
+			updated_at = EXCLUDED.updated_at
+		RETURNING failures`,
+		key, now, now, windowStart, windowStart,
+	).Scan(&failures).Error
+
+	return failures, err
+}
+
+func (r *loginThrottleRepository) LockUntil(ctx context.Context, key string, until time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&entity.LoginThrottle{}).
+		Where("key = ?", key).
+		Update("locked_until", until).
+		Error
+}
+
+func (r *loginThrottleRepository) Reset(ctx context.Context, key string) error {
+	return r.db.WithContext(ctx).Where("key = ?", key).Delete(&entity.LoginThrottle{}).Error
+}
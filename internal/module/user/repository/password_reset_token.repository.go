@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"wallet_api/internal/common/base"
+	"wallet_api/internal/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PasswordResetTokenRepository interface {
+	Create(ctx context.Context, token *entity.PasswordResetToken) error
+	Consume(ctx context.Context, tokenHash string, now time.Time) (*uuid.UUID, error)
+	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
+}
+
+type passwordResetTokenRepository struct {
+	*base.BaseRepository[entity.PasswordResetToken]
+	db *gorm.DB
+}
+
+func NewPasswordResetTokenRepository(db *gorm.DB) PasswordResetTokenRepository {
+	return &passwordResetTokenRepository{
+		BaseRepository: base.NewBaseRepository[entity.PasswordResetToken](db),
+		db:             db,
+	}
+}
+
+// Consume marks an unused, unexpired token as used in one statement and returns its user.
+// It returns nil when there is no such token, so a token can only ever be redeemed once.
+func (r *passwordResetTokenRepository) Consume(ctx context.Context, tokenHash string, now time.Time) (*uuid.UUID, error) {
+	var userIDs []uuid.UUID
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE password_reset_tokens SET used_at = ?
+		WHERE token_hash = ? AND used_at IS NULL AND expires_at > ?
+		RETURNING user_id`,
+		now, tokenHash, now,
+	).Scan(&userIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	return &userIDs[0], nil
+}
+
+func (r *passwordResetTokenRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&entity.PasswordResetToken{}).Error
+}
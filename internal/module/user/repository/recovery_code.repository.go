@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"wallet_api/internal/common/base"
+	"wallet_api/internal/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RecoveryCodeRepository interface {
+	Replace(ctx context.Context, userID uuid.UUID, codeHashes []string) error
+	Consume(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error)
+	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
+}
+
+type recoveryCodeRepository struct {
+	*base.BaseRepository[entity.RecoveryCode]
+	db *gorm.DB
+}
+
+func NewRecoveryCodeRepository(db *gorm.DB) RecoveryCodeRepository {
+	return &recoveryCodeRepository{
+		BaseRepository: base.NewBaseRepository[entity.RecoveryCode](db),
+		db:             db,
+	}
+}
+
+// Replace discards every existing code of the user and stores the new set
+func (r *recoveryCodeRepository) Replace(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&entity.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		codes := make([]*entity.RecoveryCode, len(codeHashes))
+		for i, hash := range codeHashes {
+			codes[i] = &entity.RecoveryCode{UserID: userID, CodeHash: hash}
+		}
+
+		return tx.Create(codes).Error
+	})
+}
+
+// Consume marks an unused code as used and reports whether one was found
+func (r *recoveryCodeRepository) Consume(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&entity.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *recoveryCodeRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&entity.RecoveryCode{}).Error
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+
+	"wallet_api/internal/common/base"
+	"wallet_api/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+type SecurityEventRepository interface {
+	Create(ctx context.Context, event *entity.SecurityEvent) error
+}
+
+type securityEventRepository struct {
+	*base.BaseRepository[entity.SecurityEvent]
+}
+
+func NewSecurityEventRepository(db *gorm.DB) SecurityEventRepository {
+	return &securityEventRepository{
+		BaseRepository: base.NewBaseRepository[entity.SecurityEvent](db),
+	}
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"wallet_api/internal/common/base"
+	"wallet_api/internal/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *entity.Session) error
+	FindByID(ctx context.Context, id uuid.UUID) (*entity.Session, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+	RevokeAllByUserID(ctx context.Context, userID uuid.UUID) error
+	RevokeAllByUserIDExcept(ctx context.Context, userID, keepSessionID uuid.UUID) error
+	RevokeForUser(ctx context.Context, id, userID uuid.UUID) (bool, error)
+	FindActiveByUserID(ctx context.Context, userID uuid.UUID, now time.Time) ([]*entity.Session, error)
+	Touch(ctx context.Context, id uuid.UUID, now time.Time, interval time.Duration) error
+}
+
+type sessionRepository struct {
+	*base.BaseRepository[entity.Session]
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{
+		BaseRepository: base.NewBaseRepository[entity.Session](db),
+		db:             db,
+	}
+}
+
+func (r *sessionRepository) FindByID(ctx context.Context, id uuid.UUID) (*entity.Session, error) {
+	session, err := r.BaseRepository.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return session, nil
+}
+
+func (r *sessionRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	return r.UpdateFields(ctx, id, map[string]interface{}{"is_revoked": true})
+}
+
+func (r *sessionRepository) RevokeAllByUserID(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Model(&entity.Session{}).
+		Where("user_id = ? AND is_revoked = ?", userID, false).
+		Update("is_revoked", true).
+		Error
+}
+
+func (r *sessionRepository) RevokeAllByUserIDExcept(ctx context.Context, userID, keepSessionID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Model(&entity.Session{}).
+		Where("user_id = ? AND id <> ? AND is_revoked = ?", userID, keepSessionID, false).
+		Update("is_revoked", true).
+		Error
+}
+
+// RevokeForUser revokes a live session only if it belongs to the user, reporting whether it did
+func (r *sessionRepository) RevokeForUser(ctx context.Context, id, userID uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&entity.Session{}).
+		Where("id = ? AND user_id = ? AND is_revoked = ?", id, userID, false).
+		Update("is_revoked", true)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// FindActiveByUserID returns the sessions that are neither revoked nor expired, most recently used first
+func (r *sessionRepository) FindActiveByUserID(ctx context.Context, userID uuid.UUID, now time.Time) ([]*entity.Session, error) {
+	var sessions []*entity.Session
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND is_revoked = ? AND expired_at > ?", userID, false, now).
+		Order("last_seen_at DESC NULLS LAST, created_at DESC").
+		Find(&sessions).
+		Error
+	return sessions, err
+}
+
+// Touch records activity on a session, skipping the write if it was recorded less than interval ago
+func (r *sessionRepository) Touch(ctx context.Context, id uuid.UUID, now time.Time, interval time.Duration) error {
+	return r.db.WithContext(ctx).
+		Model(&entity.Session{}).
+		Where("id = ? AND (last_seen_at IS NULL OR last_seen_at < ?)", id, now.Add(-interval)).
+		Update("last_seen_at", now).
+		Error
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"wallet_api/internal/common/base"
 	"wallet_api/internal/entity"
@@ -17,6 +19,11 @@ type UserRepository interface {
 	FindByUsername(ctx context.Context, username string) (*entity.User, error)
 	FindByEmail(ctx context.Context, email string) (*entity.User, error)
 	Update(ctx context.Context, user *entity.User) error
+	UpdateFields(ctx context.Context, id uuid.UUID, fields map[string]interface{}) error
+	Search(ctx context.Context, query string, limit, offset int) ([]*entity.User, int64, error)
+	AdvanceTOTPStep(ctx context.Context, id uuid.UUID, step int64) (bool, error)
+	RecordTwoFactorFailure(ctx context.Context, id uuid.UUID, maxAttempts int, lockUntil time.Time) (bool, error)
+	RecordPINFailure(ctx context.Context, id uuid.UUID, maxAttempts int, lockUntil time.Time) (bool, error)
 }
 
 type userRepository struct {
@@ -54,3 +61,68 @@ func (r *userRepository) FindByEmail(ctx context.Context, email string) (*entity
 	}
 	return &user, nil
 }
+
+// Search matches users by username or email, newest first. An empty query lists everyone.
+func (r *userRepository) Search(ctx context.Context, query string, limit, offset int) ([]*entity.User, int64, error) {
+	var users []*entity.User
+	var total int64
+
+	db := r.db.WithContext(ctx).Model(&entity.User{})
+	if query != "" {
+		pattern := "%" + query + "%"
+		db = db.Where("username ILIKE ? OR email ILIKE ?", pattern, pattern)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := db.Order("created_at DESC").Limit(limit).Offset(offset).Find(&users).Error
+	return users, total, err
+}
+
+// AdvanceTOTPStep records step as the last accepted TOTP step. It reports false when a code
+// of this or a later step was already used, so every code works only once.
+func (r *userRepository) AdvanceTOTPStep(ctx context.Context, id uuid.UUID, step int64) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&entity.User{}).
+		Where("id = ? AND totp_last_used_step < ?", id, step).
+		Update("totp_last_used_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RecordTwoFactorFailure counts a wrong 2FA code in one statement, so concurrent failures
+// can't overwrite each other. It reports true when this failure engaged the lock.
+func (r *userRepository) RecordTwoFactorFailure(ctx context.Context, id uuid.UUID, maxAttempts int, lockUntil time.Time) (bool, error) {
+	return r.recordFailure(ctx, id, "two_factor_failed_attempts", "two_factor_locked_until", maxAttempts, lockUntil)
+}
+
+// RecordPINFailure counts a wrong PIN the same way, against the PIN's own counter.
+func (r *userRepository) RecordPINFailure(ctx context.Context, id uuid.UUID, maxAttempts int, lockUntil time.Time) (bool, error) {
+	return r.recordFailure(ctx, id, "pin_failed_attempts", "pin_locked_until", maxAttempts, lockUntil)
+}
+
+// recordFailure increments counter and, once it reaches maxAttempts, resets it and sets lockColumn.
+// The counter comes back as 0 only when this update took the lock.
+func (r *userRepository) recordFailure(ctx context.Context, id uuid.UUID, counter, lockColumn string, maxAttempts int, lockUntil time.Time) (bool, error) {
+	var attempts []int
+	err := r.db.WithContext(ctx).Raw(fmt.Sprintf(`
+		UPDATE users SET
+			%[1]s = CASE WHEN %[1]s + 1 >= ? THEN 0 ELSE %[1]s + 1 END,
+			%[2]s = CASE WHEN %[1]s + 1 >= ? THEN ? ELSE %[2]s END,
+			updated_at = ?
+		WHERE id = ?
+		RETURNING %[1]s`, counter, lockColumn),
+		maxAttempts, maxAttempts, lockUntil, time.Now(), id,
+	).Scan(&attempts).Error
+	if err != nil {
+		return false, err
+	}
+	if len(attempts) == 0 {
+		return false, gorm.ErrRecordNotFound
+	}
+	return attempts[0] == 0, nil
+}
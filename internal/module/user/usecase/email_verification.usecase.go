@@ -0,0 +1,201 @@
+package userusecase
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"time"
+
+	"wallet_api/internal/common/consts"
+	"wallet_api/internal/common/errors"
+	"wallet_api/internal/entity"
+	"wallet_api/internal/module/user/repository"
+	"wallet_api/internal/utils"
+	"wallet_api/pkg/logger"
+	"wallet_api/pkg/mailer"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const verificationTokenBytes = 32
+
+var (
+	ErrInvalidVerificationToken = errors.New(400, "Invalid or expired verification token", nil)
+	errEmailAlreadyVerified     = errors.New(400, "Email already verified", nil)
+)
+
+// EmailVerificationConfig controls the verification link sent by email
+type EmailVerificationConfig struct {
+	URL      string
+	TokenTTL time.Duration
+}
+
+type EmailVerificationUseCase interface {
+	// SendVerification mails a link for the pending email if there is one, otherwise for the current email
+	SendVerification(ctx context.Context, user *entity.User) error
+	ResendVerification(ctx context.Context, userID uuid.UUID) error
+	VerifyEmail(ctx context.Context, token string, meta SessionMeta) error
+	IsEmailVerified(ctx context.Context, userID uuid.UUID) (bool, error)
+}
+
+type emailVerificationUseCase struct {
+	repo      repository.UserRepository
+	tokenRepo repository.EmailVerificationTokenRepository
+	mailer    mailer.Interface
+	cfg       EmailVerificationConfig
+	security  *securityLog
+	log       logger.Interface
+}
+
+func NewEmailVerificationUseCase(
+	repo repository.UserRepository,
+	tokenRepo repository.EmailVerificationTokenRepository,
+	securityEventRepo repository.SecurityEventRepository,
+	mail mailer.Interface,
+	cfg EmailVerificationConfig,
+	log logger.Interface,
+) EmailVerificationUseCase {
+	return &emailVerificationUseCase{
+		repo:      repo,
+		tokenRepo: tokenRepo,
+		mailer:    mail,
+		cfg:       cfg,
+		security:  &securityLog{repo: securityEventRepo, log: log},
+		log:       log,
+	}
+}
+
+func (uc *emailVerificationUseCase) SendVerification(ctx context.Context, user *entity.User) error {
+	email := user.Email
+	if user.PendingEmail != nil {
+		email = *user.PendingEmail
+	} else if user.EmailVerified() {
+		return errEmailAlreadyVerified
+	}
+	if email == "" {
+		return errors.New(400, "No email address to verify", nil)
+	}
+
+	token, err := utils.GenerateRandomToken(verificationTokenBytes)
+	if err != nil {
+		return fmt.Errorf("failed to generate verification token: %w", err)
+	}
+
+	// Only the newest link works
+	if err := uc.tokenRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to delete old verification tokens: %w", err)
+	}
+
+	verificationToken := &entity.EmailVerificationToken{
+		UserID:    user.ID,
+		Email:     email,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(uc.cfg.TokenTTL),
+	}
+	if err := uc.tokenRepo.Create(ctx, verificationToken); err != nil {
+		return fmt.Errorf("failed to store verification token: %w", err)
+	}
+
+	msg := mailer.Message{
+		To:      email,
+		Subject: "Verify your wallet_api email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nOpen the link below to confirm this email address. It expires in %d hours.\n\n%s\n\nWithdrawals and transfers stay disabled until the address is confirmed.\n",
+			user.Username, int(uc.cfg.TokenTTL.Hours()), tokenLink(uc.cfg.URL, token),
+		),
+	}
+	if err := uc.mailer.Send(ctx, msg); err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+
+	return nil
+}
+
+func (uc *emailVerificationUseCase) ResendVerification(ctx context.Context, userID uuid.UUID) error {
+	user, err := uc.findUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return uc.SendVerification(ctx, user)
+}
+
+// VerifyEmail redeems a verification token. A token for the pending email also swaps it in
+// as the account email; a token for an address the user has since replaced is rejected.
+func (uc *emailVerificationUseCase) VerifyEmail(ctx context.Context, token string, meta SessionMeta) error {
+	verificationToken, err := uc.tokenRepo.Consume(ctx, utils.HashToken(token), time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to consume verification token: %w", err)
+	}
+	if verificationToken == nil {
+		return ErrInvalidVerificationToken
+	}
+
+	user, err := uc.findUser(ctx, verificationToken.UserID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	fields := map[string]interface{}{"email_verified_at": now}
+	details := "email address verified"
+
+	switch {
+	case user.PendingEmail != nil && *user.PendingEmail == verificationToken.Email:
+		// Someone may have registered the address while the link was in the mailbox
+		taken, err := uc.repo.FindByEmail(ctx, verificationToken.Email)
+		if err != nil {
+			return fmt.Errorf("failed to check email: %w", err)
+		}
+		if taken != nil && taken.ID != user.ID {
+			return errors.New(409, "Email already taken", nil)
+		}
+
+		fields["email"] = verificationToken.Email
+		fields["pending_email"] = nil
+		details = fmt.Sprintf("email changed from %s to %s", user.Email, verificationToken.Email)
+	case user.PendingEmail == nil && user.Email == verificationToken.Email:
+	default:
+		return ErrInvalidVerificationToken
+	}
+
+	if err := uc.repo.UpdateFields(ctx, user.ID, fields); err != nil {
+		return fmt.Errorf("failed to verify email: %w", err)
+	}
+
+	if err := uc.tokenRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		uc.log.Error("failed to delete verification tokens: %v", err)
+	}
+
+	uc.security.record(ctx, &entity.SecurityEvent{
+		UserID:    &user.ID,
+		EventType: consts.SecurityEventEmailVerified,
+		IPAddress: meta.IPAddress,
+		UserAgent: meta.UserAgent,
+		Details:   details,
+	})
+
+	return nil
+}
+
+func (uc *emailVerificationUseCase) IsEmailVerified(ctx context.Context, userID uuid.UUID) (bool, error) {
+	user, err := uc.findUser(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return user.EmailVerified(), nil
+}
+
+func (uc *emailVerificationUseCase) findUser(ctx context.Context, userID uuid.UUID) (*entity.User, error) {
+	user, err := uc.repo.FindByID(ctx, userID)
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	return user, nil
+}
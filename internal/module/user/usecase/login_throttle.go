@@ -0,0 +1,93 @@
+package userusecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"wallet_api/internal/module/user/repository"
+)
+
+// LoginThrottleConfig controls how failed logins are slowed down and locked out
+type LoginThrottleConfig struct {
+	MaxFailures     int
+	MaxIPFailures   int
+	Window          time.Duration
+	LockoutDuration time.Duration
+	DelayAfter      int
+	BaseDelay       time.Duration
+}
+
+// loginThrottle tracks failed logins per username and per IP in Postgres,
+// so the limits hold no matter which replica serves the request
+type loginThrottle struct {
+	repo repository.LoginThrottleRepository
+	cfg  LoginThrottleConfig
+	now  func() time.Time
+}
+
+func userThrottleKey(username string) string {
+	return "user:" + strings.ToLower(strings.TrimSpace(username))
+}
+
+func ipThrottleKey(ip string) string {
+	return "ip:" + ip
+}
+
+func (t *loginThrottle) locked(ctx context.Context, username, ip string) (bool, error) {
+	return t.repo.IsLocked(ctx, []string{userThrottleKey(username), ipThrottleKey(ip)}, t.now())
+}
+
+// fail records a failed attempt for both the username and the IP and locks them when needed.
+// Only usernames get progressive delays; an IP is shared by many users behind NAT and is
+// only locked once it reaches MaxIPFailures.
+func (t *loginThrottle) fail(ctx context.Context, username, ip string) error {
+	if err := t.failKey(ctx, userThrottleKey(username), t.cfg.MaxFailures, true); err != nil {
+		return err
+	}
+	return t.failKey(ctx, ipThrottleKey(ip), t.cfg.MaxIPFailures, false)
+}
+
+func (t *loginThrottle) failKey(ctx context.Context, key string, maxFailures int, progressive bool) error {
+	now := t.now()
+
+	failures, err := t.repo.RecordFailure(ctx, key, now, t.cfg.Window)
+	if err != nil {
+		return err
+	}
+
+	if delay := t.lockFor(failures, maxFailures, progressive); delay > 0 {
+		return t.repo.LockUntil(ctx, key, now.Add(delay))
+	}
+	return nil
+}
+
+// lockFor returns how long a key stays locked after its n-th failure in the window.
+// From DelayAfter on the wait doubles every failure, at maxFailures it becomes a full lockout.
+func (t *loginThrottle) lockFor(failures, maxFailures int, progressive bool) time.Duration {
+	if maxFailures > 0 && failures >= maxFailures {
+		return t.cfg.LockoutDuration
+	}
+	if !progressive || t.cfg.DelayAfter <= 0 || failures < t.cfg.DelayAfter {
+		return 0
+	}
+
+	delay := t.cfg.BaseDelay
+	for i := t.cfg.DelayAfter; i < failures && delay < t.cfg.LockoutDuration; i++ {
+		delay *= 2
+	}
+	if delay > t.cfg.LockoutDuration {
+		delay = t.cfg.LockoutDuration
+	}
+	return delay
+}
+
+// succeed clears the username counter. The IP counter is left alone so one valid
+// account cannot be used to reset a credential-stuffing run.
+func (t *loginThrottle) succeed(ctx context.Context, username string) error {
+	return t.repo.Reset(ctx, userThrottleKey(username))
+}
+
+func (t *loginThrottle) unlock(ctx context.Context, username string) error {
+	return t.repo.Reset(ctx, userThrottleKey(username))
+}
@@ -0,0 +1,50 @@
+package userusecase
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoginThrottleLockFor(t *testing.T) {
+	throttle := &loginThrottle{cfg: LoginThrottleConfig{
+		MaxFailures:     6,
+		DelayAfter:      3,
+		BaseDelay:       time.Second,
+		LockoutDuration: 15 * time.Minute,
+	}}
+
+	tests := []struct {
+		failures    int
+		progressive bool
+		want        time.Duration
+	}{
+		{failures: 1, progressive: true, want: 0},
+		{failures: 2, progressive: true, want: 0},
+		{failures: 3, progressive: true, want: time.Second},
+		{failures: 4, progressive: true, want: 2 * time.Second},
+		{failures: 5, progressive: true, want: 4 * time.Second},
+		{failures: 6, progressive: true, want: 15 * time.Minute},
+		{failures: 9, progressive: true, want: 15 * time.Minute},
+		{failures: 5, progressive: false, want: 0},
+		{failures: 6, progressive: false, want: 15 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := throttle.lockFor(tt.failures, 6, tt.progressive); got != tt.want {
+			t.Errorf("lockFor(%d, progressive=%v) = %v, want %v", tt.failures, tt.progressive, got, tt.want)
+		}
+	}
+}
+
+func TestLoginThrottleDelayIsCapped(t *testing.T) {
+	throttle := &loginThrottle{cfg: LoginThrottleConfig{
+		DelayAfter:      1,
+		BaseDelay:       time.Minute,
+		LockoutDuration: 5 * time.Minute,
+	}}
+
+	// No MaxFailures: delays grow but never beyond the lockout duration
+	if got := throttle.lockFor(10, 0, true); got != 5*time.Minute {
+		t.Errorf("lockFor() = %v, want %v", got, 5*time.Minute)
+	}
+}
@@ -0,0 +1,225 @@
+package userusecase
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"wallet_api/internal/common/consts"
+	"wallet_api/internal/common/errors"
+	"wallet_api/internal/entity"
+	"wallet_api/internal/module/user/repository"
+	"wallet_api/internal/utils"
+	"wallet_api/pkg/logger"
+	"wallet_api/pkg/mailer"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const resetTokenBytes = 32
+
+var (
+	ErrInvalidResetToken     = errors.New(400, "Invalid or expired reset token", nil)
+	ErrWrongCurrentPassword  = errors.New(400, "Current password is incorrect", nil)
+	errPasswordSameAsCurrent = errors.New(400, "New password must be different from the current password", nil)
+)
+
+// PasswordResetConfig controls the reset link sent by email
+type PasswordResetConfig struct {
+	URL      string
+	TokenTTL time.Duration
+}
+
+type PasswordUseCase interface {
+	ForgotPassword(ctx context.Context, email string, meta SessionMeta) error
+	ResetPassword(ctx context.Context, token, newPassword string, meta SessionMeta) error
+	// ChangePassword keeps the calling session alive and signs out every other one
+	ChangePassword(ctx context.Context, userID, sessionID uuid.UUID, currentPassword, newPassword string, meta SessionMeta) error
+}
+
+type passwordUseCase struct {
+	repo        repository.UserRepository
+	resetRepo   repository.PasswordResetTokenRepository
+	sessionRepo repository.SessionRepository
+	mailer      mailer.Interface
+	cfg         PasswordResetConfig
+	security    *securityLog
+	log         logger.Interface
+}
+
+func NewPasswordUseCase(
+	repo repository.UserRepository,
+	resetRepo repository.PasswordResetTokenRepository,
+	sessionRepo repository.SessionRepository,
+	securityEventRepo repository.SecurityEventRepository,
+	mail mailer.Interface,
+	cfg PasswordResetConfig,
+	log logger.Interface,
+) PasswordUseCase {
+	return &passwordUseCase{
+		repo:        repo,
+		resetRepo:   resetRepo,
+		sessionRepo: sessionRepo,
+		mailer:      mail,
+		cfg:         cfg,
+		security:    &securityLog{repo: securityEventRepo, log: log},
+		log:         log,
+	}
+}
+
+// ForgotPassword emails a reset link. It returns nil for unknown addresses and delivery
+// failures too, so the endpoint cannot be used to find out which emails are registered.
+func (uc *passwordUseCase) ForgotPassword(ctx context.Context, email string, meta SessionMeta) error {
+	user, err := uc.repo.FindByEmail(ctx, strings.TrimSpace(email))
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+	if user == nil {
+		return nil
+	}
+
+	token, err := utils.GenerateRandomToken(resetTokenBytes)
+	if err != nil {
+		return fmt.Errorf("failed to generate reset token: %w", err)
+	}
+
+	// Only the newest link works
+	if err := uc.resetRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to delete old reset tokens: %w", err)
+	}
+
+	resetToken := &entity.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(uc.cfg.TokenTTL),
+		IPAddress: meta.IPAddress,
+	}
+	if err := uc.resetRepo.Create(ctx, resetToken); err != nil {
+		return fmt.Errorf("failed to store reset token: %w", err)
+	}
+
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your wallet_api password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nOpen the link below to choose a new password. It expires in %d minutes and can only be used once.\n\n%s\n\nIf you did not ask for this, you can ignore this email.\n",
+			user.Username, int(uc.cfg.TokenTTL.Minutes()), tokenLink(uc.cfg.URL, token),
+		),
+	}
+	if err := uc.mailer.Send(ctx, msg); err != nil {
+		uc.log.Error("failed to send password reset email: %v", err)
+	}
+
+	return nil
+}
+
+// ResetPassword redeems a reset token, sets the new password and logs the user out everywhere
+func (uc *passwordUseCase) ResetPassword(ctx context.Context, token, newPassword string, meta SessionMeta) error {
+	if err := validatePassword(newPassword); err != nil {
+		return err
+	}
+
+	userID, err := uc.resetRepo.Consume(ctx, utils.HashToken(token), time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to consume reset token: %w", err)
+	}
+	if userID == nil {
+		return ErrInvalidResetToken
+	}
+
+	hashedPassword, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	if err := uc.repo.UpdateFields(ctx, *userID, map[string]interface{}{"password_hash": hashedPassword}); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	// Whoever had the old password must not keep a session
+	if err := uc.sessionRepo.RevokeAllByUserID(ctx, *userID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	if err := uc.resetRepo.DeleteByUserID(ctx, *userID); err != nil {
+		uc.log.Error("failed to delete reset tokens: %v", err)
+	}
+
+	uc.security.record(ctx, &entity.SecurityEvent{
+		UserID:    userID,
+		EventType: consts.SecurityEventPasswordReset,
+		IPAddress: meta.IPAddress,
+		UserAgent: meta.UserAgent,
+		Details:   "password reset by email link, all sessions revoked",
+	})
+
+	return nil
+}
+
+func (uc *passwordUseCase) ChangePassword(ctx context.Context, userID, sessionID uuid.UUID, currentPassword, newPassword string, meta SessionMeta) error {
+	user, err := uc.repo.FindByID(ctx, userID)
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.ErrNotFound
+		}
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	if err := utils.VerifyPassword(user.PasswordHash, currentPassword); err != nil {
+		return ErrWrongCurrentPassword
+	}
+
+	if err := validatePassword(newPassword); err != nil {
+		return err
+	}
+	if newPassword == currentPassword {
+		return errPasswordSameAsCurrent
+	}
+
+	hashedPassword, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	if err := uc.repo.UpdateFields(ctx, userID, map[string]interface{}{"password_hash": hashedPassword}); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	// Revoking a session also kills its refresh tokens
+	if err := uc.sessionRepo.RevokeAllByUserIDExcept(ctx, userID, sessionID); err != nil {
+		return fmt.Errorf("failed to revoke other sessions: %w", err)
+	}
+
+	// A reset link mailed before the change must not undo it
+	if err := uc.resetRepo.DeleteByUserID(ctx, userID); err != nil {
+		uc.log.Error("failed to delete reset tokens: %v", err)
+	}
+
+	uc.security.record(ctx, &entity.SecurityEvent{
+		UserID:    &userID,
+		SessionID: &sessionID,
+		EventType: consts.SecurityEventPasswordChanged,
+		IPAddress: meta.IPAddress,
+		UserAgent: meta.UserAgent,
+		Details:   "password changed, other sessions revoked",
+	})
+
+	return nil
+}
+
+// tokenLink appends the token to a frontend URL as ?token=
+func tokenLink(base, token string) string {
+	link, err := url.Parse(base)
+	if err != nil {
+		return base + "?token=" + url.QueryEscape(token)
+	}
+
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String()
+}
@@ -0,0 +1,57 @@
+package userusecase
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"wallet_api/internal/common/errors"
+)
+
+const (
+	minPasswordLength = 8
+	// bcrypt ignores everything after 72 bytes
+	maxPasswordLength = 72
+)
+
+var (
+	errPasswordTooShort = errors.New(400, fmt.Sprintf("Password must be at least %d characters", minPasswordLength), nil)
+	errPasswordTooLong  = errors.New(400, fmt.Sprintf("Password must be at most %d bytes", maxPasswordLength), nil)
+	errPasswordBanned   = errors.New(400, "Password is too common, choose a different one", nil)
+)
+
+//go:embed banned_passwords.txt
+var bannedPasswordList string
+
+var bannedPasswords = parseBannedPasswords(bannedPasswordList)
+
+func parseBannedPasswords(list string) map[string]struct{} {
+	banned := make(map[string]struct{})
+
+	scanner := bufio.NewScanner(strings.NewReader(list))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		banned[strings.ToLower(line)] = struct{}{}
+	}
+
+	return banned
+}
+
+// validatePassword applies the password policy to a new password
+func validatePassword(password string) error {
+	if len([]rune(password)) < minPasswordLength {
+		return errPasswordTooShort
+	}
+	if len(password) > maxPasswordLength {
+		return errPasswordTooLong
+	}
+	if _, ok := bannedPasswords[strings.ToLower(password)]; ok {
+		return errPasswordBanned
+	}
+
+	return nil
+}
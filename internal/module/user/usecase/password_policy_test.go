@@ -0,0 +1,37 @@
+package userusecase
+
+import "testing"
+
+func TestValidatePassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		want     error
+	}{
+		{name: "too short", password: "s3cr3t!", want: errPasswordTooShort},
+		{name: "multibyte counted as characters", password: "ééééééé", want: errPasswordTooShort},
+		{name: "too long", password: string(make([]byte, maxPasswordLength+1)), want: errPasswordTooLong},
+		{name: "banned", password: "password123", want: errPasswordBanned},
+		{name: "banned any case", password: "QWERTY123", want: errPasswordBanned},
+		{name: "ok", password: "correct horse battery", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validatePassword(tt.password); got != tt.want {
+				t.Errorf("validatePassword(%q) = %v, want %v", tt.password, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBannedPasswordListSkipsComments(t *testing.T) {
+	banned := parseBannedPasswords("# comment\n\n  hunter2  \n")
+
+	if len(banned) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(banned))
+	}
+	if _, ok := banned["hunter2"]; !ok {
+		t.Error("expected hunter2 to be banned")
+	}
+}
@@ -0,0 +1,214 @@
+package userusecase
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"time"
+
+	"wallet_api/internal/common/consts"
+	"wallet_api/internal/common/errors"
+	"wallet_api/internal/entity"
+	"wallet_api/internal/module/user/repository"
+	"wallet_api/internal/utils"
+	"wallet_api/pkg/logger"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	pinLength      = 6
+	maxPINAttempts = 5
+	pinLockout     = 15 * time.Minute
+)
+
+var (
+	ErrInvalidPIN       = errors.New(403, "Invalid PIN", nil)
+	ErrPINLocked        = errors.New(429, "Too many wrong PIN attempts, try again later", nil)
+	ErrPINNotSet        = errors.New(403, "Set a transaction PIN first", nil)
+	ErrStepUpRequired   = errors.New(403, "PIN or step-up token required for this amount", nil)
+	ErrInvalidStepUp    = errors.New(403, "Invalid or expired step-up token", nil)
+	errInvalidPINFormat = errors.New(400, fmt.Sprintf("PIN must be exactly %d digits", pinLength), nil)
+)
+
+// StepUpToken is handed out after a PIN check and accepted in place of the PIN until it expires
+type StepUpToken struct {
+	Token     string
+	ExpiresIn int64
+}
+
+type PINUseCase interface {
+	// SetPIN sets or replaces the transaction PIN, the account password is required either way
+	SetPIN(ctx context.Context, userID uuid.UUID, password, pin string, meta SessionMeta) error
+	StepUp(ctx context.Context, userID uuid.UUID, pin string) (*StepUpToken, error)
+	// VerifyStepUp accepts a valid step-up token or else checks the PIN
+	VerifyStepUp(ctx context.Context, userID uuid.UUID, pin, stepUpToken string) error
+}
+
+type pinUseCase struct {
+	repo       repository.UserRepository
+	jwtManager *utils.JWTManager
+	tokenTTL   time.Duration
+	security   *securityLog
+}
+
+func NewPINUseCase(
+	repo repository.UserRepository,
+	securityEventRepo repository.SecurityEventRepository,
+	jwtManager *utils.JWTManager,
+	stepUpTokenTTL time.Duration,
+	log logger.Interface,
+) PINUseCase {
+	return &pinUseCase{
+		repo:       repo,
+		jwtManager: jwtManager,
+		tokenTTL:   stepUpTokenTTL,
+		security:   &securityLog{repo: securityEventRepo, log: log},
+	}
+}
+
+func (uc *pinUseCase) SetPIN(ctx context.Context, userID uuid.UUID, password, pin string, meta SessionMeta) error {
+	if !validPINFormat(pin) {
+		return errInvalidPINFormat
+	}
+
+	user, err := uc.findUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := utils.VerifyPassword(user.PasswordHash, password); err != nil {
+		return ErrWrongCurrentPassword
+	}
+
+	pinHash, err := utils.HashPassword(pin)
+	if err != nil {
+		return fmt.Errorf("failed to hash PIN: %w", err)
+	}
+
+	err = uc.repo.UpdateFields(ctx, userID, map[string]interface{}{
+		"pin_hash":            pinHash,
+		"pin_failed_attempts": 0,
+		"pin_locked_until":    nil,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store PIN: %w", err)
+	}
+
+	uc.security.record(ctx, &entity.SecurityEvent{
+		UserID:    &userID,
+		EventType: consts.SecurityEventPINChanged,
+		IPAddress: meta.IPAddress,
+		UserAgent: meta.UserAgent,
+		Details:   "transaction PIN set",
+	})
+
+	return nil
+}
+
+func (uc *pinUseCase) StepUp(ctx context.Context, userID uuid.UUID, pin string) (*StepUpToken, error) {
+	user, err := uc.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.verifyPIN(ctx, user, pin); err != nil {
+		return nil, err
+	}
+
+	token, err := uc.jwtManager.GenerateStepUpToken(userID, uc.tokenTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate step-up token: %w", err)
+	}
+
+	return &StepUpToken{Token: token, ExpiresIn: int64(uc.tokenTTL.Seconds())}, nil
+}
+
+func (uc *pinUseCase) VerifyStepUp(ctx context.Context, userID uuid.UUID, pin, stepUpToken string) error {
+	if stepUpToken != "" {
+		claims, err := uc.jwtManager.ValidateStepUpToken(stepUpToken)
+		if err == nil && claims.UserID == userID {
+			return nil
+		}
+		if pin == "" {
+			return ErrInvalidStepUp
+		}
+	}
+
+	if pin == "" {
+		return ErrStepUpRequired
+	}
+
+	user, err := uc.findUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return uc.verifyPIN(ctx, user, pin)
+}
+
+// verifyPIN checks the PIN with its own failure counter, separate from login and 2FA
+func (uc *pinUseCase) verifyPIN(ctx context.Context, user *entity.User, pin string) error {
+	if !user.HasPIN() {
+		return ErrPINNotSet
+	}
+
+	now := time.Now()
+	if user.PINLockedUntil != nil && now.Before(*user.PINLockedUntil) {
+		return ErrPINLocked
+	}
+
+	if err := utils.VerifyPassword(user.PINHash, pin); err != nil {
+		// The count is kept in the database, user may be stale when requests race
+		locked, err := uc.repo.RecordPINFailure(ctx, user.ID, maxPINAttempts, now.Add(pinLockout))
+		if err != nil {
+			return fmt.Errorf("failed to record PIN failure: %w", err)
+		}
+
+		if locked {
+			uc.security.record(ctx, &entity.SecurityEvent{
+				UserID:    &user.ID,
+				EventType: consts.SecurityEventPINLocked,
+				Details:   fmt.Sprintf("%d wrong PIN attempts, locked for %s", maxPINAttempts, pinLockout),
+			})
+		}
+		return ErrInvalidPIN
+	}
+
+	if user.PINFailedAttempts > 0 || user.PINLockedUntil != nil {
+		err := uc.repo.UpdateFields(ctx, user.ID, map[string]interface{}{
+			"pin_failed_attempts": 0,
+			"pin_locked_until":    nil,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to reset PIN failures: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (uc *pinUseCase) findUser(ctx context.Context, userID uuid.UUID) (*entity.User, error) {
+	user, err := uc.repo.FindByID(ctx, userID)
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	return user, nil
+}
+
+func validPINFormat(pin string) bool {
+	if len(pin) != pinLength {
+		return false
+	}
+	for _, r := range pin {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,104 @@
+package userusecase
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"wallet_api/internal/common/consts"
+	"wallet_api/internal/entity"
+	"wallet_api/internal/module/user/repository"
+	"wallet_api/pkg/logger"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// memUsers hands out copies of the stored user, so every request works on its own snapshot
+// like it would with separate database reads. RecordPINFailure mirrors the atomic UPDATE.
+type memUsers struct {
+	repository.UserRepository
+	mu   sync.Mutex
+	user entity.User
+}
+
+func (r *memUsers) FindByID(_ context.Context, id uuid.UUID) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id != r.user.ID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	user := r.user
+	return &user, nil
+}
+
+func (r *memUsers) RecordPINFailure(_ context.Context, _ uuid.UUID, maxAttempts int, lockUntil time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.user.PINFailedAttempts+1 >= maxAttempts {
+		r.user.PINFailedAttempts = 0
+		r.user.PINLockedUntil = &lockUntil
+		return true, nil
+	}
+	r.user.PINFailedAttempts++
+	return false, nil
+}
+
+type memSecurityEvents struct {
+	mu     sync.Mutex
+	events []*entity.SecurityEvent
+}
+
+func (r *memSecurityEvents) Create(_ context.Context, event *entity.SecurityEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	return nil
+}
+
+type quietLogger struct{ logger.Interface }
+
+func (quietLogger) Warn(string, ...interface{})       {}
+func (quietLogger) Error(interface{}, ...interface{}) {}
+
+func TestVerifyPINConcurrentFailuresLock(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("123456"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := &memUsers{user: entity.User{ID: uuid.New(), PINHash: string(hash)}}
+	events := &memSecurityEvents{}
+	uc := &pinUseCase{repo: repo, security: &securityLog{repo: events, log: quietLogger{}}}
+
+	// All attempts read the user before any failure is recorded
+	snapshots := make([]*entity.User, maxPINAttempts)
+	for i := range snapshots {
+		snapshots[i], _ = repo.FindByID(context.Background(), repo.user.ID)
+	}
+
+	var wg sync.WaitGroup
+	for _, user := range snapshots {
+		wg.Add(1)
+		go func(user *entity.User) {
+			defer wg.Done()
+			if err := uc.verifyPIN(context.Background(), user, "000000"); err != ErrInvalidPIN {
+				t.Errorf("verifyPIN() error = %v, want %v", err, ErrInvalidPIN)
+			}
+		}(user)
+	}
+	wg.Wait()
+
+	if repo.user.PINLockedUntil == nil {
+		t.Fatalf("PIN not locked after %d concurrent failures", maxPINAttempts)
+	}
+	if len(events.events) != 1 || events.events[0].EventType != consts.SecurityEventPINLocked {
+		t.Errorf("security events = %v, want one %s", events.events, consts.SecurityEventPINLocked)
+	}
+
+	user, _ := repo.FindByID(context.Background(), repo.user.ID)
+	if err := uc.verifyPIN(context.Background(), user, "123456"); err != ErrPINLocked {
+		t.Errorf("verifyPIN() with the right PIN while locked = %v, want %v", err, ErrPINLocked)
+	}
+}
@@ -0,0 +1,25 @@
+package userusecase
+
+import (
+	"context"
+
+	"wallet_api/internal/entity"
+	"wallet_api/internal/module/user/repository"
+	"wallet_api/pkg/logger"
+)
+
+// securityLog persists security events and mirrors them to the application log.
+// Failing to store an event must never fail the request that triggered it.
+type securityLog struct {
+	repo repository.SecurityEventRepository
+	log  logger.Interface
+}
+
+func (s *securityLog) record(ctx context.Context, event *entity.SecurityEvent) {
+	s.log.Warn("security event %s: user=%v session=%v ip=%s details=%s",
+		event.EventType, event.UserID, event.SessionID, event.IPAddress, event.Details)
+
+	if err := s.repo.Create(ctx, event); err != nil {
+		s.log.Error("failed to record security event %s: %v", event.EventType, err)
+	}
+}
@@ -0,0 +1,247 @@
+package userusecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"wallet_api/internal/common/consts"
+	"wallet_api/internal/common/errors"
+	"wallet_api/internal/entity"
+	"wallet_api/internal/module/user/repository"
+	"wallet_api/internal/utils"
+	"wallet_api/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrSessionRevoked = errors.New(401, "Session has been revoked", nil)
+	ErrSessionExpired = errors.New(401, "Session has expired", nil)
+	ErrTokenNotIssued = errors.New(401, "Invalid token", nil)
+	ErrTokenReused    = errors.New(401, "Refresh token has already been used", nil)
+
+	errSessionNotFound = errors.New(404, "Session not found", nil)
+)
+
+// lastSeenInterval limits last_seen_at writes to one per session per interval
+const lastSeenInterval = time.Minute
+
+// SessionMeta describes the client a session was opened from
+type SessionMeta struct {
+	UserAgent string
+	IPAddress string
+}
+
+type SessionUseCase interface {
+	CreateSession(ctx context.Context, user *entity.User, meta SessionMeta) (*utils.TokenPair, error)
+	RefreshSession(ctx context.Context, refreshToken string, meta SessionMeta) (*utils.TokenPair, error)
+	ValidateAccessToken(ctx context.Context, claims *utils.Claims, token string) error
+	RevokeSession(ctx context.Context, sessionID uuid.UUID) error
+
+	// Session management for the logged-in user
+	ListSessions(ctx context.Context, userID uuid.UUID) ([]*entity.Session, error)
+	RevokeUserSession(ctx context.Context, userID, sessionID uuid.UUID) error
+	RevokeOtherSessions(ctx context.Context, userID, currentSessionID uuid.UUID) error
+}
+
+type sessionUseCase struct {
+	userRepo        repository.UserRepository
+	sessionRepo     repository.SessionRepository
+	accessTokenRepo repository.AccessTokenRepository
+	jwtManager      *utils.JWTManager
+	security        *securityLog
+	log             logger.Interface
+}
+
+func NewSessionUseCase(
+	userRepo repository.UserRepository,
+	sessionRepo repository.SessionRepository,
+	accessTokenRepo repository.AccessTokenRepository,
+	securityEventRepo repository.SecurityEventRepository,
+	jwtManager *utils.JWTManager,
+	log logger.Interface,
+) SessionUseCase {
+	return &sessionUseCase{
+		userRepo:        userRepo,
+		sessionRepo:     sessionRepo,
+		accessTokenRepo: accessTokenRepo,
+		jwtManager:      jwtManager,
+		security:        &securityLog{repo: securityEventRepo, log: log},
+		log:             log,
+	}
+}
+
+func (uc *sessionUseCase) CreateSession(ctx context.Context, user *entity.User, meta SessionMeta) (*utils.TokenPair, error) {
+	sessionToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate session token: %w", err)
+	}
+
+	now := time.Now()
+	session := &entity.Session{
+		ID:           uuid.New(),
+		UserID:       user.ID,
+		SessionToken: utils.HashToken(sessionToken),
+		UserAgent:    meta.UserAgent,
+		IPAddress:    meta.IPAddress,
+		ExpiredAt:    now.Add(uc.jwtManager.RefreshTokenDuration()),
+		LastSeenAt:   &now,
+	}
+
+	if err := uc.sessionRepo.Create(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return uc.issueTokens(ctx, session.ID, user)
+}
+
+// RefreshSession rotates a refresh token. Every refresh token is single use: presenting one that was
+// already rotated means it leaked, so the whole session (the token family) is revoked.
+func (uc *sessionUseCase) RefreshSession(ctx context.Context, refreshToken string, meta SessionMeta) (*utils.TokenPair, error) {
+	claims, err := uc.jwtManager.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		return nil, errors.New(401, "Invalid refresh token", err)
+	}
+
+	record, err := uc.findLiveToken(ctx, claims, refreshToken, consts.TokenTypeRefresh)
+	if err != nil {
+		return nil, err
+	}
+
+	rotated, err := uc.accessTokenRepo.MarkUsed(ctx, record.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if !rotated {
+		if err := uc.sessionRepo.Revoke(ctx, record.SessionID); err != nil {
+			return nil, fmt.Errorf("failed to revoke session: %w", err)
+		}
+
+		uc.security.record(ctx, &entity.SecurityEvent{
+			UserID:    &record.UserID,
+			SessionID: &record.SessionID,
+			EventType: consts.SecurityEventRefreshTokenReuse,
+			IPAddress: meta.IPAddress,
+			UserAgent: meta.UserAgent,
+			Details:   "rotated refresh token presented again, session revoked",
+		})
+
+		return nil, ErrTokenReused
+	}
+
+	// Reload the user so username and role changes are picked up on rotation
+	user, err := uc.userRepo.FindByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	uc.touch(ctx, claims.SessionID)
+
+	return uc.issueTokens(ctx, claims.SessionID, user)
+}
+
+func (uc *sessionUseCase) ValidateAccessToken(ctx context.Context, claims *utils.Claims, token string) error {
+	if _, err := uc.findLiveToken(ctx, claims, token, consts.TokenTypeAccess); err != nil {
+		return err
+	}
+
+	uc.touch(ctx, claims.SessionID)
+	return nil
+}
+
+func (uc *sessionUseCase) RevokeSession(ctx context.Context, sessionID uuid.UUID) error {
+	if err := uc.sessionRepo.Revoke(ctx, sessionID); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	return nil
+}
+
+func (uc *sessionUseCase) ListSessions(ctx context.Context, userID uuid.UUID) ([]*entity.Session, error) {
+	sessions, err := uc.sessionRepo.FindActiveByUserID(ctx, userID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// RevokeUserSession revokes one of the user's own sessions. Other users' sessions look like missing ones.
+func (uc *sessionUseCase) RevokeUserSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	revoked, err := uc.sessionRepo.RevokeForUser(ctx, sessionID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	if !revoked {
+		return errSessionNotFound
+	}
+
+	return nil
+}
+
+func (uc *sessionUseCase) RevokeOtherSessions(ctx context.Context, userID, currentSessionID uuid.UUID) error {
+	if err := uc.sessionRepo.RevokeAllByUserIDExcept(ctx, userID, currentSessionID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return nil
+}
+
+// touch updates last_seen_at. It is bookkeeping only, so a failure never fails the request.
+func (uc *sessionUseCase) touch(ctx context.Context, sessionID uuid.UUID) {
+	if err := uc.sessionRepo.Touch(ctx, sessionID, time.Now(), lastSeenInterval); err != nil {
+		uc.log.Error("failed to update session last seen: %v", err)
+	}
+}
+
+// findLiveToken makes sure the token was issued by this server and that its session is still usable
+func (uc *sessionUseCase) findLiveToken(ctx context.Context, claims *utils.Claims, token, tokenType string) (*entity.AccessToken, error) {
+	record, err := uc.accessTokenRepo.FindByHash(ctx, utils.HashToken(token))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find token: %w", err)
+	}
+	if record == nil || record.TokenType != tokenType || record.SessionID != claims.SessionID || record.UserID != claims.UserID {
+		return nil, ErrTokenNotIssued
+	}
+
+	if record.Session.IsRevoked {
+		return nil, ErrSessionRevoked
+	}
+	if time.Now().After(record.Session.ExpiredAt) {
+		return nil, ErrSessionExpired
+	}
+
+	return record, nil
+}
+
+// issueTokens mints a token pair for the session and records both tokens by hash
+func (uc *sessionUseCase) issueTokens(ctx context.Context, sessionID uuid.UUID, user *entity.User) (*utils.TokenPair, error) {
+	tokenPair, err := uc.jwtManager.GenerateToken(user.ID, user.Username, user.Role, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate tokens: %w", err)
+	}
+
+	tokens := []*entity.AccessToken{
+		{
+			SessionID: sessionID,
+			UserID:    user.ID,
+			TokenHash: utils.HashToken(tokenPair.AccessToken),
+			TokenType: consts.TokenTypeAccess,
+			ExpiredAt: tokenPair.AccessTokenExpiresAt,
+		},
+		{
+			SessionID: sessionID,
+			UserID:    user.ID,
+			TokenHash: utils.HashToken(tokenPair.RefreshToken),
+			TokenType: consts.TokenTypeRefresh,
+			ExpiredAt: tokenPair.RefreshTokenExpiresAt,
+		},
+	}
+
+	if err := uc.accessTokenRepo.CreateBatch(ctx, tokens); err != nil {
+		return nil, fmt.Errorf("failed to record tokens: %w", err)
+	}
+
+	return tokenPair, nil
+}
@@ -0,0 +1,244 @@
+package userusecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"fmt"
+	"strings"
+	"time"
+
+	"wallet_api/internal/common/errors"
+	"wallet_api/internal/entity"
+	"wallet_api/internal/module/user/repository"
+	"wallet_api/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+const (
+	twoFactorIssuer      = "wallet_api"
+	twoFactorTokenTTL    = 5 * time.Minute
+	recoveryCodeCount    = 10
+	maxTwoFactorAttempts = 5
+	twoFactorLockout     = 15 * time.Minute
+)
+
+var (
+	ErrInvalidTwoFactorCode    = errors.New(401, "Invalid two-factor code", nil)
+	ErrTwoFactorLocked         = errors.New(429, "Too many failed two-factor attempts, try again later", nil)
+	ErrTwoFactorAlreadyEnabled = errors.New(409, "Two-factor authentication is already enabled", nil)
+	ErrTwoFactorNotEnrolled    = errors.New(400, "Two-factor enrollment has not been started", nil)
+	ErrTwoFactorNotEnabled     = errors.New(400, "Two-factor authentication is not enabled", nil)
+)
+
+// TwoFactorEnrollment is what an authenticator app needs to start generating codes
+type TwoFactorEnrollment struct {
+	Secret string
+	URI    string
+}
+
+type TwoFactorUseCase interface {
+	Enroll(ctx context.Context, userID uuid.UUID) (*TwoFactorEnrollment, error)
+	Confirm(ctx context.Context, userID uuid.UUID, code string) ([]string, error)
+	Disable(ctx context.Context, userID uuid.UUID, password, code string) error
+}
+
+type twoFactorUseCase struct {
+	repo         repository.UserRepository
+	recoveryRepo repository.RecoveryCodeRepository
+	secondFactor *secondFactor
+}
+
+func NewTwoFactorUseCase(repo repository.UserRepository, recoveryRepo repository.RecoveryCodeRepository) TwoFactorUseCase {
+	return &twoFactorUseCase{
+		repo:         repo,
+		recoveryRepo: recoveryRepo,
+		secondFactor: &secondFactor{repo: repo, recoveryRepo: recoveryRepo},
+	}
+}
+
+// Enroll generates a new pending secret. 2FA is only switched on once Confirm sees a valid code.
+func (uc *twoFactorUseCase) Enroll(ctx context.Context, userID uuid.UUID) (*TwoFactorEnrollment, error) {
+	user, err := uc.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	if user.TwoFactorEnabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	if err := uc.repo.UpdateFields(ctx, userID, map[string]interface{}{"totp_secret": secret}); err != nil {
+		return nil, fmt.Errorf("failed to store secret: %w", err)
+	}
+
+	return &TwoFactorEnrollment{
+		Secret: secret,
+		URI:    utils.TOTPURI(twoFactorIssuer, user.Username, secret),
+	}, nil
+}
+
+// Confirm enables 2FA and returns the one-time recovery codes in plain text. They are never shown again.
+func (uc *twoFactorUseCase) Confirm(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	user, err := uc.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	if user.TwoFactorEnabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+
+	step, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate recovery codes: %w", err)
+	}
+
+	if err := uc.recoveryRepo.Replace(ctx, userID, hashes); err != nil {
+		return nil, fmt.Errorf("failed to store recovery codes: %w", err)
+	}
+
+	err = uc.repo.UpdateFields(ctx, userID, map[string]interface{}{
+		"totp_enabled_at":            time.Now(),
+		"totp_last_used_step":        step,
+		"two_factor_failed_attempts": 0,
+		"two_factor_locked_until":    nil,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+
+	return codes, nil
+}
+
+// Disable turns 2FA off. The caller has to prove both the password and a second factor again.
+func (uc *twoFactorUseCase) Disable(ctx context.Context, userID uuid.UUID, password, code string) error {
+	user, err := uc.repo.FindByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+	if !user.TwoFactorEnabled() {
+		return ErrTwoFactorNotEnabled
+	}
+
+	if err := utils.VerifyPassword(user.PasswordHash, password); err != nil {
+		return errors.New(401, "Invalid password", nil)
+	}
+
+	if err := uc.secondFactor.verify(ctx, user, code); err != nil {
+		return err
+	}
+
+	if err := uc.recoveryRepo.DeleteByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	err = uc.repo.UpdateFields(ctx, userID, map[string]interface{}{
+		"totp_secret":         "",
+		"totp_enabled_at":     nil,
+		"totp_last_used_step": 0,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to disable two-factor authentication: %w", err)
+	}
+
+	return nil
+}
+
+// secondFactor verifies TOTP and recovery codes for users that have 2FA enabled.
+// Consecutive failures lock the second step for a while so codes cannot be brute forced.
+type secondFactor struct {
+	repo         repository.UserRepository
+	recoveryRepo repository.RecoveryCodeRepository
+}
+
+func (f *secondFactor) verify(ctx context.Context, user *entity.User, code string) error {
+	now := time.Now()
+	if user.TwoFactorLockedUntil != nil && now.Before(*user.TwoFactorLockedUntil) {
+		return ErrTwoFactorLocked
+	}
+
+	ok, err := f.check(ctx, user, code, now)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		if _, err := f.repo.RecordTwoFactorFailure(ctx, user.ID, maxTwoFactorAttempts, now.Add(twoFactorLockout)); err != nil {
+			return fmt.Errorf("failed to record two-factor failure: %w", err)
+		}
+		return ErrInvalidTwoFactorCode
+	}
+
+	if user.TwoFactorFailedAttempts > 0 || user.TwoFactorLockedUntil != nil {
+		err := f.repo.UpdateFields(ctx, user.ID, map[string]interface{}{
+			"two_factor_failed_attempts": 0,
+			"two_factor_locked_until":    nil,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to reset two-factor failures: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// check accepts either a 6 digit TOTP code or one of the recovery codes
+func (f *secondFactor) check(ctx context.Context, user *entity.User, code string, now time.Time) (bool, error) {
+	code = strings.TrimSpace(code)
+
+	if step, ok := utils.ValidateTOTP(user.TOTPSecret, code, now); ok {
+		// A code is valid for a whole time step, only accept each step once
+		return f.repo.AdvanceTOTPStep(ctx, user.ID, step)
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	if normalized == "" {
+		return false, nil
+	}
+
+	consumed, err := f.recoveryRepo.Consume(ctx, user.ID, utils.HashToken(normalized))
+	if err != nil {
+		return false, fmt.Errorf("failed to consume recovery code: %w", err)
+	}
+	return consumed, nil
+}
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// generateRecoveryCodes returns codes formatted as xxxxx-xxxxx together with their hashes
+func generateRecoveryCodes(n int) ([]string, []string, error) {
+	codes := make([]string, n)
+	hashes := make([]string, n)
+
+	for i := range codes {
+		raw := make([]byte, 7)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(raw))[:10]
+		codes[i] = code[:5] + "-" + code[5:]
+		hashes[i] = utils.HashToken(code)
+	}
+
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
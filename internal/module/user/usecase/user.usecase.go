@@ -2,30 +2,59 @@ package userusecase
 
 import (
 	"context"
+	stdErrors "errors"
 	"fmt"
+	"time"
 
+	"wallet_api/internal/common/consts"
 	"wallet_api/internal/common/errors"
 	"wallet_api/internal/entity"
 	"wallet_api/internal/module/user/repository"
 	"wallet_api/internal/utils"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+// LoginResult is the outcome of the password step. When TwoFactorToken is set the user
+// still has to present a TOTP or recovery code to LoginTwoFactor before a session is opened.
+type LoginResult struct {
+	User           *entity.User
+	TwoFactorToken string
+}
+
 type UseCase interface {
 	Register(ctx context.Context, user *entity.User) error
-	Login(ctx context.Context, username, password string) (*entity.User, error)
+	Login(ctx context.Context, username, password, ipAddress string) (*LoginResult, error)
+	LoginTwoFactor(ctx context.Context, twoFactorToken, code string) (*entity.User, error)
 	GetProfile(ctx context.Context, userID uuid.UUID) (*entity.User, error)
 	UpdateProfile(ctx context.Context, user *entity.User) error
+	SearchUsers(ctx context.Context, query string, limit, offset int) ([]*entity.User, int64, error)
+	UnlockUser(ctx context.Context, userID uuid.UUID) error
 }
 
+// ErrInvalidCredentials is returned for a wrong password, an unknown user and a locked account alike
+var ErrInvalidCredentials = errors.New(401, "Invalid credentials", nil)
+
 type useCase struct {
-	repo repository.UserRepository
+	repo         repository.UserRepository
+	jwtManager   *utils.JWTManager
+	secondFactor *secondFactor
+	throttle     *loginThrottle
 }
 
-func New(repo repository.UserRepository) UseCase {
+func New(
+	repo repository.UserRepository,
+	recoveryRepo repository.RecoveryCodeRepository,
+	throttleRepo repository.LoginThrottleRepository,
+	throttleCfg LoginThrottleConfig,
+	jwtManager *utils.JWTManager,
+) UseCase {
 	return &useCase{
-		repo: repo,
+		repo:         repo,
+		jwtManager:   jwtManager,
+		secondFactor: &secondFactor{repo: repo, recoveryRepo: recoveryRepo},
+		throttle:     &loginThrottle{repo: throttleRepo, cfg: throttleCfg, now: time.Now},
 	}
 }
 
@@ -54,6 +83,7 @@ func (uc *useCase) Register(ctx context.Context, user *entity.User) error {
 		return fmt.Errorf("failed to hash password: %w", err)
 	}
 	user.PasswordHash = hashedPassword
+	user.Role = consts.RoleUser
 
 	// Create user
 	if err := uc.repo.Create(ctx, user); err != nil {
@@ -63,18 +93,70 @@ func (uc *useCase) Register(ctx context.Context, user *entity.User) error {
 	return nil
 }
 
-func (uc *useCase) Login(ctx context.Context, username, password string) (*entity.User, error) {
+func (uc *useCase) Login(ctx context.Context, username, password, ipAddress string) (*LoginResult, error) {
+	locked, err := uc.throttle.locked(ctx, username, ipAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check login throttle: %w", err)
+	}
+
 	user, err := uc.repo.FindByUsername(ctx, username)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find user: %w", err)
 	}
-	if user == nil {
-		return nil, errors.ErrBadRequest
+
+	// Always pay for one bcrypt comparison so timing does not reveal unknown or locked accounts
+	passwordOK := false
+	if user != nil {
+		passwordOK = utils.VerifyPassword(user.PasswordHash, password) == nil
+	} else {
+		utils.CompareDummyPassword(password)
+	}
+
+	// Locked accounts get exactly the same answer as bad credentials
+	if locked {
+		return nil, ErrInvalidCredentials
+	}
+
+	if !passwordOK {
+		if err := uc.throttle.fail(ctx, username, ipAddress); err != nil {
+			return nil, fmt.Errorf("failed to record login failure: %w", err)
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := uc.throttle.succeed(ctx, username); err != nil {
+		return nil, fmt.Errorf("failed to reset login throttle: %w", err)
+	}
+
+	if !user.TwoFactorEnabled() {
+		return &LoginResult{User: user}, nil
+	}
+
+	// Password is correct but a second factor is still required
+	token, err := uc.jwtManager.GenerateTwoFactorToken(user.ID, user.Username, twoFactorTokenTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate two-factor token: %w", err)
+	}
+
+	return &LoginResult{User: user, TwoFactorToken: token}, nil
+}
+
+func (uc *useCase) LoginTwoFactor(ctx context.Context, twoFactorToken, code string) (*entity.User, error) {
+	claims, err := uc.jwtManager.ValidateTwoFactorToken(twoFactorToken)
+	if err != nil {
+		return nil, errors.New(401, "Invalid two-factor token", err)
+	}
+
+	user, err := uc.repo.FindByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	if !user.TwoFactorEnabled() {
+		return nil, errors.New(401, "Invalid two-factor token", nil)
 	}
 
-	// Verify password
-	if err := utils.VerifyPassword(user.PasswordHash, password); err != nil {
-		return nil, errors.ErrUnauthorized
+	if err := uc.secondFactor.verify(ctx, user, code); err != nil {
+		return nil, err
 	}
 
 	return user, nil
@@ -122,12 +204,52 @@ func (uc *useCase) UpdateProfile(ctx context.Context, user *entity.User) error {
 		if emailTaken != nil && emailTaken.ID != user.ID {
 			return errors.New(409, "Email already taken", nil)
 		}
-	}
 
-	// Update user
-	if err := uc.repo.Update(ctx, user); err != nil {
+		// A new address only takes effect after it is verified
+		pendingEmail := user.Email
+		user.PendingEmail = &pendingEmail
+		user.Email = existing.Email
+	} else {
+		// Submitting the current address again cancels a pending change
+		user.PendingEmail = nil
+	}
+
+	// Only write the profile columns, saving the whole row would revert security state
+	// (lockouts, 2FA, PIN) changed since it was read
+	err = uc.repo.UpdateFields(ctx, user.ID, map[string]interface{}{
+		"username":      user.Username,
+		"email":         user.Email,
+		"pending_email": user.PendingEmail,
+	})
+	if err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
 	}
 
 	return nil
 }
+
+func (uc *useCase) SearchUsers(ctx context.Context, query string, limit, offset int) ([]*entity.User, int64, error) {
+	users, total, err := uc.repo.Search(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search users: %w", err)
+	}
+
+	return users, total, nil
+}
+
+// UnlockUser clears the failed login counter of a user, lifting any lockout
+func (uc *useCase) UnlockUser(ctx context.Context, userID uuid.UUID) error {
+	user, err := uc.repo.FindByID(ctx, userID)
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.ErrNotFound
+		}
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	if err := uc.throttle.unlock(ctx, user.Username); err != nil {
+		return fmt.Errorf("failed to unlock user: %w", err)
+	}
+
+	return nil
+}
@@ -1,27 +1,64 @@
 package user
 
 import (
+	"wallet_api/config"
 	"wallet_api/internal/module/user/handler"
 	"wallet_api/internal/module/user/repository"
 	userusecase "wallet_api/internal/module/user/usecase"
+	"wallet_api/internal/utils"
 	"wallet_api/pkg/logger"
+	"wallet_api/pkg/mailer"
 	"gorm.io/gorm"
 )
 
 type Module struct {
-	UseCase userusecase.UseCase
-	Handler *handler.Handler
+	UseCase                  userusecase.UseCase
+	SessionUseCase           userusecase.SessionUseCase
+	EmailVerificationUseCase userusecase.EmailVerificationUseCase
+	PINUseCase               userusecase.PINUseCase
+	Handler                  *handler.Handler
 }
 
-func NewModule(db *gorm.DB, log logger.Interface) *Module {
+func NewModule(db *gorm.DB, log logger.Interface, cfg *config.Config, jwtManager *utils.JWTManager, mail mailer.Interface) *Module {
 	repo := repository.New(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	accessTokenRepo := repository.NewAccessTokenRepository(db)
+	securityEventRepo := repository.NewSecurityEventRepository(db)
+	recoveryCodeRepo := repository.NewRecoveryCodeRepository(db)
+	loginThrottleRepo := repository.NewLoginThrottleRepository(db)
+	passwordResetRepo := repository.NewPasswordResetTokenRepository(db)
+	emailVerificationRepo := repository.NewEmailVerificationTokenRepository(db)
 
-	uc := userusecase.New(repo)
+	throttleCfg := userusecase.LoginThrottleConfig{
+		MaxFailures:     cfg.LoginThrottle.MaxFailures,
+		MaxIPFailures:   cfg.LoginThrottle.MaxIPFailures,
+		Window:          cfg.LoginThrottle.Window,
+		LockoutDuration: cfg.LoginThrottle.LockoutDuration,
+		DelayAfter:      cfg.LoginThrottle.DelayAfter,
+		BaseDelay:       cfg.LoginThrottle.BaseDelay,
+	}
+
+	uc := userusecase.New(repo, recoveryCodeRepo, loginThrottleRepo, throttleCfg, jwtManager)
+	sessionUC := userusecase.NewSessionUseCase(repo, sessionRepo, accessTokenRepo, securityEventRepo, jwtManager, log)
+	twoFactorUC := userusecase.NewTwoFactorUseCase(repo, recoveryCodeRepo)
+	passwordUC := userusecase.NewPasswordUseCase(repo, passwordResetRepo, sessionRepo, securityEventRepo, mail, userusecase.PasswordResetConfig{
+		URL:      cfg.PasswordReset.URL,
+		TokenTTL: cfg.PasswordReset.TokenTTL,
+	}, log)
+	verificationUC := userusecase.NewEmailVerificationUseCase(repo, emailVerificationRepo, securityEventRepo, mail, userusecase.EmailVerificationConfig{
+		URL:      cfg.EmailVerification.URL,
+		TokenTTL: cfg.EmailVerification.TokenTTL,
+	}, log)
+
+	pinUC := userusecase.NewPINUseCase(repo, securityEventRepo, jwtManager, cfg.StepUp.TokenTTL, log)
 
-	h := handler.New(uc, log)
+	h := handler.New(uc, sessionUC, twoFactorUC, passwordUC, verificationUC, pinUC, log)
 
 	return &Module{
-		UseCase: uc,
-		Handler: h,
+		UseCase:                  uc,
+		SessionUseCase:           sessionUC,
+		EmailVerificationUseCase: verificationUC,
+		PINUseCase:               pinUC,
+		Handler:                  h,
 	}
 }
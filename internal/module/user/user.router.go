@@ -1,23 +1,46 @@
 package user
 
 import (
+	"wallet_api/internal/common/consts"
 	"wallet_api/internal/middleware"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func (m *Module) RegisterRoutes(app *fiber.App) {
-	auth := app.Group("/v1/auth")
+func (m *Module) RegisterRoutes(app *fiber.App, auth fiber.Handler) {
+	authRoutes := app.Group("/v1/auth")
 	{
-		auth.Post("/register", m.Handler.Register)
-		auth.Post("/login", m.Handler.Login)
-		auth.Post("/logout", middleware.JWTAuth(), m.Handler.Logout)
-		auth.Post("/refresh", m.Handler.RefreshToken)
+		authRoutes.Post("/register", m.Handler.Register)
+		authRoutes.Post("/login", m.Handler.Login)
+		authRoutes.Post("/login/2fa", m.Handler.LoginTwoFactor)
+		authRoutes.Post("/logout", auth, m.Handler.Logout)
+		authRoutes.Post("/refresh", middleware.CSRF(), m.Handler.RefreshToken)
+		authRoutes.Post("/token/refresh", m.Handler.RefreshTokenBody)
+		authRoutes.Post("/password/forgot", m.Handler.ForgotPassword)
+		authRoutes.Post("/password/reset", m.Handler.ResetPassword)
+		authRoutes.Post("/email/verify", m.Handler.VerifyEmail)
 	}
 
-	users := app.Group("/v1/users", middleware.JWTAuth())
+	users := app.Group("/v1/users", auth)
 	{
 		users.Get("/profile", m.Handler.GetProfile)
 		users.Put("/profile", m.Handler.UpdateProfile)
+		users.Put("/password", m.Handler.ChangePassword)
+		users.Put("/pin", m.Handler.SetPIN)
+		users.Post("/step-up", m.Handler.StepUp)
+		users.Get("/sessions", m.Handler.ListSessions)
+		users.Delete("/sessions", m.Handler.RevokeOtherSessions)
+		users.Delete("/sessions/:id", m.Handler.RevokeSession)
+		users.Post("/email/verification", m.Handler.ResendVerification)
+		users.Post("/2fa/enroll", m.Handler.EnrollTwoFactor)
+		users.Post("/2fa/confirm", m.Handler.ConfirmTwoFactor)
+		users.Post("/2fa/disable", m.Handler.DisableTwoFactor)
+	}
+
+	admin := app.Group("/v1/admin/users", auth, middleware.RequireRole(consts.RoleAdmin))
+	{
+		admin.Get("/", m.Handler.ListUsers)
+		admin.Get("/:id", m.Handler.GetUser)
+		admin.Post("/:id/unlock", m.Handler.UnlockUser)
 	}
 }
@@ -1,32 +1,80 @@
 package router
 
 import (
+	"wallet_api/config"
+	"wallet_api/internal/middleware"
 	"wallet_api/internal/module/account"
+	"wallet_api/internal/module/apikey"
+	"wallet_api/internal/module/currency"
+	"wallet_api/internal/module/fx"
+	"wallet_api/internal/module/schedule"
 	"wallet_api/internal/module/user"
+	"wallet_api/internal/utils"
 	"wallet_api/pkg/logger"
+	"wallet_api/pkg/mailer"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
 
 type Module struct {
-	User    *user.Module
-	Account *account.Module
+	User     *user.Module
+	Account  *account.Module
+	APIKey   *apikey.Module
+	Schedule *schedule.Module
+	FX       *fx.Module
+	Currency *currency.Module
+
+	JWTManager *utils.JWTManager
 }
 
-func NewModule(db *gorm.DB, log logger.Interface) *Module {
+func NewModule(db *gorm.DB, log logger.Interface, cfg *config.Config, jwtManager *utils.JWTManager, mail mailer.Interface) *Module {
 	// Initialize User Module
-	userModule := user.NewModule(db, log)
+	userModule := user.NewModule(db, log, cfg, jwtManager, mail)
+
+	// Initialize Currency Module, the registry every wallet currency and amount is checked against
+	currencyModule := currency.NewModule(db, log)
+
+	// Initialize Account Module, withdraw and transfer need a verified email and,
+	// for large amounts, a PIN check from the user module
+	accountModule := account.NewModule(db, log, cfg, userModule.EmailVerificationUseCase, userModule.PINUseCase, currencyModule.UseCase)
+
+	// Initialize API Key Module, it checks wallet ownership through the account module
+	apiKeyModule := apikey.NewModule(db, log, accountModule.UseCase)
 
-	// Initialize Account Module
-	accountModule := account.NewModule(db, log)
+	// Initialize Schedule Module, every run is a transfer through the account module
+	scheduleModule := schedule.NewModule(db, log, cfg, accountModule.UseCase)
+
+	// Initialize FX Module, conversions are posted by the account module
+	fxModule := fx.NewModule(db, log, cfg, accountModule.UseCase, currencyModule.UseCase)
 
 	return &Module{
-		User:    userModule,
-		Account: accountModule,
+		User:     userModule,
+		Account:  accountModule,
+		APIKey:   apiKeyModule,
+		Schedule: scheduleModule,
+		FX:       fxModule,
+		Currency: currencyModule,
+
+		JWTManager: jwtManager,
 	}
 }
 
 func (m *Module) RegisterRoutes(app *fiber.App) {
-	m.User.RegisterRoutes(app)
-	m.Account.RegisterRoutes(app)
+	// Every module shares one session-aware auth middleware
+	auth := middleware.JWTAuth(m.JWTManager, m.User.SessionUseCase)
+	// Wallet routes can also be called by backend jobs with an API key
+	walletAuth := middleware.JWTOrAPIKeyAuth(auth, middleware.APIKeyAuth(m.APIKey.UseCase))
+
+	m.User.RegisterRoutes(app, auth)
+	m.Account.RegisterRoutes(app, auth, walletAuth)
+	m.APIKey.RegisterRoutes(app, auth)
+	m.Schedule.RegisterRoutes(app, auth)
+	m.FX.RegisterRoutes(app, auth, walletAuth, m.Account.Idempotency)
+	m.Currency.RegisterRoutes(app, auth)
+
+	// Public keys so other services can verify our tokens without a shared secret
+	app.Get("/.well-known/jwks.json", func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+		return c.JSON(m.JWTManager.JWKS())
+	})
 }
@@ -2,9 +2,11 @@ package router
 
 import (
 	"net/http"
+	"strings"
 
 	"wallet_api/config"
 	"wallet_api/internal/middleware"
+	"wallet_api/internal/utils"
 	"wallet_api/pkg/logger"
 
 	"github.com/gofiber/fiber/v2"
@@ -21,7 +23,25 @@ func NewRouter(
 	// Global middleware
 	app.Use(middleware.Logger(l))
 	app.Use(middleware.Recovery(l))
-	app.Use(cors.New())
+	app.Use(cors.New(cors.Config{
+		// Credentials are only sent to the listed frontends, never to "*"
+		AllowOrigins:     strings.Join(cfg.CORS.AllowOrigins, ","),
+		AllowCredentials: true,
+		AllowHeaders: strings.Join([]string{
+			fiber.HeaderOrigin,
+			fiber.HeaderContentType,
+			fiber.HeaderAccept,
+			fiber.HeaderAuthorization,
+			utils.CSRFHeader,
+			middleware.APIKeyHeader,
+			middleware.IdempotencyKeyHeader,
+			"X-Client-Type",
+		}, ","),
+		ExposeHeaders: strings.Join([]string{
+			utils.CSRFHeader,
+			middleware.IdempotentReplayedHeader,
+		}, ","),
+	}))
 	app.Use(recover.New())
 
 	// Health check endpoint (Kubernetes standard)
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// How the access token reached the server
+	AuthMethodBearer = "bearer"
+	AuthMethodCookie = "cookie"
+)
+
+// GetAccessToken ambil access token dari request.
+// Authorization header selalu menang: kalau header ada, cookie tidak dipakai sama sekali,
+// jadi header yang salah format tidak diam-diam jatuh ke cookie.
+func GetAccessToken(c *fiber.Ctx) (token string, method string) {
+	if header := c.Get(fiber.HeaderAuthorization); header != "" {
+		return GetBearerToken(header), AuthMethodBearer
+	}
+
+	return GetAccessTokenFromCookie(c), AuthMethodCookie
+}
+
+// GetBearerToken returns the token from an "Authorization: Bearer <token>" header value
+func GetBearerToken(header string) string {
+	scheme, token, found := strings.Cut(strings.TrimSpace(header), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
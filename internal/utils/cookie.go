@@ -90,6 +90,16 @@ func ClearAuthCookies(c *fiber.Ctx) {
 		SameSite: "Strict",
 		Path:     "/",
 	})
+
+	// Clear CSRF cookie
+	SetCookie(c, CookieConfig{
+		Name:     CSRFCookie,
+		Value:    "",
+		MaxAge:   -1, // Expire immediately
+		Secure:   true,
+		SameSite: "Strict",
+		Path:     "/",
+	})
 }
 
 func GetAccessTokenFromCookie(c *fiber.Ctx) string {
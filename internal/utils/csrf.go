@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"crypto/subtle"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// CSRFCookie is readable by the frontend, which echoes it in CSRFHeader (double-submit)
+	CSRFCookie = "csrf_token"
+	CSRFHeader = "X-CSRF-Token"
+
+	csrfTokenBytes = 32
+	csrfCookieAge  = 7 * 24 * time.Hour // same as the refresh token cookie
+)
+
+// IssueCSRFToken sets a fresh CSRF cookie and also returns the token in the CSRFHeader
+// response header, for frontends on another origin that cannot read the cookie
+func IssueCSRFToken(c *fiber.Ctx, isProduction bool) error {
+	token, err := GenerateRandomToken(csrfTokenBytes)
+	if err != nil {
+		return err
+	}
+
+	sameSite := "Lax"
+	if isProduction {
+		sameSite = "Strict"
+	}
+
+	SetCookie(c, CookieConfig{
+		Name:     CSRFCookie,
+		Value:    token,
+		MaxAge:   csrfCookieAge,
+		HTTPOnly: false, // must be readable by JavaScript
+		Secure:   isProduction,
+		SameSite: sameSite,
+		Path:     "/",
+	})
+	c.Set(CSRFHeader, token)
+
+	return nil
+}
+
+// ValidCSRFToken checks that the header matches the cookie. Both must be present.
+func ValidCSRFToken(c *fiber.Ctx) bool {
+	cookie := c.Cookies(CSRFCookie)
+	header := c.Get(CSRFHeader)
+	if cookie == "" || header == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) == 1
+}
@@ -2,11 +2,10 @@ package utils
 
 import (
 	"errors"
-	"log"
-	"os"
-	"strconv"
 	"time"
 
+	"wallet_api/internal/common/consts"
+
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
@@ -23,8 +22,11 @@ var (
 )
 
 type Claims struct {
-	UserID   uuid.UUID `json:"user_id"`
-	Username string    `json:"username"`
+	UserID    uuid.UUID `json:"user_id"`
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	SessionID uuid.UUID `json:"sid"`
+	TokenType string    `json:"typ"`
 	jwt.RegisteredClaims
 }
 
@@ -32,44 +34,57 @@ type TokenPair struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
 	ExpiresIn    int64  `json:"expires_in"` // access token expiry in seconds
+
+	AccessTokenExpiresAt  time.Time `json:"-"`
+	RefreshTokenExpiresAt time.Time `json:"-"`
 }
 
 type JWTManager struct {
-	secretKey            []byte
+	keys                 *KeySet
 	accessTokenDuration  time.Duration
 	refreshTokenDuration time.Duration
 }
 
-// NewJWTManager creates JWT manager with configuration from environment variables
-func NewJWTManager(secretKey string) *JWTManager {
-	// Baca konfigurasi dari environment variables
-	accessTokenExpiry := getEnvAsInt("ACCESS_TOKEN_EXPIRY", 15)  // default 15 menit
-	refreshTokenExpiry := getEnvAsInt("REFRESH_TOKEN_EXPIRY", 7)  // default 7 hari
-
+// NewJWTManager creates a JWT manager that signs with the active key of the set
+// and verifies with any key still in it
+func NewJWTManager(keys *KeySet, accessTokenDuration, refreshTokenDuration time.Duration) *JWTManager {
 	return &JWTManager{
-		secretKey:            []byte(secretKey),
-		accessTokenDuration:  time.Duration(accessTokenExpiry) * time.Minute,
-		refreshTokenDuration: time.Duration(refreshTokenExpiry) * 24 * time.Hour,
+		keys:                 keys,
+		accessTokenDuration:  accessTokenDuration,
+		refreshTokenDuration: refreshTokenDuration,
 	}
 }
 
-// getEnvAsInt reads environment variable as integer with default value
-func getEnvAsInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intVal, err := strconv.Atoi(value); err == nil {
-			return intVal
-		}
-	}
-	return defaultValue
+// JWKS returns the public keys for /.well-known/jwks.json
+func (j *JWTManager) JWKS() JWKSet {
+	return j.keys.JWKS()
 }
 
-func (j *JWTManager) GenerateToken(userID uuid.UUID, username string) (*TokenPair, error) {
+// sign signs the claims with the active key and records its kid in the header
+func (j *JWTManager) sign(claims *Claims) (string, error) {
+	key := j.keys.Active()
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.PrivateKey)
+}
+
+// RefreshTokenDuration returns how long a refresh token (and therefore a session) stays valid
+func (j *JWTManager) RefreshTokenDuration() time.Duration {
+	return j.refreshTokenDuration
+}
+
+func (j *JWTManager) GenerateToken(userID uuid.UUID, username, role string, sessionID uuid.UUID) (*TokenPair, error) {
 	// Generate Access Token
 	accessTokenExpiry := time.Now().Add(j.accessTokenDuration)
 	accessClaims := &Claims{
-		UserID:   userID,
-		Username: username,
+		UserID:    userID,
+		Username:  username,
+		Role:      role,
+		SessionID: sessionID,
+		TokenType: consts.TokenTypeAccess,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			ExpiresAt: jwt.NewNumericDate(accessTokenExpiry),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
@@ -78,8 +93,7 @@ func (j *JWTManager) GenerateToken(userID uuid.UUID, username string) (*TokenPai
 		},
 	}
 
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-	accessTokenString, err := accessToken.SignedString(j.secretKey)
+	accessTokenString, err := j.sign(accessClaims)
 	if err != nil {
 		return nil, err
 	}
@@ -87,9 +101,13 @@ func (j *JWTManager) GenerateToken(userID uuid.UUID, username string) (*TokenPai
 	// Generate Refresh Token
 	refreshTokenExpiry := time.Now().Add(j.refreshTokenDuration)
 	refreshClaims := &Claims{
-		UserID:   userID,
-		Username: username,
+		UserID:    userID,
+		Username:  username,
+		Role:      role,
+		SessionID: sessionID,
+		TokenType: consts.TokenTypeRefresh,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			ExpiresAt: jwt.NewNumericDate(refreshTokenExpiry),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
@@ -98,27 +116,30 @@ func (j *JWTManager) GenerateToken(userID uuid.UUID, username string) (*TokenPai
 		},
 	}
 
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-	refreshTokenString, err := refreshToken.SignedString(j.secretKey)
+	refreshTokenString, err := j.sign(refreshClaims)
 	if err != nil {
 		return nil, err
 	}
 
 	return &TokenPair{
-		AccessToken:  accessTokenString,
-		RefreshToken: refreshTokenString,
-		ExpiresIn:    int64(j.accessTokenDuration.Seconds()),
+		AccessToken:           accessTokenString,
+		RefreshToken:          refreshTokenString,
+		ExpiresIn:             int64(j.accessTokenDuration.Seconds()),
+		AccessTokenExpiresAt:  accessTokenExpiry,
+		RefreshTokenExpiresAt: refreshTokenExpiry,
 	}, nil
 }
 
 func (j *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Validasi signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		// Cari key berdasarkan kid, lalu pastikan algoritmanya sesuai dengan key tersebut
+		kid, _ := token.Header["kid"].(string)
+		key, ok := j.keys.Lookup(kid)
+		if !ok || token.Method.Alg() != key.Method.Alg() {
 			return nil, ErrInvalidToken
 		}
-		return j.secretKey, nil
-	})
+		return key.PublicKey, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}))
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenMalformed) {
@@ -139,37 +160,71 @@ func (j *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 	return claims, nil
 }
 
-func (j *JWTManager) RefreshAccessToken(refreshTokenString string) (string, error) {
-	// Validate refresh token
-	claims, err := j.ValidateToken(refreshTokenString)
-	if err != nil {
-		return "", err
+// GenerateTwoFactorToken issues the intermediate token that is exchanged for a
+// TokenPair once the second factor has been verified
+func (j *JWTManager) GenerateTwoFactorToken(userID uuid.UUID, username string, ttl time.Duration) (string, error) {
+	claims := &Claims{
+		UserID:    userID,
+		Username:  username,
+		TokenType: consts.TokenTypeTwoFactor,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			Issuer:    "wallet_api",
+			Subject:   userID.String(),
+		},
 	}
 
-	// Generate new access token dengan claims yang sama
-	newAccessTokenExpiry := time.Now().Add(j.accessTokenDuration)
-	newAccessClaims := &Claims{
-		UserID:   claims.UserID,
-		Username: claims.Username,
+	return j.sign(claims)
+}
+
+// GenerateStepUpToken issues a token proving the user passed a PIN check a moment ago
+func (j *JWTManager) GenerateStepUpToken(userID uuid.UUID, ttl time.Duration) (string, error) {
+	claims := &Claims{
+		UserID:    userID,
+		TokenType: consts.TokenTypeStepUp,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(newAccessTokenExpiry),
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			Issuer:    "wallet_api",
-			Subject:   claims.UserID.String(),
+			Subject:   userID.String(),
 		},
 	}
 
-	newAccessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, newAccessClaims)
-	return newAccessToken.SignedString(j.secretKey)
+	return j.sign(claims)
+}
+
+// ValidateStepUpToken validates a token and rejects anything that is not a step-up token
+func (j *JWTManager) ValidateStepUpToken(tokenString string) (*Claims, error) {
+	return j.validateTokenType(tokenString, consts.TokenTypeStepUp)
+}
+
+// ValidateTwoFactorToken validates a token and rejects anything that is not a two-factor token
+func (j *JWTManager) ValidateTwoFactorToken(tokenString string) (*Claims, error) {
+	return j.validateTokenType(tokenString, consts.TokenTypeTwoFactor)
+}
+
+// ValidateAccessToken validates a token and rejects anything that is not an access token
+func (j *JWTManager) ValidateAccessToken(tokenString string) (*Claims, error) {
+	return j.validateTokenType(tokenString, consts.TokenTypeAccess)
+}
+
+// ValidateRefreshToken validates a token and rejects anything that is not a refresh token
+func (j *JWTManager) ValidateRefreshToken(tokenString string) (*Claims, error) {
+	return j.validateTokenType(tokenString, consts.TokenTypeRefresh)
 }
 
-// GetSecretKey returns JWT secret from environment variable
-func GetSecretKey() string {
-	if secret := os.Getenv("JWT_SECRET"); secret != "" {
-		return secret
+func (j *JWTManager) validateTokenType(tokenString, tokenType string) (*Claims, error) {
+	claims, err := j.ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != tokenType {
+		return nil, ErrInvalidToken
 	}
-	// Fallback ke default untuk development dengan WARNING
-	log.Println("⚠️  WARNING: Menggunakan default JWT secret! Set JWT_SECRET environment variable untuk production!")
-	return "your-secret-key-change-this-in-production"
+	return claims, nil
 }
@@ -0,0 +1,212 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	privateKeySuffix = ".pem"
+	publicKeySuffix  = ".pub.pem"
+	minRSAKeyBits    = 2048
+)
+
+// ErrNoSigningKey is returned when the active key id has no private key to sign with
+var ErrNoSigningKey = errors.New("no JWT signing key configured")
+
+// SigningKey is one JWT key identified by its kid. Retired keys only keep the public half.
+type SigningKey struct {
+	ID         string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+}
+
+// KeySet holds every key that may still verify tokens and the one currently used to sign
+type KeySet struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+// NewKeySet builds a key set from already parsed keys. activeKeyID must have a private key.
+func NewKeySet(activeKeyID string, keys ...*SigningKey) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]*SigningKey, len(keys))}
+	for _, key := range keys {
+		if _, exists := ks.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate JWT key id %q", key.ID)
+		}
+		ks.keys[key.ID] = key
+	}
+
+	active, ok := ks.keys[activeKeyID]
+	if !ok || active.PrivateKey == nil {
+		return nil, fmt.Errorf("%w: active key %q not found", ErrNoSigningKey, activeKeyID)
+	}
+	ks.active = active
+
+	return ks, nil
+}
+
+// LoadKeySet reads keys from dir. "<kid>.pem" files hold PKCS#8 private keys (RSA or Ed25519),
+// "<kid>.pub.pem" files hold PKIX public keys of retired keys that may still verify tokens.
+// Rotasi: tambah key baru, ganti activeKeyID, hapus key lama setelah refresh token terakhirnya expired.
+func LoadKeySet(dir, activeKeyID string) (*KeySet, error) {
+	if dir == "" || activeKeyID == "" {
+		return nil, ErrNoSigningKey
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT key dir: %w", err)
+	}
+
+	var keys []*SigningKey
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, privateKeySuffix) {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWT key %s: %w", name, err)
+		}
+
+		var key *SigningKey
+		if strings.HasSuffix(name, publicKeySuffix) {
+			key, err = ParsePublicKeyPEM(strings.TrimSuffix(name, publicKeySuffix), data)
+		} else {
+			key, err = ParsePrivateKeyPEM(strings.TrimSuffix(name, privateKeySuffix), data)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT key %s: %w", name, err)
+		}
+		keys = append(keys, key)
+	}
+
+	return NewKeySet(activeKeyID, keys...)
+}
+
+// ParsePrivateKeyPEM parses a PKCS#8 RSA or Ed25519 private key
+func ParsePrivateKeyPEM(kid string, data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", parsed)
+	}
+
+	key, err := newSigningKey(kid, signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	key.PrivateKey = signer
+
+	return key, nil
+}
+
+// ParsePublicKeyPEM parses a PKIX RSA or Ed25519 public key
+func ParsePublicKeyPEM(kid string, data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSigningKey(kid, parsed)
+}
+
+func newSigningKey(kid string, public crypto.PublicKey) (*SigningKey, error) {
+	if kid == "" {
+		return nil, errors.New("key id is empty")
+	}
+
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA key must be at least %d bits", minRSAKeyBits)
+		}
+		return &SigningKey{ID: kid, Method: jwt.SigningMethodRS256, PublicKey: pub}, nil
+	case ed25519.PublicKey:
+		return &SigningKey{ID: kid, Method: jwt.SigningMethodEdDSA, PublicKey: pub}, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", public)
+	}
+}
+
+// Active returns the key new tokens are signed with
+func (ks *KeySet) Active() *SigningKey {
+	return ks.active
+}
+
+// Lookup returns the key for a kid
+func (ks *KeySet) Lookup(kid string) (*SigningKey, bool) {
+	key, ok := ks.keys[kid]
+	return key, ok
+}
+
+// JWK is a public key in RFC 7517 form
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public half of every key so other services can verify our tokens
+func (ks *KeySet) JWKS() JWKSet {
+	set := JWKSet{Keys: make([]JWK, 0, len(ks.keys))}
+	for _, key := range ks.keys {
+		jwk := JWK{KeyID: key.ID, Use: "sig", Algorithm: key.Method.Alg()}
+
+		switch pub := key.PublicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	// Stable order keeps the response cacheable
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].KeyID < set.Keys[j].KeyID })
+	return set
+}
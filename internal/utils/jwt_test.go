@@ -0,0 +1,209 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func newEd25519Key(t *testing.T, kid string) *SigningKey {
+	t.Helper()
+
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	key, err := newSigningKey(kid, priv.Public())
+	if err != nil {
+		t.Fatalf("newSigningKey() error = %v", err)
+	}
+	key.PrivateKey = priv
+	return key
+}
+
+func newRSAKey(t *testing.T, kid string) *SigningKey {
+	t.Helper()
+
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	key, err := newSigningKey(kid, priv.Public())
+	if err != nil {
+		t.Fatalf("newSigningKey() error = %v", err)
+	}
+	key.PrivateKey = priv
+	return key
+}
+
+func newManager(t *testing.T, active string, keys ...*SigningKey) *JWTManager {
+	t.Helper()
+
+	ks, err := NewKeySet(active, keys...)
+	if err != nil {
+		t.Fatalf("NewKeySet() error = %v", err)
+	}
+	return NewJWTManager(ks, 15*time.Minute, 24*time.Hour)
+}
+
+func TestJWTManagerSignsAndValidates(t *testing.T) {
+	for _, key := range []*SigningKey{newEd25519Key(t, "ed"), newRSAKey(t, "rsa")} {
+		t.Run(key.Method.Alg(), func(t *testing.T) {
+			manager := newManager(t, key.ID, key)
+			userID := uuid.New()
+
+			pair, err := manager.GenerateToken(userID, "alice", "user", uuid.New())
+			if err != nil {
+				t.Fatalf("GenerateToken() error = %v", err)
+			}
+
+			claims, err := manager.ValidateAccessToken(pair.AccessToken)
+			if err != nil {
+				t.Fatalf("ValidateAccessToken() error = %v", err)
+			}
+			if claims.UserID != userID {
+				t.Errorf("UserID = %v, want %v", claims.UserID, userID)
+			}
+
+			if _, err := manager.ValidateAccessToken(pair.RefreshToken); err == nil {
+				t.Error("refresh token must not pass as an access token")
+			}
+		})
+	}
+}
+
+func TestJWTManagerStepUpToken(t *testing.T) {
+	manager := newManager(t, "ed", newEd25519Key(t, "ed"))
+	userID := uuid.New()
+
+	token, err := manager.GenerateStepUpToken(userID, time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateStepUpToken() error = %v", err)
+	}
+
+	claims, err := manager.ValidateStepUpToken(token)
+	if err != nil {
+		t.Fatalf("ValidateStepUpToken() error = %v", err)
+	}
+	if claims.UserID != userID {
+		t.Errorf("UserID = %v, want %v", claims.UserID, userID)
+	}
+
+	if _, err := manager.ValidateAccessToken(token); err == nil {
+		t.Error("step-up token must not pass as an access token")
+	}
+
+	pair, err := manager.GenerateToken(userID, "alice", "user", uuid.New())
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	if _, err := manager.ValidateStepUpToken(pair.AccessToken); err == nil {
+		t.Error("access token must not pass as a step-up token")
+	}
+}
+
+func TestJWTManagerKeyRotation(t *testing.T) {
+	oldKey := newEd25519Key(t, "2026-01")
+	newKey := newEd25519Key(t, "2026-02")
+
+	before := newManager(t, oldKey.ID, oldKey)
+	pair, err := before.GenerateToken(uuid.New(), "alice", "user", uuid.New())
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+
+	// The old key is retired: only its public half is kept for verification
+	retired := &SigningKey{ID: oldKey.ID, Method: oldKey.Method, PublicKey: oldKey.PublicKey}
+	after := newManager(t, newKey.ID, newKey, retired)
+
+	if _, err := after.ValidateAccessToken(pair.AccessToken); err != nil {
+		t.Errorf("token signed before rotation should still validate: %v", err)
+	}
+
+	rotated, err := after.GenerateToken(uuid.New(), "bob", "user", uuid.New())
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	if _, err := before.ValidateAccessToken(rotated.AccessToken); err == nil {
+		t.Error("a verifier without the new key must reject its tokens")
+	}
+
+	jwks := after.JWKS()
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("JWKS has %d keys, want 2", len(jwks.Keys))
+	}
+	if jwks.Keys[0].KeyID != "2026-01" || jwks.Keys[0].Curve != "Ed25519" || jwks.Keys[0].X == "" {
+		t.Errorf("unexpected JWK %+v", jwks.Keys[0])
+	}
+}
+
+func TestJWTManagerRejectsUnknownKey(t *testing.T) {
+	manager := newManager(t, "a", newEd25519Key(t, "a"))
+	other := newManager(t, "a", newEd25519Key(t, "a"))
+
+	pair, err := other.GenerateToken(uuid.New(), "mallory", "admin", uuid.New())
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+
+	// Same kid, different key material
+	if _, err := manager.ValidateAccessToken(pair.AccessToken); err == nil {
+		t.Error("token signed by a foreign key must be rejected")
+	}
+}
+
+func TestLoadKeySet(t *testing.T) {
+	dir := t.TempDir()
+
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey() error = %v", err)
+	}
+	writePEM(t, filepath.Join(dir, "current.pem"), "PRIVATE KEY", der)
+
+	_, retiredPriv, _ := ed25519.GenerateKey(rand.Reader)
+	pubDER, err := x509.MarshalPKIXPublicKey(retiredPriv.Public())
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey() error = %v", err)
+	}
+	writePEM(t, filepath.Join(dir, "retired.pub.pem"), "PUBLIC KEY", pubDER)
+
+	ks, err := LoadKeySet(dir, "current")
+	if err != nil {
+		t.Fatalf("LoadKeySet() error = %v", err)
+	}
+	if ks.Active().ID != "current" {
+		t.Errorf("active key = %q, want current", ks.Active().ID)
+	}
+	if _, ok := ks.Lookup("retired"); !ok {
+		t.Error("retired public key was not loaded")
+	}
+
+	if _, err := LoadKeySet(dir, "retired"); err == nil {
+		t.Error("a public-only key must not become the signing key")
+	}
+	if _, err := LoadKeySet("", ""); err == nil {
+		t.Error("missing configuration must fail")
+	}
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"sync"
+
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -17,3 +19,17 @@ func HashPassword(password string) (string, error) {
 func VerifyPassword(hashedPassword, plainPassword string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(plainPassword))
 }
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+// CompareDummyPassword costs as much as VerifyPassword against a real hash,
+// so a login for an unknown user cannot be spotted by its response time
+func CompareDummyPassword(plainPassword string) {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcryptCost)
+	})
+	_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(plainPassword))
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// Test vectors from RFC 6238 Appendix B (SHA1 variant)
+func TestHOTPMatchesRFC6238Vectors(t *testing.T) {
+	key := []byte("12345678901234567890")
+
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+
+	for _, tt := range tests {
+		step := TOTPStep(time.Unix(tt.unix, 0))
+		if got := hotp(key, uint64(step), 8); got != tt.want {
+			t.Errorf("hotp at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret := totpEncoding.EncodeToString([]byte("12345678901234567890"))
+	now := time.Unix(1111111109, 0)
+
+	// The 6 digit code is the last 6 digits of the RFC vector
+	step, ok := ValidateTOTP(secret, "081804", now)
+	if !ok {
+		t.Fatal("expected current code to be accepted")
+	}
+	if step != TOTPStep(now) {
+		t.Errorf("matched step = %d, want %d", step, TOTPStep(now))
+	}
+
+	if _, ok := ValidateTOTP(secret, "081804", now.Add(TOTPPeriod)); !ok {
+		t.Error("expected code from the previous step to be accepted")
+	}
+
+	if _, ok := ValidateTOTP(secret, "081804", now.Add(3*TOTPPeriod)); ok {
+		t.Error("expected stale code to be rejected")
+	}
+
+	if _, ok := ValidateTOTP(secret, "000000", now); ok {
+		t.Error("expected wrong code to be rejected")
+	}
+}
+
+func TestGenerateTOTPSecretRoundTrip(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret() error = %v", err)
+	}
+
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil {
+		t.Fatalf("secret is not valid base32: %v", err)
+	}
+	if len(key) != totpSecretSize {
+		t.Errorf("secret size = %d, want %d", len(key), totpSecretSize)
+	}
+
+	now := time.Now()
+	code, err := TOTPCode(secret, now)
+	if err != nil {
+		t.Fatalf("TOTPCode() error = %v", err)
+	}
+	if _, ok := ValidateTOTP(secret, code, now); !ok {
+		t.Error("expected freshly generated code to be accepted")
+	}
+}
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_security_events_created_at;
+DROP INDEX IF EXISTS idx_security_events_event_type;
+DROP INDEX IF EXISTS idx_security_events_user_id;
+DROP TABLE IF EXISTS security_events;
+
+ALTER TABLE access_tokens DROP COLUMN IF EXISTS used_at;
+ALTER TABLE access_tokens DROP COLUMN IF EXISTS token_type;
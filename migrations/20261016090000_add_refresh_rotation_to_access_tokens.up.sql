@@ -0,0 +1,22 @@
+-- Distinguish access and refresh tokens and track refresh token rotation
+ALTER TABLE access_tokens ADD COLUMN IF NOT EXISTS token_type VARCHAR(20) NOT NULL DEFAULT 'access';
+ALTER TABLE access_tokens ADD COLUMN IF NOT EXISTS used_at TIMESTAMP;
+
+COMMENT ON COLUMN access_tokens.token_type IS 'access or refresh';
+COMMENT ON COLUMN access_tokens.used_at IS 'When a refresh token was rotated; a second use means the token family was stolen';
+
+-- Audit trail for security relevant events
+CREATE TABLE IF NOT EXISTS security_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    session_id UUID REFERENCES sessions(id) ON DELETE SET NULL,
+    event_type VARCHAR(100) NOT NULL,
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    details TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_security_events_user_id ON security_events(user_id);
+CREATE INDEX idx_security_events_event_type ON security_events(event_type);
+CREATE INDEX idx_security_events_created_at ON security_events(created_at);
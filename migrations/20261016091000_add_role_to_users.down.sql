@@ -0,0 +1,5 @@
+-- Rollback: Remove index
+DROP INDEX IF EXISTS idx_users_role;
+
+-- Rollback: Remove role column from users table
+ALTER TABLE users DROP COLUMN IF EXISTS role;
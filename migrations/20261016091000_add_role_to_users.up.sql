@@ -0,0 +1,8 @@
+-- Add role column to users table
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user';
+
+-- Create index for role
+CREATE INDEX IF NOT EXISTS idx_users_role ON users(role);
+
+-- Add comment
+COMMENT ON COLUMN users.role IS 'admin or user';
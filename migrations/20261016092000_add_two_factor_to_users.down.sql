@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_recovery_codes_user_hash;
+DROP INDEX IF EXISTS idx_recovery_codes_user_id;
+DROP TABLE IF EXISTS recovery_codes;
+
+ALTER TABLE users DROP COLUMN IF EXISTS two_factor_locked_until;
+ALTER TABLE users DROP COLUMN IF EXISTS two_factor_failed_attempts;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_last_used_step;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_enabled_at;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_secret;
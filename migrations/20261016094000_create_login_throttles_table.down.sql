@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_login_throttles_updated_at;
+DROP TABLE IF EXISTS login_throttles;
@@ -0,0 +1,13 @@
+-- Failed login counters, shared by every replica
+CREATE TABLE IF NOT EXISTS login_throttles (
+    key VARCHAR(255) PRIMARY KEY,
+    failures INT NOT NULL DEFAULT 0,
+    window_started_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_login_throttles_updated_at ON login_throttles(updated_at);
+
+COMMENT ON COLUMN login_throttles.key IS 'user:<lowercased username> or ip:<address>';
+COMMENT ON COLUMN login_throttles.locked_until IS 'Logins for this key are refused until this time';
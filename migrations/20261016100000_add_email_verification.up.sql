@@ -0,0 +1,21 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS pending_email VARCHAR(255);
+
+-- Accounts created before verification existed keep working
+UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL AND email IS NOT NULL AND email <> '';
+
+CREATE TABLE IF NOT EXISTS email_verification_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(255) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_email_verification_tokens_user_id ON email_verification_tokens(user_id);
+
+COMMENT ON COLUMN users.pending_email IS 'New address from a profile update, it replaces email once verified';
+COMMENT ON COLUMN email_verification_tokens.email IS 'Address the link was sent to, a link for a replaced address is useless';
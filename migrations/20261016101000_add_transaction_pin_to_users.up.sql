@@ -0,0 +1,6 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS pin_hash VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS pin_failed_attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS pin_locked_until TIMESTAMP;
+
+COMMENT ON COLUMN users.pin_hash IS 'bcrypt hash of the 6 digit transaction PIN';
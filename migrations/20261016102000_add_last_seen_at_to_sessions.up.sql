@@ -0,0 +1,5 @@
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMP;
+
+UPDATE sessions SET last_seen_at = created_at WHERE last_seen_at IS NULL;
+
+COMMENT ON COLUMN sessions.last_seen_at IS 'Last authenticated request, updated at most once a minute';
@@ -0,0 +1,6 @@
+-- Fails when transfers exist, both legs share a reference_id
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS idx_transactions_wallet_reference;
+ALTER TABLE transactions ADD CONSTRAINT transactions_reference_id_key UNIQUE (reference_id);
+
+DROP INDEX IF EXISTS idx_idempotency_keys_expires_at;
+DROP TABLE IF EXISTS idempotency_keys;
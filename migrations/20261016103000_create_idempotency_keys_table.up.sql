@@ -0,0 +1,23 @@
+-- Idempotency-Key header for deposit, withdraw and transfer, one row per user and key
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    key VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'processing',
+    response_status INT,
+    response_body BYTEA,
+    locked_until TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT idx_idempotency_keys_user_key UNIQUE (user_id, key)
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+
+COMMENT ON COLUMN idempotency_keys.request_hash IS 'SHA-256 of method, path and body, a reused key with another request is rejected';
+COMMENT ON COLUMN idempotency_keys.locked_until IS 'A processing key whose holder died can be taken over after this time';
+
+-- Both legs of a transfer share a reference_id, so it is only unique per wallet
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_reference_id_key;
+ALTER TABLE transactions ADD CONSTRAINT idx_transactions_wallet_reference UNIQUE (wallet_id, reference_id);
@@ -0,0 +1,15 @@
+DROP TRIGGER IF EXISTS trg_ledger_postings_balanced ON ledger_postings;
+DROP TRIGGER IF EXISTS trg_ledger_postings_immutable ON ledger_postings;
+DROP TRIGGER IF EXISTS trg_journal_entries_immutable ON journal_entries;
+DROP FUNCTION IF EXISTS ledger_check_balanced();
+DROP FUNCTION IF EXISTS ledger_reject_change();
+
+DROP INDEX IF EXISTS idx_transactions_journal_entry_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS journal_entry_id;
+
+DROP INDEX IF EXISTS idx_ledger_postings_ledger_account_id;
+DROP INDEX IF EXISTS idx_ledger_postings_journal_entry_id;
+DROP TABLE IF EXISTS ledger_postings;
+DROP INDEX IF EXISTS idx_journal_entries_reference_id;
+DROP TABLE IF EXISTS journal_entries;
+DROP TABLE IF EXISTS ledger_accounts;
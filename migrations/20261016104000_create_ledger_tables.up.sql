@@ -0,0 +1,159 @@
+-- Double-entry ledger under the wallets. Every wallet has a ledger account, system accounts
+-- per currency stand for money entering (cash_in) and leaving (cash_out) the platform.
+CREATE TABLE IF NOT EXISTS ledger_accounts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    kind VARCHAR(20) NOT NULL,
+    wallet_id UUID UNIQUE REFERENCES wallets(id),
+    code VARCHAR(50),
+    currency VARCHAR(10) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT idx_ledger_accounts_code_currency UNIQUE (code, currency),
+    CONSTRAINT chk_ledger_accounts_kind CHECK (
+        (kind = 'wallet' AND wallet_id IS NOT NULL AND code IS NULL) OR
+        (kind = 'system' AND wallet_id IS NULL AND code IS NOT NULL)
+    )
+);
+
+CREATE TABLE IF NOT EXISTS journal_entries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    reference_id VARCHAR(500) NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_journal_entries_reference_id ON journal_entries(reference_id);
+
+CREATE TABLE IF NOT EXISTS ledger_postings (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    journal_entry_id UUID NOT NULL REFERENCES journal_entries(id),
+    ledger_account_id UUID NOT NULL REFERENCES ledger_accounts(id),
+    direction VARCHAR(10) NOT NULL CHECK (direction IN ('debit', 'credit')),
+    amount NUMERIC(20, 2) NOT NULL CHECK (amount > 0),
+    currency VARCHAR(10) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_ledger_postings_journal_entry_id ON ledger_postings(journal_entry_id);
+CREATE INDEX idx_ledger_postings_ledger_account_id ON ledger_postings(ledger_account_id);
+
+COMMENT ON COLUMN ledger_accounts.code IS 'System accounts only: cash_in, cash_out, fees, suspense';
+COMMENT ON COLUMN ledger_postings.direction IS 'Credit raises a wallet balance, debit lowers it';
+
+-- Wallet statement rows point at the journal entry that moved the money
+ALTER TABLE transactions ADD COLUMN journal_entry_id UUID REFERENCES journal_entries(id);
+CREATE INDEX idx_transactions_journal_entry_id ON transactions(journal_entry_id);
+
+-- Ledger accounts for existing wallets and system accounts for every currency in use
+INSERT INTO ledger_accounts (kind, wallet_id, currency)
+SELECT 'wallet', id, currency FROM wallets;
+
+INSERT INTO ledger_accounts (kind, code, currency)
+SELECT 'system', codes.code, currencies.currency
+FROM (SELECT DISTINCT currency FROM wallets) currencies
+CROSS JOIN (VALUES ('cash_in'), ('cash_out'), ('fees'), ('suspense')) AS codes(code);
+
+-- Migrate history: one journal entry per deposit and withdrawal, one per transfer (both legs)
+INSERT INTO journal_entries (id, reference_id, type, description, created_at)
+SELECT id, reference_id, type, description, created_at
+FROM transactions
+WHERE type <> 'transfer';
+
+UPDATE transactions SET journal_entry_id = id WHERE type <> 'transfer';
+
+INSERT INTO journal_entries (id, reference_id, type, description, created_at)
+SELECT (array_agg(id ORDER BY created_at, id))[1], reference_id, 'transfer',
+       (array_agg(description ORDER BY created_at, id))[1], MIN(created_at)
+FROM transactions
+WHERE type = 'transfer'
+GROUP BY reference_id;
+
+UPDATE transactions t SET journal_entry_id = je.id
+FROM journal_entries je
+WHERE t.type = 'transfer' AND je.type = 'transfer' AND je.reference_id = t.reference_id;
+
+-- The wallet side of every migrated row
+INSERT INTO ledger_postings (journal_entry_id, ledger_account_id, direction, amount, currency, created_at)
+SELECT t.journal_entry_id, la.id,
+       CASE WHEN t.balance_after >= t.balance_before THEN 'credit' ELSE 'debit' END,
+       t.amount, la.currency, t.created_at
+FROM transactions t
+JOIN ledger_accounts la ON la.wallet_id = t.wallet_id
+WHERE t.amount > 0;
+
+-- The other side: cash_in for deposits, cash_out for withdrawals and suspense for whatever
+-- else does not balance (a transfer leg without its pair)
+INSERT INTO ledger_postings (journal_entry_id, ledger_account_id, direction, amount, currency, created_at)
+SELECT sums.journal_entry_id, sa.id,
+       CASE WHEN sums.net > 0 THEN 'debit' ELSE 'credit' END,
+       ABS(sums.net), sums.currency, sums.created_at
+FROM (
+    SELECT p.journal_entry_id, je.type, je.created_at, p.currency,
+           SUM(CASE p.direction WHEN 'credit' THEN p.amount ELSE -p.amount END) AS net
+    FROM ledger_postings p
+    JOIN journal_entries je ON je.id = p.journal_entry_id
+    GROUP BY p.journal_entry_id, je.type, je.created_at, p.currency
+) sums
+JOIN ledger_accounts sa ON sa.kind = 'system' AND sa.currency = sums.currency AND sa.code =
+    CASE sums.type WHEN 'deposit' THEN 'cash_in' WHEN 'withdrawal' THEN 'cash_out' ELSE 'suspense' END
+WHERE sums.net <> 0;
+
+-- Balances that were set without a transaction get an opening entry against suspense
+CREATE TEMPORARY TABLE ledger_opening_balances AS
+SELECT uuid_generate_v4() AS journal_entry_id, w.id AS wallet_id, la.id AS ledger_account_id, w.currency,
+       w.balance - COALESCE(SUM(CASE p.direction WHEN 'credit' THEN p.amount ELSE -p.amount END), 0) AS diff
+FROM wallets w
+JOIN ledger_accounts la ON la.wallet_id = w.id
+LEFT JOIN ledger_postings p ON p.ledger_account_id = la.id
+GROUP BY w.id, la.id, w.currency, w.balance;
+
+DELETE FROM ledger_opening_balances WHERE diff = 0;
+
+INSERT INTO journal_entries (id, reference_id, type, description)
+SELECT journal_entry_id, 'opening-' || wallet_id, 'opening_balance', 'Opening balance from before the ledger'
+FROM ledger_opening_balances;
+
+INSERT INTO ledger_postings (journal_entry_id, ledger_account_id, direction, amount, currency)
+SELECT journal_entry_id, ledger_account_id, CASE WHEN diff > 0 THEN 'credit' ELSE 'debit' END, ABS(diff), currency
+FROM ledger_opening_balances
+UNION ALL
+SELECT ob.journal_entry_id, sa.id, CASE WHEN ob.diff > 0 THEN 'debit' ELSE 'credit' END, ABS(ob.diff), ob.currency
+FROM ledger_opening_balances ob
+JOIN ledger_accounts sa ON sa.kind = 'system' AND sa.code = 'suspense' AND sa.currency = ob.currency;
+
+DROP TABLE ledger_opening_balances;
+
+-- Journal entries and postings are append-only, corrections are new entries
+CREATE OR REPLACE FUNCTION ledger_reject_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION '% is append-only', TG_TABLE_NAME;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_journal_entries_immutable
+    BEFORE UPDATE OR DELETE ON journal_entries
+    FOR EACH ROW EXECUTE FUNCTION ledger_reject_change();
+
+CREATE TRIGGER trg_ledger_postings_immutable
+    BEFORE UPDATE OR DELETE ON ledger_postings
+    FOR EACH ROW EXECUTE FUNCTION ledger_reject_change();
+
+-- Debits and credits of an entry must match per currency when the transaction commits
+CREATE OR REPLACE FUNCTION ledger_check_balanced() RETURNS trigger AS $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM ledger_postings
+        WHERE journal_entry_id = NEW.journal_entry_id
+        GROUP BY currency
+        HAVING SUM(CASE direction WHEN 'credit' THEN amount ELSE -amount END) <> 0
+    ) THEN
+        RAISE EXCEPTION 'journal entry % is not balanced', NEW.journal_entry_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER trg_ledger_postings_balanced
+    AFTER INSERT ON ledger_postings
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION ledger_check_balanced();
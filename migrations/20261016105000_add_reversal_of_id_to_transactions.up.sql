@@ -0,0 +1,5 @@
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS reversal_of_id UUID REFERENCES transactions(id);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_reversal_of_id ON transactions(reversal_of_id);
+
+COMMENT ON COLUMN transactions.reversal_of_id IS 'Original transaction a reversal row compensates, partial reversals point at the same row';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_holds_wallet_active;
+DROP TABLE IF EXISTS holds;
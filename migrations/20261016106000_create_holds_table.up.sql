@@ -0,0 +1,21 @@
+-- Money reserved on a wallet until it is captured, voided or expires
+CREATE TABLE IF NOT EXISTS holds (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    reference_id VARCHAR(500) NOT NULL,
+    amount NUMERIC(20, 2) NOT NULL CHECK (amount > 0),
+    captured_amount NUMERIC(20, 2) NOT NULL DEFAULT 0 CHECK (captured_amount >= 0 AND captured_amount <= amount),
+    currency VARCHAR(10) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'captured', 'voided')),
+    description TEXT,
+    capture_reference_id VARCHAR(500),
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT idx_holds_wallet_reference UNIQUE (wallet_id, reference_id)
+);
+
+-- Available balance sums the active holds of a wallet
+CREATE INDEX idx_holds_wallet_active ON holds(wallet_id, expires_at) WHERE status = 'active';
+
+COMMENT ON COLUMN holds.expires_at IS 'An active hold past this time no longer reserves money and cannot be captured';
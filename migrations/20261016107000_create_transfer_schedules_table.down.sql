@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS transfer_schedule_runs;
+DROP INDEX IF EXISTS idx_transfer_schedules_due;
+DROP INDEX IF EXISTS idx_transfer_schedules_user_id;
+DROP TABLE IF EXISTS transfer_schedules;
@@ -0,0 +1,36 @@
+-- One-off and recurring transfers, executed by the schedule worker
+CREATE TABLE IF NOT EXISTS transfer_schedules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    from_wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    to_wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    amount NUMERIC(20, 2) NOT NULL CHECK (amount > 0),
+    description TEXT,
+    frequency VARCHAR(20) NOT NULL CHECK (frequency IN ('once', 'daily', 'weekly', 'monthly')),
+    interval_count INT NOT NULL DEFAULT 1 CHECK (interval_count > 0),
+    start_at TIMESTAMP NOT NULL,
+    end_at TIMESTAMP,
+    run_count INT NOT NULL DEFAULT 0,
+    next_run_at TIMESTAMP,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'paused', 'completed', 'cancelled')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_transfer_schedules_user_id ON transfer_schedules(user_id);
+-- The worker polls for active schedules that are due
+CREATE INDEX idx_transfer_schedules_due ON transfer_schedules(next_run_at) WHERE status = 'active';
+
+CREATE TABLE IF NOT EXISTS transfer_schedule_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    schedule_id UUID NOT NULL REFERENCES transfer_schedules(id) ON DELETE CASCADE,
+    occurrence_at TIMESTAMP NOT NULL,
+    reference_id VARCHAR(500) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('succeeded', 'failed')),
+    error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT idx_transfer_schedule_runs_occurrence UNIQUE (schedule_id, occurrence_at)
+);
+
+COMMENT ON COLUMN transfer_schedules.run_count IS 'Occurrences already run, the next one is computed from start_at and this count';
+COMMENT ON COLUMN transfer_schedule_runs.reference_id IS 'Reference of the transfer, derived from schedule and occurrence so a retry cannot transfer twice';
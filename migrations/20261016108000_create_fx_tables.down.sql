@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_transactions_fx_quote_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS fx_destination_amount;
+ALTER TABLE transactions DROP COLUMN IF EXISTS fx_source_amount;
+ALTER TABLE transactions DROP COLUMN IF EXISTS fx_spread;
+ALTER TABLE transactions DROP COLUMN IF EXISTS fx_rate;
+ALTER TABLE transactions DROP COLUMN IF EXISTS fx_quote_id;
+
+DROP INDEX IF EXISTS idx_fx_quotes_user_id;
+DROP TABLE IF EXISTS fx_quotes;
+DROP INDEX IF EXISTS idx_fx_rates_pair;
+DROP TABLE IF EXISTS fx_rates;
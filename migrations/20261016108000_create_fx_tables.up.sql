@@ -0,0 +1,46 @@
+-- Mid rates per currency pair, the newest rate whose period covers now is used
+CREATE TABLE IF NOT EXISTS fx_rates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    base_currency VARCHAR(10) NOT NULL,
+    quote_currency VARCHAR(10) NOT NULL,
+    rate NUMERIC(20, 10) NOT NULL CHECK (rate > 0),
+    spread NUMERIC(10, 6) NOT NULL DEFAULT 0 CHECK (spread >= 0 AND spread < 1),
+    valid_from TIMESTAMP NOT NULL,
+    valid_to TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (base_currency <> quote_currency),
+    CHECK (valid_to IS NULL OR valid_to > valid_from)
+);
+
+CREATE INDEX idx_fx_rates_pair ON fx_rates(base_currency, quote_currency, valid_from DESC);
+
+-- A rate locked for one conversion until expires_at, used at most once
+CREATE TABLE IF NOT EXISTS fx_quotes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rate_id UUID NOT NULL REFERENCES fx_rates(id),
+    source_currency VARCHAR(10) NOT NULL,
+    destination_currency VARCHAR(10) NOT NULL,
+    rate NUMERIC(20, 10) NOT NULL,
+    spread NUMERIC(10, 6) NOT NULL,
+    source_amount NUMERIC(20, 2) NOT NULL CHECK (source_amount > 0),
+    destination_amount NUMERIC(20, 2) NOT NULL CHECK (destination_amount > 0),
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    reference_id VARCHAR(500),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_fx_quotes_user_id ON fx_quotes(user_id);
+
+-- Both legs of a cross-currency transfer record the quote it ran at
+ALTER TABLE transactions ADD COLUMN fx_quote_id UUID REFERENCES fx_quotes(id);
+ALTER TABLE transactions ADD COLUMN fx_rate NUMERIC(20, 10);
+ALTER TABLE transactions ADD COLUMN fx_spread NUMERIC(10, 6);
+ALTER TABLE transactions ADD COLUMN fx_source_amount NUMERIC(20, 2);
+ALTER TABLE transactions ADD COLUMN fx_destination_amount NUMERIC(20, 2);
+CREATE INDEX idx_transactions_fx_quote_id ON transactions(fx_quote_id);
+
+COMMENT ON COLUMN fx_rates.rate IS '1 base_currency buys this much quote_currency before the spread';
+COMMENT ON COLUMN fx_rates.spread IS 'Fraction taken off the rate for the customer, 0.005 = 0.5%';
+COMMENT ON COLUMN transactions.fx_rate IS 'Mid rate of the quote, the customer got fx_rate * (1 - fx_spread)';
@@ -0,0 +1,16 @@
+-- Note: amounts with more than 2 decimal places are rounded when the columns are narrowed
+ALTER TABLE fx_quotes ALTER COLUMN destination_amount TYPE NUMERIC(20, 2);
+ALTER TABLE fx_quotes ALTER COLUMN source_amount TYPE NUMERIC(20, 2);
+ALTER TABLE transfer_schedules ALTER COLUMN amount TYPE NUMERIC(20, 2);
+ALTER TABLE holds ALTER COLUMN captured_amount TYPE NUMERIC(20, 2);
+ALTER TABLE holds ALTER COLUMN amount TYPE NUMERIC(20, 2);
+ALTER TABLE ledger_postings ALTER COLUMN amount TYPE NUMERIC(20, 2);
+ALTER TABLE transactions ALTER COLUMN fx_destination_amount TYPE NUMERIC(20, 2);
+ALTER TABLE transactions ALTER COLUMN fx_source_amount TYPE NUMERIC(20, 2);
+ALTER TABLE transactions ALTER COLUMN balance_after TYPE NUMERIC(20, 2);
+ALTER TABLE transactions ALTER COLUMN balance_before TYPE NUMERIC(20, 2);
+ALTER TABLE transactions ALTER COLUMN amount TYPE NUMERIC(20, 2);
+ALTER TABLE wallets ALTER COLUMN balance TYPE NUMERIC(20, 2);
+
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS fk_wallets_currency;
+DROP TABLE IF EXISTS currencies;
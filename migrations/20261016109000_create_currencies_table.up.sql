@@ -0,0 +1,60 @@
+-- ISO 4217 currencies the platform holds. exponent is the number of minor unit digits,
+-- amounts with more decimal places are rejected by the API instead of rounded here.
+CREATE TABLE IF NOT EXISTS currencies (
+    code VARCHAR(10) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    exponent SMALLINT NOT NULL CHECK (exponent BETWEEN 0 AND 4),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO currencies (code, name, exponent) VALUES
+    ('IDR', 'Indonesian Rupiah', 2),
+    ('USD', 'US Dollar', 2),
+    ('EUR', 'Euro', 2),
+    ('GBP', 'Pound Sterling', 2),
+    ('SGD', 'Singapore Dollar', 2),
+    ('MYR', 'Malaysian Ringgit', 2),
+    ('THB', 'Baht', 2),
+    ('PHP', 'Philippine Peso', 2),
+    ('AUD', 'Australian Dollar', 2),
+    ('CAD', 'Canadian Dollar', 2),
+    ('CHF', 'Swiss Franc', 2),
+    ('CNY', 'Yuan Renminbi', 2),
+    ('HKD', 'Hong Kong Dollar', 2),
+    ('INR', 'Indian Rupee', 2),
+    ('SAR', 'Saudi Riyal', 2),
+    ('AED', 'UAE Dirham', 2),
+    ('JPY', 'Yen', 0),
+    ('KRW', 'Won', 0),
+    ('VND', 'Dong', 0),
+    ('BHD', 'Bahraini Dinar', 3),
+    ('JOD', 'Jordanian Dinar', 3),
+    ('KWD', 'Kuwaiti Dinar', 3),
+    ('OMR', 'Rial Omani', 3)
+ON CONFLICT (code) DO NOTHING;
+
+-- Wallets opened before the registry keep working with the old two decimal places
+INSERT INTO currencies (code, name, exponent)
+SELECT DISTINCT currency, currency, 2 FROM wallets WHERE currency IS NOT NULL
+ON CONFLICT (code) DO NOTHING;
+
+ALTER TABLE wallets ADD CONSTRAINT fk_wallets_currency FOREIGN KEY (currency) REFERENCES currencies(code);
+
+-- Scale 4 covers every ISO 4217 exponent, the integer part keeps its 18 digits
+ALTER TABLE wallets ALTER COLUMN balance TYPE NUMERIC(22, 4);
+ALTER TABLE transactions ALTER COLUMN amount TYPE NUMERIC(22, 4);
+ALTER TABLE transactions ALTER COLUMN balance_before TYPE NUMERIC(22, 4);
+ALTER TABLE transactions ALTER COLUMN balance_after TYPE NUMERIC(22, 4);
+ALTER TABLE transactions ALTER COLUMN fx_source_amount TYPE NUMERIC(22, 4);
+ALTER TABLE transactions ALTER COLUMN fx_destination_amount TYPE NUMERIC(22, 4);
+ALTER TABLE ledger_postings ALTER COLUMN amount TYPE NUMERIC(22, 4);
+ALTER TABLE holds ALTER COLUMN amount TYPE NUMERIC(22, 4);
+ALTER TABLE holds ALTER COLUMN captured_amount TYPE NUMERIC(22, 4);
+ALTER TABLE transfer_schedules ALTER COLUMN amount TYPE NUMERIC(22, 4);
+ALTER TABLE fx_quotes ALTER COLUMN source_amount TYPE NUMERIC(22, 4);
+ALTER TABLE fx_quotes ALTER COLUMN destination_amount TYPE NUMERIC(22, 4);
+
+COMMENT ON COLUMN currencies.exponent IS 'Minor unit digits: 2 for IDR, 0 for JPY, 3 for KWD';
+COMMENT ON COLUMN currencies.enabled IS 'Disabled currencies take no new wallets and no incoming money';
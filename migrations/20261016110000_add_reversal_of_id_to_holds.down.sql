@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_holds_reversal_of_id;
+ALTER TABLE holds DROP COLUMN IF EXISTS reversal_of_id;
@@ -0,0 +1,6 @@
+-- A reversal that the wallet cannot cover holds the shortfall, the hold points at the row being reversed
+ALTER TABLE holds ADD COLUMN IF NOT EXISTS reversal_of_id UUID REFERENCES transactions(id);
+
+CREATE INDEX IF NOT EXISTS idx_holds_reversal_of_id ON holds(reversal_of_id) WHERE reversal_of_id IS NOT NULL;
+
+COMMENT ON COLUMN holds.reversal_of_id IS 'Set on shortfall holds of a reversal, these are released by reversing the rest and never by the wallet owner';
@@ -3,7 +3,9 @@ package seeder
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"wallet_api/internal/common/consts"
 	"wallet_api/internal/entity"
 	"wallet_api/internal/utils"
 	"gorm.io/gorm"
@@ -32,19 +34,29 @@ func (s *UserSeeder) Seed(ctx context.Context) error {
 		return nil
 	}
 
-	// Create sample users
+	// Sample users start with a verified email so they can move money right away
+	verifiedAt := time.Now()
 	users := []entity.User{
 		{
-			Username:     "admin",
-			PasswordHash: mustHash("admin123"),
+			Username:        "admin",
+			Email:           "admin@example.com",
+			PasswordHash:    mustHash("admin123"),
+			Role:            consts.RoleAdmin,
+			EmailVerifiedAt: &verifiedAt,
 		},
 		{
-			Username:     "johndoe",
-			PasswordHash: mustHash("password123"),
+			Username:        "johndoe",
+			Email:           "johndoe@example.com",
+			PasswordHash:    mustHash("password123"),
+			Role:            consts.RoleUser,
+			EmailVerifiedAt: &verifiedAt,
 		},
 		{
-			Username:     "janedoe",
-			PasswordHash: mustHash("password123"),
+			Username:        "janedoe",
+			Email:           "janedoe@example.com",
+			PasswordHash:    mustHash("password123"),
+			Role:            consts.RoleUser,
+			EmailVerifiedAt: &verifiedAt,
 		},
 	}
 
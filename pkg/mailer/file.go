@@ -0,0 +1,44 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// File writes every message as an .eml file, for local development and tests.
+type File struct {
+	dir  string
+	from string
+}
+
+var _ Interface = (*File)(nil)
+
+// NewFile -.
+func NewFile(dir, from string) (*File, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("mailer - NewFile - os.MkdirAll: %w", err)
+	}
+
+	return &File{dir: dir, from: from}, nil
+}
+
+// Send -.
+func (f *File) Send(_ context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	name := fmt.Sprintf("%s_%s.eml", now.UTC().Format("20060102T150405"), uuid.NewString())
+
+	if err := os.WriteFile(filepath.Join(f.dir, name), msg.build(f.from, now), 0o640); err != nil {
+		return fmt.Errorf("mailer - File - Send: %w", err)
+	}
+
+	return nil
+}
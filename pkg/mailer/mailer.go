@@ -0,0 +1,48 @@
+// Package mailer implements outgoing email delivery.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Interface -.
+type Interface interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Message -.
+type Message struct {
+	To      string
+	Subject string
+	Body    string // plain text
+}
+
+// build renders the message as an RFC 5322 email
+func (m Message) build(from string, now time.Time) []byte {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", m.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", m.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(m.Body, "\n", "\r\n"))
+
+	return []byte(b.String())
+}
+
+// validate rejects header injection through the recipient or subject
+func (m Message) validate() error {
+	if m.To == "" {
+		return fmt.Errorf("mailer - recipient is empty")
+	}
+	if strings.ContainsAny(m.To+m.Subject, "\r\n") {
+		return fmt.Errorf("mailer - header contains a line break")
+	}
+	return nil
+}
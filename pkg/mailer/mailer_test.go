@@ -0,0 +1,60 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileSendWritesEML(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	m, err := NewFile(dir, "no-reply@example.com")
+	if err != nil {
+		t.Fatalf("NewFile() error = %v", err)
+	}
+
+	msg := Message{To: "alice@example.com", Subject: "Reset password", Body: "line one\nline two"}
+	if err := m.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 1 {
+		t.Fatalf("expected 1 eml file, got %d", len(files))
+	}
+
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+
+	content := string(data)
+	for _, want := range []string{
+		"From: no-reply@example.com\r\n",
+		"To: alice@example.com\r\n",
+		"Subject: Reset password\r\n",
+		"\r\n\r\nline one\r\nline two",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("eml does not contain %q:\n%s", want, content)
+		}
+	}
+}
+
+func TestSendRejectsHeaderInjection(t *testing.T) {
+	t.Parallel()
+
+	m, err := NewFile(t.TempDir(), "no-reply@example.com")
+	if err != nil {
+		t.Fatalf("NewFile() error = %v", err)
+	}
+
+	msg := Message{To: "alice@example.com\r\nBcc: everyone@example.com", Subject: "hi"}
+	if err := m.Send(context.Background(), msg); err == nil {
+		t.Error("expected an error for a recipient with a line break")
+	}
+}
@@ -0,0 +1,80 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+)
+
+// SMTP -.
+type SMTP struct {
+	addr     string
+	host     string
+	from     string // From header, may include a display name
+	envelope string // bare address for MAIL FROM
+	username string
+	password string
+}
+
+var _ Interface = (*SMTP)(nil)
+
+// SMTPOption -.
+type SMTPOption func(*SMTP)
+
+// Auth -.
+func Auth(username, password string) SMTPOption {
+	return func(s *SMTP) {
+		s.username = username
+		s.password = password
+	}
+}
+
+// NewSMTP -.
+func NewSMTP(host, port, from string, opts ...SMTPOption) *SMTP {
+	s := &SMTP{
+		addr:     net.JoinHostPort(host, port),
+		host:     host,
+		from:     from,
+		envelope: from,
+	}
+	if addr, err := mail.ParseAddress(from); err == nil {
+		s.envelope = addr.Address
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Send -.
+func (s *SMTP) Send(ctx context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if s.username != "" {
+		auth = smtp.PlainAuth("", s.username, s.password, s.host)
+	}
+
+	// net/smtp has no context support, so run it aside and stop waiting when ctx ends
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(s.addr, auth, s.envelope, []string{msg.To}, msg.build(s.from, time.Now()))
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("mailer - SMTP - Send: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}